@@ -18,6 +18,7 @@ require (
 	github.com/stretchr/testify v1.4.0
 	github.com/urfave/cli v1.22.1
 	github.com/yalp/jsonpath v0.0.0-20180802001716-5cc68e5049a0
+	golang.org/x/crypto v0.0.0-20190911031432-227b76d455e7
 	github.ibm.com/IAM/uum v0.0.0-20190927184355-9ee975de411d // indirect
 	gopkg.in/alecthomas/kingpin.v2 v2.2.6
 	gopkg.in/yaml.v2 v2.2.2
@@ -1,6 +1,10 @@
 package cache
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/mcastorina/poster/internal/store"
 )
 
@@ -169,6 +173,140 @@ func SaveVariable(v *store.Variable) error {
 	return v.Save()
 }
 
+func GetAllSuites() []store.Suite {
+	key := "GetAllSuites"
+	if suites, ok := cacheGet(key); ok {
+		return suites.([]store.Suite)
+	}
+	suites := store.GetAllSuites()
+	cacheSet(key, suites)
+	return suites
+}
+func GetSuiteByName(name string) (store.Suite, error) {
+	key := "GetSuiteByName:" + name
+	if result, ok := cacheGet(key); ok {
+		return result.(store.Suite), nil
+	}
+	suite, err := store.GetSuiteByName(name)
+	if err != nil {
+		return store.Suite{}, err
+	}
+	cacheSet(key, suite)
+	return suite, nil
+}
+func GetSuitesByEnvironment(env string) []store.Suite {
+	key := "GetSuitesByEnvironment:" + env
+	if suites, ok := cacheGet(key); ok {
+		return suites.([]store.Suite)
+	}
+	suites := store.GetSuitesByEnvironment(env)
+	cacheSet(key, suites)
+	return suites
+}
+func SaveSuite(s *store.Suite) error {
+	delete(cache, "GetAllSuites")
+	delete(cache, "GetSuiteByName:"+s.Name)
+	delete(cache, "GetSuitesByEnvironment:"+s.Environment)
+	return s.Save()
+}
+
+func GetAllResponses() []store.Response {
+	key := "GetAllResponses"
+	if responses, ok := cacheGet(key); ok {
+		return responses.([]store.Response)
+	}
+	responses := store.GetAllResponses()
+	cacheSet(key, responses)
+	return responses
+}
+func GetResponseByID(id int64) (store.Response, error) {
+	key := fmt.Sprintf("GetResponseByID:%d", id)
+	if result, ok := cacheGet(key); ok {
+		return result.(store.Response), nil
+	}
+	response, err := store.GetResponseByID(id)
+	if err != nil {
+		return store.Response{}, err
+	}
+	cacheSet(key, response)
+	return response, nil
+}
+func GetBaselineByRequestAndEnvironment(request, environment string) (store.Response, error) {
+	key := "GetBaselineByRequestAndEnvironment:" + request + ":" + environment
+	if result, ok := cacheGet(key); ok {
+		return result.(store.Response), nil
+	}
+	response, err := store.GetBaselineByRequestAndEnvironment(request, environment)
+	if err != nil {
+		return store.Response{}, err
+	}
+	cacheSet(key, response)
+	return response, nil
+}
+func GetResponsesByRequest(request string) []store.Response {
+	key := "GetResponsesByRequest:" + request
+	if responses, ok := cacheGet(key); ok {
+		return responses.([]store.Response)
+	}
+	responses := store.GetResponsesByRequest(request)
+	cacheSet(key, responses)
+	return responses
+}
+func SaveResponse(r *store.Response) error {
+	delete(cache, "GetAllResponses")
+	delete(cache, "GetResponsesByRequest:"+r.Request)
+	return r.Save()
+}
+func MarkBaseline(r *store.Response) error {
+	clearResponses()
+	return r.MarkBaseline()
+}
+func DeleteResponsesBefore(t time.Time) error {
+	clearResponses()
+	return store.DeleteResponsesBefore(t)
+}
+func DeleteResponsesExceptLatest(n int) error {
+	clearResponses()
+	return store.DeleteResponsesExceptLatest(n)
+}
+
+func GetAllCookies() []store.Cookie {
+	key := "GetAllCookies"
+	if cookies, ok := cacheGet(key); ok {
+		return cookies.([]store.Cookie)
+	}
+	cookies := store.GetAllCookies()
+	cacheSet(key, cookies)
+	return cookies
+}
+func GetCookiesBySession(session, environment string) []store.Cookie {
+	key := "GetCookiesBySession:" + session + ":" + environment
+	if cookies, ok := cacheGet(key); ok {
+		return cookies.([]store.Cookie)
+	}
+	cookies := store.GetCookiesBySession(session, environment)
+	cacheSet(key, cookies)
+	return cookies
+}
+func StoreSession(session, environment string, cookies []store.Cookie) error {
+	delete(cache, "GetAllCookies")
+	delete(cache, "GetCookiesBySession:"+session+":"+environment)
+	return store.StoreSession(session, environment, cookies)
+}
+func DeleteSession(session, environment string) error {
+	delete(cache, "GetAllCookies")
+	delete(cache, "GetCookiesBySession:"+session+":"+environment)
+	return store.DeleteSession(session, environment)
+}
+
+func clearResponses() {
+	for key := range cache {
+		if strings.HasPrefix(key, "GetAllResponses") || strings.HasPrefix(key, "GetResponse") ||
+			strings.HasPrefix(key, "GetBaseline") {
+			delete(cache, key)
+		}
+	}
+}
 func cacheGet(key string) (interface{}, bool) {
 	if value, ok := cache[key]; ok {
 		log.Debugf("Cache hit on [%s]", key)
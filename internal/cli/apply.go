@@ -0,0 +1,329 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/mcastorina/poster/internal/models"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+const globalEnvironment = "global"
+
+var applyCmd = &cobra.Command{
+	Use:   "apply -f PATH",
+	Short: "Create or update resources from YAML files",
+	Long: `Create or update resources from YAML files.
+
+PATH is a file or a directory, which is searched recursively for .yaml and
+.yml files. Each file lists resources in the same format as create
+--interactive, grouped by type:
+
+    environments:
+    - name: staging
+    - name: staging-eu
+      parent: staging
+      variables:
+      - name: region
+        type: const
+        value: eu-west-1
+    requests:
+    - name: get-token
+      method: GET
+      url: :host/token
+      default-environment: staging
+    variables:
+    - name: host
+      type: const
+      value: localhost:8080
+      environments: [staging]
+    suites:
+    - name: smoke
+      default-environment: staging
+      requests: [get-token]
+
+Variables can also be defined in the environment they belong to. Resources
+are applied in dependency order: environments, parents before their
+children, requests, variables and then suites. With --prune, resources that
+are not in the files are deleted. poster dump writes the database in this
+format.
+`,
+	Run:  apply,
+	Args: applyArgs,
+}
+
+// resourceFile is the format read by apply and written by dump
+type resourceFile struct {
+	Environments []Environment `yaml:"environments,omitempty"`
+	Requests     []Request     `yaml:"requests,omitempty"`
+	Variables    []Variable    `yaml:"variables,omitempty"`
+	Suites       []Suite       `yaml:"suites,omitempty"`
+}
+
+func init() {
+	rootCmd.AddCommand(applyCmd)
+
+	// apply flags
+	applyCmd.Flags().StringArrayP("filename", "f", []string{}, "File or directory to apply")
+	applyCmd.Flags().Bool("prune", false, "Delete resources that are not in the files")
+	applyCmd.Flags().Bool("dry-run", false, "Only print the changes")
+}
+
+// run functions
+func apply(cmd *cobra.Command, args []string) {
+	paths, _ := cmd.Flags().GetStringArray("filename")
+	prune, _ := cmd.Flags().GetBool("prune")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	resources := resourceFile{}
+	for _, path := range paths {
+		if err := readResourcePath(path, &resources); err != nil {
+			log.Errorf("Could not read %s: %+v\n", path, err)
+			os.Exit(1)
+		}
+	}
+
+	failed := false
+	report := func(kind, name, action string, err error) {
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %s/%s: %+v\n", kind, name, err)
+			failed = true
+			return
+		}
+		if dryRun {
+			action += " (dry run)"
+		}
+		fmt.Printf("%s/%s %s\n", kind, name, action)
+	}
+
+	// Apply in dependency order
+	existing := existingResources()
+	for _, environment := range parentsFirst(resources.Environments) {
+		if existing["environment/"+environment.Name] {
+			current, _ := models.GetEnvironmentByName(environment.Name)
+			if current.Parent == environment.Parent &&
+				reflect.DeepEqual(current.Client, environment.Client) &&
+				current.Certificate == environment.Certificate &&
+				current.Auth == environment.Auth {
+				continue
+			}
+		}
+		report("environment", environment.Name, applyAction(existing, "environment/"+environment.Name),
+			applyChange(dryRun, environment.Save))
+	}
+	for _, request := range resources.Requests {
+		report("request", request.Name, applyAction(existing, "request/"+request.Name),
+			applyChange(dryRun, request.Save))
+	}
+	for _, variable := range resources.Variables {
+		if len(variable.Environments) == 0 {
+			report("variable", variable.Name, "", errorMissingEnvironments)
+		}
+		for _, environment := range variable.Environments {
+			v := variable
+			v.Environments = []string{environment}
+			name := variable.Name + "@" + environment
+			report("variable", name, applyAction(existing, "variable/"+name), applyChange(dryRun, v.Save))
+		}
+	}
+	for _, suite := range resources.Suites {
+		report("suite", suite.Name, applyAction(existing, "suite/"+suite.Name), applyChange(dryRun, suite.Save))
+	}
+
+	if prune {
+		applied := resources.names()
+		// Delete in reverse dependency order
+		for _, suite := range models.GetAllSuites() {
+			if !applied["suite/"+suite.Name] {
+				report("suite", suite.Name, "deleted", applyChange(dryRun, suite.Delete))
+			}
+		}
+		for _, variable := range models.GetAllVariables() {
+			name := variable.Name + "@" + variable.Environment.Name
+			if !applied["variable/"+name] {
+				report("variable", name, "deleted", applyChange(dryRun, variable.Delete))
+			}
+		}
+		for _, request := range models.GetAllRequests() {
+			if !applied["request/"+request.Name] {
+				report("request", request.Name, "deleted", applyChange(dryRun, request.Delete))
+			}
+		}
+		// Children before their parents
+		environments := models.GetAllEnvironments()
+		sort.SliceStable(environments, func(i, j int) bool {
+			return len(environments[i].Chain()) > len(environments[j].Chain())
+		})
+		for _, environment := range environments {
+			if environment.Name != globalEnvironment && !applied["environment/"+environment.Name] {
+				report("environment", environment.Name, "deleted", applyChange(dryRun, environment.Delete))
+			}
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// argument functions
+func applyArgs(cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return errorTooManyArgs
+	}
+	if !flagsAreSet(cmd, "filename") {
+		return errorMissingFlag("filename")
+	}
+	return nil
+}
+
+// helper functions
+
+// readResourcePath reads the YAML files at path into resources
+func readResourcePath(path string, resources *resourceFile) error {
+	return filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		if file != path && !strings.HasSuffix(file, ".yaml") && !strings.HasSuffix(file, ".yml") {
+			return nil
+		}
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if err := readResources(f, resources); err != nil {
+			return fmt.Errorf("%s: %+v", file, err)
+		}
+		return nil
+	})
+}
+
+// readResources appends the resources of every YAML document in r
+func readResources(r io.Reader, resources *resourceFile) error {
+	decoder := yaml.NewDecoder(r)
+	decoder.SetStrict(true)
+	for {
+		document := resourceFile{}
+		if err := decoder.Decode(&document); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		// Variables defined in an environment are applied with the others,
+		// after the requests they may depend on
+		for i, environment := range document.Environments {
+			for _, variable := range environment.Variables {
+				variable.Environments = []string{environment.Name}
+				document.Variables = append(document.Variables, variable)
+			}
+			document.Environments[i].Variables = nil
+		}
+		resources.Environments = append(resources.Environments, document.Environments...)
+		resources.Requests = append(resources.Requests, document.Requests...)
+		resources.Variables = append(resources.Variables, document.Variables...)
+		resources.Suites = append(resources.Suites, document.Suites...)
+	}
+}
+
+// names returns the set of resources as kind/name, where the name of a
+// variable is name@environment
+func (r *resourceFile) names() map[string]bool {
+	names := map[string]bool{}
+	for _, environment := range r.Environments {
+		names["environment/"+environment.Name] = true
+	}
+	for _, request := range r.Requests {
+		names["request/"+request.Name] = true
+	}
+	for _, variable := range r.Variables {
+		for _, environment := range variable.Environments {
+			names["variable/"+variable.Name+"@"+environment] = true
+		}
+	}
+	for _, suite := range r.Suites {
+		names["suite/"+suite.Name] = true
+	}
+	return names
+}
+
+// existingResources returns the set of resources in the database, named as
+// in resourceFile.names
+func existingResources() map[string]bool {
+	existing := map[string]bool{}
+	for _, environment := range models.GetAllEnvironments() {
+		existing["environment/"+environment.Name] = true
+	}
+	for _, request := range models.GetAllRequests() {
+		existing["request/"+request.Name] = true
+	}
+	for _, variable := range models.GetAllVariables() {
+		existing["variable/"+variable.Name+"@"+variable.Environment.Name] = true
+	}
+	for _, suite := range models.GetAllSuites() {
+		existing["suite/"+suite.Name] = true
+	}
+	return existing
+}
+func applyAction(existing map[string]bool, name string) string {
+	if existing[name] {
+		return "configured"
+	}
+	return "created"
+}
+func applyChange(dryRun bool, change func() error) error {
+	if dryRun {
+		return nil
+	}
+	return change()
+}
+
+// parentsFirst orders environments so that each one comes after its parent,
+// if the parent is also in the list
+func parentsFirst(environments []Environment) []Environment {
+	byName := map[string]Environment{}
+	for _, environment := range environments {
+		byName[environment.Name] = environment
+	}
+	ordered := []Environment{}
+	added := map[string]bool{}
+	var add func(environment Environment)
+	add = func(environment Environment) {
+		if added[environment.Name] {
+			return
+		}
+		added[environment.Name] = true
+		if parent, ok := byName[environment.Parent]; ok {
+			add(parent)
+		}
+		ordered = append(ordered, environment)
+	}
+	for _, environment := range environments {
+		add(environment)
+	}
+	return ordered
+}
+
+// sortResources orders resources by name, and variables by name and their
+// first environment, so dumps are stable
+func sortResources(r *resourceFile) {
+	sort.Slice(r.Environments, func(i, j int) bool { return r.Environments[i].Name < r.Environments[j].Name })
+	sort.Slice(r.Requests, func(i, j int) bool { return r.Requests[i].Name < r.Requests[j].Name })
+	sort.Slice(r.Suites, func(i, j int) bool { return r.Suites[i].Name < r.Suites[j].Name })
+	sort.Slice(r.Variables, func(i, j int) bool {
+		if r.Variables[i].Name != r.Variables[j].Name {
+			return r.Variables[i].Name < r.Variables[j].Name
+		}
+		return r.Variables[i].Environments[0] < r.Variables[j].Environments[0]
+	})
+}
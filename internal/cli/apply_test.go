@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadResources(t *testing.T) {
+	data := `environments:
+- name: local
+requests:
+- name: get-token
+  method: GET
+  url: :host/token
+---
+variables:
+- name: host
+  type: const
+  value: localhost
+  environments: [local, staging]
+`
+	resources := resourceFile{}
+	assert.Nil(t, readResources(strings.NewReader(data), &resources))
+	assert.Equal(t, 1, len(resources.Environments))
+	assert.Equal(t, 1, len(resources.Requests))
+	assert.Equal(t, 1, len(resources.Variables))
+	assert.Equal(t, map[string]bool{
+		"environment/local":     true,
+		"request/get-token":     true,
+		"variable/host@local":   true,
+		"variable/host@staging": true,
+	}, resources.names())
+
+	err := readResources(strings.NewReader("requests:\n- name: a\n  verb: GET\n"), &resources)
+	assert.NotNil(t, err)
+}
+
+func TestParentsFirst(t *testing.T) {
+	environments := []Environment{
+		{Name: "prod-eu-west", Parent: "prod-eu"},
+		{Name: "prod-eu", Parent: "prod"},
+		{Name: "staging", Parent: "global"},
+		{Name: "prod"},
+	}
+	names := []string{}
+	for _, environment := range parentsFirst(environments) {
+		names = append(names, environment.Name)
+	}
+	assert.Equal(t, []string{"prod", "prod-eu", "prod-eu-west", "staging"}, names)
+}
+
+func TestReadResourcesInlineVariables(t *testing.T) {
+	data := `environments:
+- name: staging
+  variables:
+  - name: host
+    type: const
+    value: localhost
+`
+	resources := resourceFile{}
+	assert.Nil(t, readResources(strings.NewReader(data), &resources))
+	assert.Equal(t, 0, len(resources.Environments[0].Variables))
+	assert.Equal(t, 1, len(resources.Variables))
+	assert.Equal(t, []string{"staging"}, resources.Variables[0].Environments)
+	assert.True(t, resources.names()["variable/host@staging"])
+}
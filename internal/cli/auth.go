@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"github.com/mcastorina/poster/internal/models"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// addAuthFlags adds the flags of the auth options, which are shared by
+// create request and create environment
+func addAuthFlags(flags *pflag.FlagSet) {
+	flags.String("auth", "", "Auth type (none, basic, bearer, digest, aws-sigv4)")
+	flags.String("auth-user", "", "Username of basic and digest auth")
+	flags.String("auth-password", "", "Password of basic and digest auth")
+	flags.String("auth-token", "", "Token of bearer auth")
+	flags.String("aws-access-key", "", "Access key ID of aws-sigv4 auth")
+	flags.String("aws-secret-key", "", "Secret access key of aws-sigv4 auth")
+	flags.String("aws-session-token", "", "Session token of aws-sigv4 auth")
+	flags.String("aws-region", "", "Region of aws-sigv4 auth, e.g. us-east-1")
+	flags.String("aws-service", "", "Service of aws-sigv4 auth, e.g. execute-api")
+}
+
+// authFromFlags returns the auth of the flags that are set
+func authFromFlags(cmd *cobra.Command) (models.Auth, error) {
+	auth := models.Auth{}
+	flags := cmd.Flags()
+	auth.Type, _ = flags.GetString("auth")
+	auth.Username, _ = flags.GetString("auth-user")
+	auth.Password, _ = flags.GetString("auth-password")
+	auth.Token, _ = flags.GetString("auth-token")
+	auth.AccessKey, _ = flags.GetString("aws-access-key")
+	auth.SecretKey, _ = flags.GetString("aws-secret-key")
+	auth.SessionToken, _ = flags.GetString("aws-session-token")
+	auth.Region, _ = flags.GetString("aws-region")
+	auth.Service, _ = flags.GetString("aws-service")
+	return auth, auth.Validate()
+}
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"github.com/mcastorina/poster/internal/models"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// addClientFlags adds the flags of the client profile options, which are
+// shared by create request, create environment and run
+func addClientFlags(flags *pflag.FlagSet) {
+	flags.String("timeout", "", "Timeout of the request, e.g. 30s")
+	flags.Int("max-redirects", 0, "Number of redirects to follow")
+	flags.Bool("no-follow", false, "Do not follow redirects")
+	flags.String("proxy", "", "Proxy URL")
+	flags.BoolP("insecure", "k", false, "Skip verification of the server certificate")
+	flags.String("cacert", "", "PEM file of CA certificates to trust")
+}
+
+// clientProfileFromFlags returns a profile with the options of the flags
+// that are set
+func clientProfileFromFlags(cmd *cobra.Command) (models.ClientProfile, error) {
+	profile := models.ClientProfile{}
+	flags := cmd.Flags()
+	profile.Timeout, _ = flags.GetString("timeout")
+	profile.Proxy, _ = flags.GetString("proxy")
+	profile.CACert, _ = flags.GetString("cacert")
+	if flags.Changed("max-redirects") {
+		maxRedirects, _ := flags.GetInt("max-redirects")
+		profile.MaxRedirects = &maxRedirects
+	}
+	if noFollow, _ := flags.GetBool("no-follow"); noFollow {
+		if profile.MaxRedirects != nil && *profile.MaxRedirects != 0 {
+			return models.ClientProfile{}, errorNoFollowRedirects
+		}
+		maxRedirects := 0
+		profile.MaxRedirects = &maxRedirects
+	}
+	if flags.Changed("insecure") {
+		insecure, _ := flags.GetBool("insecure")
+		profile.Insecure = &insecure
+	}
+	return profile, profile.Validate()
+}
+
+// addCertificateFlags adds the flags of the client certificate of an
+// environment
+func addCertificateFlags(flags *pflag.FlagSet) {
+	flags.String("cert", "", "PEM file of the client certificate")
+	flags.String("key", "", "PEM file of the client certificate key")
+	flags.String("pkcs12", "", "PKCS#12 file of the client certificate and key")
+	flags.String("pkcs12-password", "", "Password of the PKCS#12 file")
+}
+
+// clientCertificateFromFlags returns the client certificate of the flags
+func clientCertificateFromFlags(cmd *cobra.Command) (models.ClientCertificate, error) {
+	certificate := models.ClientCertificate{}
+	flags := cmd.Flags()
+	certificate.Cert, _ = flags.GetString("cert")
+	certificate.Key, _ = flags.GetString("key")
+	certificate.PKCS12, _ = flags.GetString("pkcs12")
+	certificate.Password, _ = flags.GetString("pkcs12-password")
+	return certificate, certificate.Validate()
+}
@@ -0,0 +1,577 @@
+package cli
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mcastorina/poster/internal/models"
+)
+
+// collection holds the resources converted from another tool's format along
+// with a warning for everything that could not be converted.
+type collection struct {
+	environments []models.Environment
+	variables    []models.Variable
+	requests     []models.Request
+	warnings     []string
+}
+
+func (c *collection) warnf(format string, args ...interface{}) {
+	c.warnings = append(c.warnings, fmt.Sprintf(format, args...))
+}
+func (c *collection) addEnvironment(name string) {
+	for _, env := range c.environments {
+		if env.Name == name {
+			return
+		}
+	}
+	c.environments = append(c.environments, models.Environment{Name: name})
+}
+func (c *collection) addVariable(name, value, environment string) {
+	varName := sanitizeVariableName(name)
+	if varName != name {
+		c.warnf("variable %s renamed to %s", name, varName)
+	}
+	c.variables = append(c.variables, models.Variable{
+		Name:        varName,
+		Value:       value,
+		Type:        models.ConstType,
+		Environment: models.Environment{Name: environment},
+	})
+}
+func (c *collection) addRequest(request models.Request) {
+	name := slugify(request.Name)
+	if name == "" {
+		name = "request"
+	}
+	unique := name
+	for i := 2; c.hasRequest(unique); i++ {
+		unique = fmt.Sprintf("%s-%d", name, i)
+	}
+	if unique != name {
+		c.warnf("request %s renamed to %s to avoid a name collision", name, unique)
+	}
+	request.Name = unique
+	c.requests = append(c.requests, request)
+}
+func (c *collection) hasRequest(name string) bool {
+	for _, request := range c.requests {
+		if request.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// validate checks every resource, so nothing is saved if one of them is
+// invalid
+func (c *collection) validate() error {
+	for _, env := range c.environments {
+		if err := env.Validate(); err != nil {
+			return fmt.Errorf("environment %s: %+v", env.Name, err)
+		}
+	}
+	for _, variable := range c.variables {
+		if err := variable.Validate(); err != nil {
+			return fmt.Errorf("variable %s: %+v", variable.Name, err)
+		}
+	}
+	for _, request := range c.requests {
+		if err := request.Validate(); err != nil {
+			return fmt.Errorf("request %s: %+v", request.Name, err)
+		}
+	}
+	return nil
+}
+
+// save validates the collection and stores the environments, then the
+// variables and then the requests. Existing environments are left as they
+// are.
+func (c *collection) save() error {
+	if err := c.validate(); err != nil {
+		return err
+	}
+	existing := make(map[string]bool)
+	for _, env := range models.GetAllEnvironments() {
+		existing[env.Name] = true
+	}
+	for _, env := range c.environments {
+		if existing[env.Name] {
+			continue
+		}
+		if err := env.Save(); err != nil {
+			return fmt.Errorf("environment %s: %+v", env.Name, err)
+		}
+	}
+	for _, variable := range c.variables {
+		if err := variable.Save(); err != nil {
+			return fmt.Errorf("variable %s: %+v", variable.Name, err)
+		}
+	}
+	for _, request := range c.requests {
+		if err := request.Save(); err != nil {
+			return fmt.Errorf("request %s: %+v", request.Name, err)
+		}
+	}
+	return nil
+}
+
+// translatePlaceholders converts {{name}} placeholders into :name references
+// and escapes the colons of the text around them. The prefix is stripped
+// from the name (e.g. "_." for Insomnia).
+func (c *collection) translatePlaceholders(input, prefix string) string {
+	return c.translate(input, prefix, models.EscapeText)
+}
+
+// translateValue converts the placeholders of a variable value, which is not
+// a template so its text is kept as it is
+func (c *collection) translateValue(input, prefix string) string {
+	return c.translate(input, prefix, func(text string) string { return text })
+}
+
+// translate converts the placeholders of input, passing the text between
+// them through escape
+func (c *collection) translate(input, prefix string, escape func(string) string) string {
+	re := regexp.MustCompile(`\{\{\s*([^{}]+?)\s*\}\}`)
+	output := strings.Builder{}
+	last := 0
+	for _, match := range re.FindAllStringSubmatchIndex(input, -1) {
+		output.WriteString(escape(input[last:match[0]]))
+		last = match[1]
+		name := strings.TrimPrefix(input[match[2]:match[3]], prefix)
+		if strings.HasPrefix(name, "$") {
+			c.warnf("dynamic variable %s is not supported", input[match[0]:match[1]])
+			output.WriteString(escape(input[match[0]:match[1]]))
+			continue
+		}
+		output.WriteString(models.VariableReference(sanitizeVariableName(name), input[match[1]:]))
+	}
+	output.WriteString(escape(input[last:]))
+	if strings.Contains(input, "{%") {
+		c.warnf("template tags are not supported: %s", input)
+	}
+	return output.String()
+}
+
+// formParam URL encodes a form parameter, leaving variable references intact.
+func (c *collection) formParam(key, value, prefix string) string {
+	return c.translate(key, prefix, url.QueryEscape) + "=" + c.translate(value, prefix, url.QueryEscape)
+}
+
+// Postman v2.1 collections and environments
+type postmanCollection struct {
+	Item     []postmanItem     `json:"item"`
+	Variable []postmanKeyValue `json:"variable"`
+	Auth     *postmanAuth      `json:"auth"`
+	Event    []json.RawMessage `json:"event"`
+	Values   []postmanKeyValue `json:"values"`
+	Name     string            `json:"name"`
+	Scope    string            `json:"_postman_variable_scope"`
+}
+type postmanItem struct {
+	Name    string            `json:"name"`
+	Item    []postmanItem     `json:"item"`
+	Request json.RawMessage   `json:"request"`
+	Auth    *postmanAuth      `json:"auth"`
+	Event   []json.RawMessage `json:"event"`
+}
+type postmanRequest struct {
+	Method string            `json:"method"`
+	URL    json.RawMessage   `json:"url"`
+	Header []postmanKeyValue `json:"header"`
+	Body   *postmanBody      `json:"body"`
+	Auth   *postmanAuth      `json:"auth"`
+}
+type postmanKeyValue struct {
+	Key      string  `json:"key"`
+	Value    dynamic `json:"value"`
+	Disabled bool    `json:"disabled"`
+	Enabled  *bool   `json:"enabled"`
+}
+type postmanBody struct {
+	Mode       string            `json:"mode"`
+	Raw        string            `json:"raw"`
+	URLEncoded []postmanKeyValue `json:"urlencoded"`
+	GraphQL    *struct {
+		Query     string `json:"query"`
+		Variables string `json:"variables"`
+	} `json:"graphql"`
+}
+type postmanAuth struct {
+	Type   string            `json:"type"`
+	Bearer []postmanKeyValue `json:"bearer"`
+	Basic  []postmanKeyValue `json:"basic"`
+	APIKey []postmanKeyValue `json:"apikey"`
+}
+
+// dynamic is a JSON value of any type represented as a string
+type dynamic string
+
+func (d *dynamic) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*d = dynamic(s)
+		return nil
+	}
+	if string(data) == "null" {
+		*d = ""
+		return nil
+	}
+	*d = dynamic(data)
+	return nil
+}
+
+func parsePostman(data []byte, environment string) (collection, error) {
+	c := collection{}
+	pc := postmanCollection{}
+	if err := json.Unmarshal(data, &pc); err != nil {
+		return c, err
+	}
+
+	// Environment export
+	if pc.Scope == "environment" || pc.Scope == "globals" {
+		envName := slugify(pc.Name)
+		if pc.Scope == "globals" {
+			envName = globalEnvironment
+		}
+		c.addEnvironment(envName)
+		for _, kv := range pc.Values {
+			if kv.Enabled != nil && !*kv.Enabled {
+				c.warnf("disabled variable %s skipped", kv.Key)
+				continue
+			}
+			c.addVariable(kv.Key, string(kv.Value), envName)
+		}
+		return c, nil
+	}
+
+	c.addEnvironment(environment)
+	for _, kv := range pc.Variable {
+		if kv.Disabled {
+			c.warnf("disabled variable %s skipped", kv.Key)
+			continue
+		}
+		c.addVariable(kv.Key, c.translateValue(string(kv.Value), ""), environment)
+	}
+	if len(pc.Event) > 0 {
+		c.warnf("collection scripts are not supported")
+	}
+	c.addPostmanItems(pc.Item, "", pc.Auth, environment)
+	return c, nil
+}
+func (c *collection) addPostmanItems(items []postmanItem, prefix string, auth *postmanAuth, environment string) {
+	for _, item := range items {
+		name := item.Name
+		if prefix != "" {
+			name = prefix + "-" + name
+		}
+		itemAuth := auth
+		if item.Auth != nil && item.Auth.Type != "inherit" {
+			itemAuth = item.Auth
+		}
+		if len(item.Event) > 0 {
+			c.warnf("scripts in %s are not supported", name)
+		}
+		// Folder
+		if item.Request == nil {
+			c.addPostmanItems(item.Item, name, itemAuth, environment)
+			continue
+		}
+
+		pr := postmanRequest{}
+		var rawURL string
+		if err := json.Unmarshal(item.Request, &rawURL); err == nil {
+			pr.Method = "GET"
+			pr.URL, _ = json.Marshal(rawURL)
+		} else if err := json.Unmarshal(item.Request, &pr); err != nil {
+			c.warnf("request %s skipped: %+v", name, err)
+			continue
+		}
+		if pr.Auth != nil && pr.Auth.Type != "inherit" {
+			itemAuth = pr.Auth
+		}
+
+		request := models.Request{
+			Name:        name,
+			Method:      strings.ToUpper(pr.Method),
+			Environment: models.Environment{Name: environment},
+			Headers:     []models.Header{},
+		}
+		if request.Method == "" {
+			request.Method = "GET"
+		}
+		// URL is either a string or an object with the raw string
+		if err := json.Unmarshal(pr.URL, &rawURL); err != nil {
+			urlObj := struct {
+				Raw string `json:"raw"`
+			}{}
+			json.Unmarshal(pr.URL, &urlObj)
+			rawURL = urlObj.Raw
+		}
+		if rawURL == "" {
+			c.warnf("request %s has no URL", name)
+		}
+		request.URL = c.translatePlaceholders(rawURL, "")
+
+		for _, kv := range pr.Header {
+			if kv.Disabled {
+				continue
+			}
+			request.Headers = append(request.Headers, models.Header{
+				Key:   c.translatePlaceholders(kv.Key, ""),
+				Value: c.translatePlaceholders(string(kv.Value), ""),
+			})
+		}
+		if pr.Body != nil {
+			switch pr.Body.Mode {
+			case "raw":
+				request.Body = c.translatePlaceholders(pr.Body.Raw, "")
+			case "urlencoded":
+				params := []string{}
+				for _, kv := range pr.Body.URLEncoded {
+					if kv.Disabled {
+						continue
+					}
+					params = append(params, c.formParam(kv.Key, string(kv.Value), ""))
+				}
+				request.Body = strings.Join(params, "&")
+				addHeaderIfMissing(&request, "Content-Type", "application/x-www-form-urlencoded")
+			case "graphql":
+				if pr.Body.GraphQL != nil {
+					body := map[string]interface{}{"query": pr.Body.GraphQL.Query}
+					var variables interface{}
+					if err := json.Unmarshal([]byte(pr.Body.GraphQL.Variables), &variables); err == nil {
+						body["variables"] = variables
+					}
+					data, _ := json.Marshal(body)
+					request.Body = c.translatePlaceholders(string(data), "")
+					addHeaderIfMissing(&request, "Content-Type", "application/json")
+				}
+			case "":
+			default:
+				c.warnf("body mode %s in %s is not supported", pr.Body.Mode, name)
+			}
+		}
+		if itemAuth != nil {
+			c.addPostmanAuth(&request, itemAuth)
+		}
+		c.addRequest(request)
+	}
+}
+func (c *collection) addPostmanAuth(r *models.Request, auth *postmanAuth) {
+	params := func(kvs []postmanKeyValue) map[string]string {
+		m := make(map[string]string)
+		for _, kv := range kvs {
+			m[kv.Key] = c.translatePlaceholders(string(kv.Value), "")
+		}
+		return m
+	}
+	switch auth.Type {
+	case "noauth":
+	case "bearer":
+		addHeaderIfMissing(r, "Authorization", "Bearer "+params(auth.Bearer)["token"])
+	case "basic":
+		p := make(map[string]string)
+		for _, kv := range auth.Basic {
+			if strings.Contains(string(kv.Value), "{{") {
+				c.warnf("basic auth in %s uses variables and cannot be encoded", r.Name)
+				return
+			}
+			p[kv.Key] = string(kv.Value)
+		}
+		credentials := p["username"] + ":" + p["password"]
+		addHeaderIfMissing(r, "Authorization",
+			"Basic "+base64.StdEncoding.EncodeToString([]byte(credentials)))
+	case "apikey":
+		p := params(auth.APIKey)
+		if p["in"] == "query" {
+			c.warnf("apikey auth in the query string of %s is not supported", r.Name)
+			return
+		}
+		addHeaderIfMissing(r, p["key"], p["value"])
+	default:
+		c.warnf("auth type %s in %s is not supported", auth.Type, r.Name)
+	}
+}
+
+// Insomnia v4 exports
+type insomniaExport struct {
+	Type      string             `json:"_type"`
+	Format    int                `json:"__export_format"`
+	Resources []insomniaResource `json:"resources"`
+}
+type insomniaResource struct {
+	ID       string                 `json:"_id"`
+	ParentID string                 `json:"parentId"`
+	Type     string                 `json:"_type"`
+	Name     string                 `json:"name"`
+	Method   string                 `json:"method"`
+	URL      string                 `json:"url"`
+	Data     map[string]interface{} `json:"data"`
+	Body     struct {
+		MimeType string `json:"mimeType"`
+		Text     string `json:"text"`
+		Params   []struct {
+			Name     string `json:"name"`
+			Value    string `json:"value"`
+			Disabled bool   `json:"disabled"`
+		} `json:"params"`
+	} `json:"body"`
+	Headers []struct {
+		Name     string `json:"name"`
+		Value    string `json:"value"`
+		Disabled bool   `json:"disabled"`
+	} `json:"headers"`
+	Authentication map[string]interface{} `json:"authentication"`
+}
+
+func parseInsomnia(data []byte, environment string) (collection, error) {
+	c := collection{}
+	export := insomniaExport{}
+	if err := json.Unmarshal(data, &export); err != nil {
+		return c, err
+	}
+	if export.Type != "export" || export.Format != 4 {
+		return c, errorInvalidImportFile
+	}
+
+	resources := make(map[string]insomniaResource)
+	for _, resource := range export.Resources {
+		resources[resource.ID] = resource
+	}
+	// Name of a resource prefixed by the folders it is in
+	fullName := func(resource insomniaResource) string {
+		name := resource.Name
+		for parent, ok := resources[resource.ParentID]; ok && parent.Type == "request_group"; parent, ok = resources[parent.ParentID] {
+			name = parent.Name + "-" + name
+		}
+		return name
+	}
+
+	c.addEnvironment(environment)
+	// The base environment belongs to the workspace and its sub environments
+	// belong to the base environment.
+	for _, resource := range export.Resources {
+		if resource.Type != "environment" {
+			continue
+		}
+		envName := environment
+		if parent, ok := resources[resource.ParentID]; ok && parent.Type == "environment" {
+			envName = slugify(resource.Name)
+			c.addEnvironment(envName)
+		}
+		keys := []string{}
+		for key := range resource.Data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			switch value := resource.Data[key].(type) {
+			case string:
+				c.addVariable(key, c.translateValue(value, "_."), envName)
+			case float64, bool:
+				c.addVariable(key, fmt.Sprintf("%v", value), envName)
+			default:
+				c.warnf("variable %s in %s is not a string and was skipped", key, resource.Name)
+			}
+		}
+	}
+
+	for _, resource := range export.Resources {
+		switch resource.Type {
+		case "request":
+		case "environment", "request_group", "workspace", "cookie_jar", "api_spec":
+			continue
+		default:
+			c.warnf("resource type %s (%s) is not supported", resource.Type, resource.Name)
+			continue
+		}
+		name := fullName(resource)
+		request := models.Request{
+			Name:        name,
+			Method:      strings.ToUpper(resource.Method),
+			URL:         c.translatePlaceholders(resource.URL, "_."),
+			Environment: models.Environment{Name: environment},
+			Headers:     []models.Header{},
+		}
+		if request.Method == "" {
+			request.Method = "GET"
+		}
+		for _, header := range resource.Headers {
+			if header.Disabled {
+				continue
+			}
+			request.Headers = append(request.Headers, models.Header{
+				Key:   c.translatePlaceholders(header.Name, "_."),
+				Value: c.translatePlaceholders(header.Value, "_."),
+			})
+		}
+		switch resource.Body.MimeType {
+		case "":
+		case "application/x-www-form-urlencoded":
+			params := []string{}
+			for _, param := range resource.Body.Params {
+				if param.Disabled {
+					continue
+				}
+				params = append(params, c.formParam(param.Name, param.Value, "_."))
+			}
+			request.Body = strings.Join(params, "&")
+		case "multipart/form-data", "application/octet-stream":
+			c.warnf("body type %s in %s is not supported", resource.Body.MimeType, name)
+		default:
+			request.Body = c.translatePlaceholders(resource.Body.Text, "_.")
+		}
+		if resource.Body.MimeType != "" {
+			addHeaderIfMissing(&request, "Content-Type", resource.Body.MimeType)
+		}
+		if authType, _ := resource.Authentication["type"].(string); authType != "" {
+			if disabled, _ := resource.Authentication["disabled"].(bool); !disabled {
+				switch authType {
+				case "bearer":
+					token, _ := resource.Authentication["token"].(string)
+					prefix, _ := resource.Authentication["prefix"].(string)
+					if prefix == "" {
+						prefix = "Bearer"
+					}
+					addHeaderIfMissing(&request, "Authorization",
+						models.EscapeText(prefix)+" "+c.translatePlaceholders(token, "_."))
+				default:
+					c.warnf("auth type %s in %s is not supported", authType, name)
+				}
+			}
+		}
+		c.addRequest(request)
+	}
+	return c, nil
+}
+
+// helper functions
+func addHeaderIfMissing(r *models.Request, key, value string) {
+	for _, header := range r.Headers {
+		if strings.EqualFold(header.Key, key) {
+			return
+		}
+	}
+	r.Headers = append(r.Headers, models.Header{Key: key, Value: value})
+}
+func slugify(name string) string {
+	// Split camel case words (e.g. getItem becomes get-item)
+	name = regexp.MustCompile(`([a-z0-9])([A-Z])`).ReplaceAllString(name, "$1-$2")
+	re := regexp.MustCompile(`[^a-z0-9_]+`)
+	return strings.Trim(re.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+func sanitizeVariableName(name string) string {
+	re := regexp.MustCompile(`[^\w-]+`)
+	name = strings.Trim(re.ReplaceAllString(name, "-"), "-")
+	// Variable names start with a letter or _
+	if name != "" && !regexp.MustCompile(`^[A-Za-z_]`).MatchString(name) {
+		name = "_" + name
+	}
+	return name
+}
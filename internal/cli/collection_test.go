@@ -0,0 +1,198 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/mcastorina/poster/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+const testPostmanCollection = `{
+	"info": {"name": "Items", "schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"},
+	"auth": {"type": "bearer", "bearer": [{"key": "token", "value": "{{token}}", "type": "string"}]},
+	"variable": [{"key": "base.url", "value": "http://localhost:8080"}],
+	"item": [
+		{
+			"name": "Items",
+			"item": [
+				{
+					"name": "Create Item",
+					"request": {
+						"method": "POST",
+						"header": [
+							{"key": "Content-Type", "value": "application/json"},
+							{"key": "X-Debug", "value": "1", "disabled": true}
+						],
+						"body": {"mode": "raw", "raw": "{\"id\": \"{{$guid}}\"}"},
+						"url": {"raw": "{{base.url}}/items", "host": ["{{base.url}}"], "path": ["items"]}
+					},
+					"event": [{"listen": "test"}]
+				},
+				{
+					"name": "List Items",
+					"request": {"method": "GET", "url": "{{base.url}}/items?page=1", "auth": {"type": "noauth"}}
+				}
+			]
+		},
+		{
+			"name": "Upload",
+			"request": {"method": "PUT", "url": "{{base.url}}/upload", "body": {"mode": "formdata"}}
+		}
+	]
+}`
+
+const testPostmanEnvironment = `{
+	"name": "Staging US",
+	"values": [
+		{"key": "token", "value": "abc", "enabled": true},
+		{"key": "old", "value": "x", "enabled": false}
+	],
+	"_postman_variable_scope": "environment"
+}`
+
+const testInsomniaExport = `{
+	"_type": "export",
+	"__export_format": 4,
+	"resources": [
+		{"_id": "wrk_1", "_type": "workspace", "name": "Items"},
+		{"_id": "env_1", "parentId": "wrk_1", "_type": "environment", "name": "Base", "data": {"host": "localhost:8080", "nested": {"a": 1}}},
+		{"_id": "env_2", "parentId": "env_1", "_type": "environment", "name": "Production", "data": {"host": "example.com"}},
+		{"_id": "fld_1", "parentId": "wrk_1", "_type": "request_group", "name": "Items"},
+		{
+			"_id": "req_1", "parentId": "fld_1", "_type": "request", "name": "Create Item",
+			"method": "post", "url": "{{ _.host }}/items",
+			"body": {"mimeType": "application/json", "text": "{\"name\": \"{% uuid 'v4' %}\"}"},
+			"headers": [{"name": "Accept", "value": "*/*"}],
+			"authentication": {"type": "bearer", "token": "{{ _.token }}"}
+		},
+		{
+			"_id": "req_2", "parentId": "wrk_1", "_type": "request", "name": "Login",
+			"method": "POST", "url": "{{ _.host }}/login",
+			"body": {"mimeType": "application/x-www-form-urlencoded", "params": [{"name": "user", "value": "{{ _.user }}"}]},
+			"authentication": {"type": "oauth2"}
+		}
+	]
+}`
+
+func TestParsePostmanCollection(t *testing.T) {
+	c, err := parsePostman([]byte(testPostmanCollection), "local")
+	assert.Nil(t, err)
+
+	assert.Equal(t, []models.Environment{{Name: "local"}}, c.environments)
+	assert.Equal(t, 1, len(c.variables))
+	assert.Equal(t, "base-url", c.variables[0].Name)
+	assert.Equal(t, "http://localhost:8080", c.variables[0].Value)
+
+	assert.Equal(t, 3, len(c.requests))
+	create := c.requests[0]
+	assert.Equal(t, "items-create-item", create.Name)
+	assert.Equal(t, "POST", create.Method)
+	assert.Equal(t, ":base-url/items", create.URL)
+	assert.Equal(t, `{"id": "{{$guid}}"}`, create.Body)
+	assert.Equal(t, []models.Header{
+		{Key: "Content-Type", Value: "application/json"},
+		{Key: "Authorization", Value: "Bearer :token"},
+	}, create.Headers)
+
+	list := c.requests[1]
+	assert.Equal(t, "items-list-items", list.Name)
+	assert.Equal(t, ":base-url/items?page=1", list.URL)
+	assert.Equal(t, []models.Header{}, list.Headers)
+
+	assert.Equal(t, "upload", c.requests[2].Name)
+
+	assert.Contains(t, c.warnings, "variable base.url renamed to base-url")
+	assert.Contains(t, c.warnings, "dynamic variable {{$guid}} is not supported")
+	assert.Contains(t, c.warnings, "scripts in Items-Create Item are not supported")
+	assert.Contains(t, c.warnings, "body mode formdata in Upload is not supported")
+}
+func TestParsePostmanEnvironment(t *testing.T) {
+	c, err := parsePostman([]byte(testPostmanEnvironment), "local")
+	assert.Nil(t, err)
+	assert.Equal(t, []models.Environment{{Name: "staging-us"}}, c.environments)
+	assert.Equal(t, 1, len(c.variables))
+	assert.Equal(t, "token", c.variables[0].Name)
+	assert.Equal(t, "staging-us", c.variables[0].Environment.Name)
+	assert.Contains(t, c.warnings, "disabled variable old skipped")
+}
+func TestParseInsomnia(t *testing.T) {
+	c, err := parseInsomnia([]byte(testInsomniaExport), "local")
+	assert.Nil(t, err)
+
+	assert.Equal(t, []models.Environment{{Name: "local"}, {Name: "production"}}, c.environments)
+	assert.Equal(t, 2, len(c.variables))
+	assert.Equal(t, "host", c.variables[0].Name)
+	assert.Equal(t, "local", c.variables[0].Environment.Name)
+	assert.Equal(t, "example.com", c.variables[1].Value)
+	assert.Equal(t, "production", c.variables[1].Environment.Name)
+
+	assert.Equal(t, 2, len(c.requests))
+	create := c.requests[0]
+	assert.Equal(t, "items-create-item", create.Name)
+	assert.Equal(t, "POST", create.Method)
+	assert.Equal(t, ":host/items", create.URL)
+	assert.Equal(t, []models.Header{
+		{Key: "Accept", Value: "*/*"},
+		{Key: "Content-Type", Value: "application/json"},
+		{Key: "Authorization", Value: "Bearer :token"},
+	}, create.Headers)
+
+	login := c.requests[1]
+	assert.Equal(t, "login", login.Name)
+	assert.Equal(t, "user=:user", login.Body)
+
+	assert.Contains(t, c.warnings, "variable nested in Base is not a string and was skipped")
+	assert.Contains(t, c.warnings, "auth type oauth2 in Login is not supported")
+	assert.Contains(t, c.warnings, `template tags are not supported: {"name": "{% uuid 'v4' %}"}`)
+}
+func TestParsePostmanPlaceholders(t *testing.T) {
+	c, err := parsePostman([]byte(`{
+		"item": [{
+			"name": "Get Book",
+			"request": {
+				"method": "POST",
+				"url": "{{host}}/books/{{id}}abc/urn:isbn:{{isbn}}",
+				"body": {"mode": "urlencoded", "urlencoded": [{"key": "q", "value": "a:b {{q}}x"}]}
+			}
+		}],
+		"variable": [{"key": "host", "value": "urn:host"}]
+	}`), "local")
+	assert.Nil(t, err)
+	assert.Equal(t, ":host/books/:{id}abc/urn\\:isbn::isbn", c.requests[0].URL)
+	assert.Equal(t, "q=a%3Ab+:{q}x", c.requests[0].Body)
+	// Variable values are not templates
+	assert.Equal(t, "urn:host", c.variables[0].Value)
+	assert.Equal(t, []string(nil), c.warnings)
+}
+func TestParseInsomniaInvalid(t *testing.T) {
+	_, err := parseInsomnia([]byte(`{"_type": "export", "__export_format": 3}`), "local")
+	assert.Equal(t, errorInvalidImportFile, err)
+}
+func TestSanitizeVariableName(t *testing.T) {
+	tests := map[string]string{
+		"base.url": "base-url",
+		"_.host":   "_-host",
+		"1st":      "_1st",
+		"-page-":   "page",
+		"$guid":    "guid",
+	}
+	for name, expected := range tests {
+		assert.Equal(t, expected, sanitizeVariableName(name), name)
+	}
+}
+func TestCollectionValidate(t *testing.T) {
+	c, err := parseInsomnia([]byte(`{
+		"_type": "export", "__export_format": 4,
+		"resources": [{"_id": "req_1", "_type": "request", "name": "Ping", "url": "localhost"}]
+	}`), "local")
+	assert.Nil(t, err)
+	assert.Equal(t, "GET", c.requests[0].Method)
+	assert.Nil(t, c.validate())
+
+	// A single invalid request stops the whole collection from being saved
+	c.requests = append(c.requests, models.Request{Name: "fetch", Method: "FETCH", URL: "localhost"})
+	err = c.validate()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "request fetch")
+	assert.NotNil(t, c.save())
+}
@@ -17,8 +17,13 @@ var createCmd = &cobra.Command{
 	Long: `Create a resource. Valid resource types:
 	
     request            Method, url, and default environment to run the request
+    suite              Ordered list of requests to run together
     environment        Name of an environment for variable scope
     const-variable     Environment dependent constant values
+    env-variable       Value read from an environment variable
+    dotenv-variable    Value read from a .env file
+    prompt-variable    Value asked for when a request is run
+    oauth2-variable    Access token from an OAuth2 token endpoint
 `,
 }
 var createRequestCmd = &cobra.Command{
@@ -32,10 +37,44 @@ contains the following attributes:
     method              HTTP request method
     url                 The URL path
     environment         The default environment to run the request
+    assertions          Checks on the response (see poster run --help)
+    client              Timeout, redirect, proxy and TLS options
+    auth                Credentials of the request
+
+The client options of the request take precedence over the ones of its
+environment.
+
+The auth is set with --auth and the credentials of its type:
+
+    basic               --auth-user and --auth-password
+    bearer              --auth-token, sent as Authorization: Bearer TOKEN
+    digest              --auth-user and --auth-password, sent in answer to the
+                        Digest challenge of the server
+    aws-sigv4           --aws-access-key, --aws-secret-key, --aws-region,
+                        --aws-service and optionally --aws-session-token
+    none                No credentials, even if the environment has an auth
+
+Credentials may contain variables, e.g. --auth-token :token. A request without
+an auth uses the auth of its environment, so a request that generates the
+token of the environment should have --auth none.
 `,
 	Run:  createRequest,
 	Args: createRequestArgs,
 }
+var createSuiteCmd = &cobra.Command{
+	Use:     "suite NAME REQUEST [REQUEST ...]",
+	Aliases: []string{"s"},
+	Short:   "Create a suite resource",
+	Long: `Create suite will create and save a suite resource. A suite resource
+contains the following attributes:
+
+    name                Name of the suite for ease of use
+    requests            Ordered list of request names to run
+    environment         The default environment to run the suite
+`,
+	Run:  createSuite,
+	Args: createSuiteArgs,
+}
 var createEnvironmentCmd = &cobra.Command{
 	Use:     "environment NAME",
 	Aliases: []string{"env", "e"},
@@ -44,6 +83,30 @@ var createEnvironmentCmd = &cobra.Command{
 environment resource contains the following attributes:
 
     name                Name of the environment
+    parent              Environment to inherit variables from (default global)
+    client              Timeout, redirect, proxy and TLS options of requests
+    certificate         Client certificate presented to servers (mutual TLS)
+    auth                Credentials of the requests without one, see
+                        poster create request --help
+    variables           Variables of the environment, as in create --interactive
+
+Variables are resolved in the environment first, then in its parent, and so
+on up to the global environment. For example, prod-eu may have prod as its
+parent and only override the variables that differ between regions. Client
+options are inherited the same way. The proxy and CA certificate may contain
+variables.
+
+The client certificate is read from PEM files with --cert and --key, or from
+a PKCS#12 file with --pkcs12 and --pkcs12-password. The paths and the
+password may contain variables, e.g. --pkcs12-password :p12-password with a
+secret variable. An environment without a certificate uses the one of its
+nearest ancestor.
+
+With --from, the new environment gets a copy of every variable of an
+existing environment, and its parent, client options, certificate and auth
+unless they are set by flags. Request
+variables that run their request in the existing environment run it in the
+new one instead.
 `,
 	Run:  createEnvironment,
 	Args: createEnvironmentArgs,
@@ -60,9 +123,11 @@ A variable resource contains the following attributes:
 
     name                Name of the variable
     value               Current value of the variable
-    type                Type of variable (const, request, script)
+    type                Type of variable (const, request, script, env, dotenv, prompt, oauth2)
     environment         Environment this variable belongs to
     generator           How to generate the value
+    secret              Encrypt the value with a key derived from the file in
+                        POSTER_KEY_FILE or the passphrase in POSTER_PASSPHRASE
 `,
 	Run:  createConstVariable,
 	Args: createConstVariableArgs,
@@ -79,9 +144,11 @@ A variable resource contains the following attributes:
 
     name                Name of the variable
     value               Current value of the variable
-    type                Type of variable (const, request, script)
+    type                Type of variable (const, request, script, env, dotenv, prompt, oauth2)
     environment         Environment this variable belongs to
     generator           How to generate the value
+    secret              Encrypt the value with a key derived from the file in
+                        POSTER_KEY_FILE or the passphrase in POSTER_PASSPHRASE
 `,
 	Run:  createScriptVariable,
 	Args: createScriptVariableArgs,
@@ -98,21 +165,128 @@ A variable resource contains the following attributes:
 
     name                Name of the variable
     value               Current value of the variable
-    type                Type of variable (const, request, script)
+    type                Type of variable (const, request, script, env, dotenv, prompt, oauth2)
     environment         Environment this variable belongs to
     generator           How to generate the value
+    secret              Encrypt the value with a key derived from the file in
+                        POSTER_KEY_FILE or the passphrase in POSTER_PASSPHRASE
 `,
 	Run:  createRequestVariable,
 	Args: createRequestVariableArgs,
 }
 
+var createEnvVariableCmd = &cobra.Command{
+	Use:     "env-variable NAME VARIABLE",
+	Aliases: []string{"env-var", "ev"},
+	Short:   "Create an environment variable backed variable resource",
+	Long: `Create env-variable will create and save a variable resource whose value
+is read from the environment variable VARIABLE every time a request is run.
+The value is never stored, so this is useful for secrets.
+Variables in a request are denoted by prefixing the name with a colon
+(e.g. :variable-name).
+
+A variable resource contains the following attributes:
+
+    name                Name of the variable
+    type                Type of variable (const, request, script, env, dotenv, prompt, oauth2)
+    environment         Environment this variable belongs to
+    generator           Name of the environment variable to read
+`,
+	Run:  createEnvVariable,
+	Args: createEnvVariableArgs,
+}
+var createDotenvVariableCmd = &cobra.Command{
+	Use:     "dotenv-variable NAME PATH",
+	Aliases: []string{"dotenv-var", "dv"},
+	Short:   "Create a .env file backed variable resource",
+	Long: `Create dotenv-variable will create and save a variable resource whose value
+is read from the .env file at PATH every time a request is run. The key in the
+file defaults to the name of the variable. A relative PATH is relative to the
+directory poster is run in. The value is never stored, so this is useful for
+secrets.
+Variables in a request are denoted by prefixing the name with a colon
+(e.g. :variable-name).
+
+A variable resource contains the following attributes:
+
+    name                Name of the variable
+    type                Type of variable (const, request, script, env, dotenv, prompt, oauth2)
+    environment         Environment this variable belongs to
+    generator           Path of the .env file and the key to read
+`,
+	Run:  createDotenvVariable,
+	Args: createDotenvVariableArgs,
+}
+
+var createPromptVariableCmd = &cobra.Command{
+	Use:     "prompt-variable NAME",
+	Aliases: []string{"prompt-var", "pv"},
+	Short:   "Create a prompt variable resource",
+	Long: `Create prompt-variable will create and save a variable resource whose value
+is asked for on the terminal when a request is run. Each prompt variable is
+only asked for once per run, and the answer is never stored. Input is hidden
+for --secret variables. Running fails if stdin is not a terminal, in which
+case the value can be passed with poster run --variable.
+Variables in a request are denoted by prefixing the name with a colon
+(e.g. :variable-name).
+
+A variable resource contains the following attributes:
+
+    name                Name of the variable
+    type                Type of variable (const, request, script, env, dotenv, prompt, oauth2)
+    environment         Environment this variable belongs to
+    generator           The message, default answer and choices of the prompt
+    secret              Hide the input and mask the value in logs and history
+`,
+	Run:  createPromptVariable,
+	Args: createPromptVariableArgs,
+}
+
+var createOAuth2VariableCmd = &cobra.Command{
+	Use:     "oauth2-variable NAME TOKEN_URL",
+	Aliases: []string{"oauth2-var", "ov"},
+	Short:   "Create an OAuth2 access token variable resource",
+	Long: `Create oauth2-variable will create and save a variable resource whose value
+is an access token from the OAuth2 token endpoint at TOKEN_URL. The token is
+requested with the client_credentials, password or refresh_token grant and
+kept until it expires, according to the expires_in of the token response.
+Expired tokens are refreshed with the refresh token if the server issued one,
+otherwise they are requested again with the grant. Tokens without an expiry
+are requested again every time a request is run.
+
+The client is authenticated with HTTP Basic auth if it has a secret, otherwise
+its ID is sent in the form. The URL, client, user and refresh token may
+contain variables, e.g. --client-secret :client-secret with a secret variable.
+Variables in a request are denoted by prefixing the name with a colon
+(e.g. :variable-name).
+
+A variable resource contains the following attributes:
+
+    name                Name of the variable
+    value               Current access token
+    type                Type of variable (const, request, script, env, dotenv, prompt, oauth2)
+    environment         Environment this variable belongs to
+    generator           The token endpoint, grant and credentials
+    secret              Encrypt the token and credentials with a key derived from
+                        the file in POSTER_KEY_FILE or the passphrase in
+                        POSTER_PASSPHRASE
+`,
+	Run:  createOAuth2Variable,
+	Args: createOAuth2VariableArgs,
+}
+
 func init() {
 	rootCmd.AddCommand(createCmd)
 	createCmd.AddCommand(createRequestCmd)
+	createCmd.AddCommand(createSuiteCmd)
 	createCmd.AddCommand(createEnvironmentCmd)
 	createCmd.AddCommand(createConstVariableCmd)
 	createCmd.AddCommand(createScriptVariableCmd)
 	createCmd.AddCommand(createRequestVariableCmd)
+	createCmd.AddCommand(createEnvVariableCmd)
+	createCmd.AddCommand(createDotenvVariableCmd)
+	createCmd.AddCommand(createPromptVariableCmd)
+	createCmd.AddCommand(createOAuth2VariableCmd)
 
 	// create flags
 	createCmd.PersistentFlags().BoolP("interactive", "i", false, "Interactively create the resource")
@@ -122,16 +296,58 @@ func init() {
 	createRequestCmd.Flags().StringP("environment", "e", "", "Default environment for this request")
 	createRequestCmd.Flags().StringP("data", "d", "", "Request body")
 	createRequestCmd.Flags().StringArrayP("header", "H", []string{}, "Request header")
+	createRequestCmd.Flags().StringArray("expect", []string{}, "Assertion on the response")
+	addClientFlags(createRequestCmd.Flags())
+	addAuthFlags(createRequestCmd.Flags())
+
+	// create suite flags
+	createSuiteCmd.Flags().StringP("environment", "e", "", "Default environment for this suite")
+
+	// create environment flags
+	createEnvironmentCmd.Flags().StringP("parent", "p", "", "Environment to inherit variables from")
+	createEnvironmentCmd.Flags().String("from", "", "Environment to copy the variables from")
+	addClientFlags(createEnvironmentCmd.Flags())
+	addCertificateFlags(createEnvironmentCmd.Flags())
+	addAuthFlags(createEnvironmentCmd.Flags())
 
 	// create const-variable flags
 	createConstVariableCmd.Flags().StringP("environment", "e", "", "Environment to store variable in")
+	createConstVariableCmd.Flags().Bool("secret", false, "Encrypt the value of the variable")
 
 	// create script-variable flags
 	createScriptVariableCmd.Flags().StringP("environment", "e", "", "Environment to store variable in")
+	createScriptVariableCmd.Flags().Bool("secret", false, "Encrypt the value of the variable")
 
 	// create request-variable flags
 	createRequestVariableCmd.Flags().StringP("environment", "e", "", "Environment to store variable in")
+	createRequestVariableCmd.Flags().Bool("secret", false, "Encrypt the value of the variable")
 	createRequestVariableCmd.Flags().StringP("jsonpath", "j", "", "JSONPath to extract the value from the result body")
+
+	// create env-variable flags
+	createEnvVariableCmd.Flags().StringP("environment", "e", "", "Environment to store variable in")
+
+	// create dotenv-variable flags
+	createDotenvVariableCmd.Flags().StringP("environment", "e", "", "Environment to store variable in")
+	createDotenvVariableCmd.Flags().StringP("key", "k", "", "Key to read from the file (default is NAME)")
+
+	// create prompt-variable flags
+	createPromptVariableCmd.Flags().StringP("environment", "e", "", "Environment to store variable in")
+	createPromptVariableCmd.Flags().StringP("message", "m", "", "Message to show when asking for the value")
+	createPromptVariableCmd.Flags().StringP("default", "d", "", "Value used when the answer is empty")
+	createPromptVariableCmd.Flags().StringArrayP("choice", "c", []string{}, "Allowed answer")
+	createPromptVariableCmd.Flags().Bool("secret", false, "Hide the input")
+
+	// create oauth2-variable flags
+	createOAuth2VariableCmd.Flags().StringP("environment", "e", "", "Environment to store variable in")
+	createOAuth2VariableCmd.Flags().StringP("grant-type", "g", models.GrantClientCredentials,
+		"Grant used to get tokens (client_credentials, password, refresh_token)")
+	createOAuth2VariableCmd.Flags().String("client-id", "", "Client ID")
+	createOAuth2VariableCmd.Flags().String("client-secret", "", "Client secret")
+	createOAuth2VariableCmd.Flags().StringP("username", "u", "", "Username of the password grant")
+	createOAuth2VariableCmd.Flags().StringP("password", "p", "", "Password of the password grant")
+	createOAuth2VariableCmd.Flags().String("scope", "", "Space separated scopes to request")
+	createOAuth2VariableCmd.Flags().String("refresh-token", "", "Refresh token of the refresh_token grant")
+	createOAuth2VariableCmd.Flags().Bool("secret", false, "Encrypt the token and credentials of the variable")
 }
 
 // run functions
@@ -144,6 +360,7 @@ func createRequest(cmd *cobra.Command, args []string) {
 	environment, _ := cmd.Flags().GetString("environment")
 	body, _ := cmd.Flags().GetString("body")
 	rawHeaders, _ := cmd.Flags().GetStringArray("header")
+	rawAssertions, _ := cmd.Flags().GetStringArray("expect")
 
 	headers := []models.Header{}
 	for _, rawHeader := range rawHeaders {
@@ -154,6 +371,14 @@ func createRequest(cmd *cobra.Command, args []string) {
 		})
 	}
 
+	assertions := []models.Assertion{}
+	for _, rawAssertion := range rawAssertions {
+		assertion, _ := models.ParseAssertion(rawAssertion)
+		assertions = append(assertions, assertion)
+	}
+	client, _ := clientProfileFromFlags(cmd)
+	auth, _ := authFromFlags(cmd)
+
 	request := &models.Request{
 		Name:        name,
 		Method:      args[0],
@@ -161,19 +386,70 @@ func createRequest(cmd *cobra.Command, args []string) {
 		Environment: models.Environment{Name: environment},
 		Body:        body,
 		Headers:     headers,
+		Assertions:  assertions,
+		Client:      client,
+		Auth:        auth,
 	}
 	if err := request.Save(); err != nil {
 		log.Errorf("Could not save request: %+v\n", err)
 		os.Exit(1)
 	}
 }
+func createSuite(cmd *cobra.Command, args []string) {
+	if interactive, _ := cmd.Flags().GetBool("interactive"); interactive {
+		createSuiteI(cmd, args)
+		return
+	}
+	environment, _ := cmd.Flags().GetString("environment")
+	suite := &models.Suite{
+		Name:        args[0],
+		Environment: models.Environment{Name: environment},
+		Requests:    args[1:],
+	}
+	if err := suite.Save(); err != nil {
+		log.Errorf("Could not save suite: %+v\n", err)
+		os.Exit(1)
+	}
+}
 func createEnvironment(cmd *cobra.Command, args []string) {
 	if interactive, _ := cmd.Flags().GetBool("interactive"); interactive {
 		createEnvironmentI(cmd, args)
 		return
 	}
+	parent, _ := cmd.Flags().GetString("parent")
+	client, _ := clientProfileFromFlags(cmd)
+	certificate, _ := clientCertificateFromFlags(cmd)
+	auth, _ := authFromFlags(cmd)
 	env := &models.Environment{
-		Name: args[0],
+		Name:        args[0],
+		Parent:      parent,
+		Client:      client,
+		Certificate: certificate,
+		Auth:        auth,
+	}
+	if from, _ := cmd.Flags().GetString("from"); from != "" {
+		source, err := models.GetEnvironmentByName(from)
+		if err != nil {
+			log.Errorf("Could not find environment %s: %+v\n", from, err)
+			os.Exit(1)
+		}
+		if !flagsAreSet(cmd, "parent") {
+			env.Parent = source.Parent
+		}
+		if client.IsEmpty() {
+			env.Client = source.Client
+		}
+		if certificate.IsEmpty() {
+			env.Certificate = source.Certificate
+		}
+		if auth.IsEmpty() {
+			env.Auth = source.Auth
+		}
+		if err := env.Clone(source); err != nil {
+			log.Errorf("Could not clone environment: %+v\n", err)
+			os.Exit(1)
+		}
+		return
 	}
 	if err := env.Save(); err != nil {
 		log.Errorf("Could not save environment: %+v\n", err)
@@ -186,9 +462,11 @@ func createConstVariable(cmd *cobra.Command, args []string) {
 		return
 	}
 	environment, _ := cmd.Flags().GetString("environment")
+	secret, _ := cmd.Flags().GetBool("secret")
 	variable := &models.Variable{
 		Name:        args[0],
 		Value:       args[1],
+		Secret:      secret,
 		Type:        models.ConstType,
 		Environment: models.Environment{Name: environment},
 	}
@@ -203,8 +481,10 @@ func createScriptVariable(cmd *cobra.Command, args []string) {
 		return
 	}
 	environment, _ := cmd.Flags().GetString("environment")
+	secret, _ := cmd.Flags().GetBool("secret")
 	variable := &models.Variable{
 		Name:        args[0],
+		Secret:      secret,
 		Type:        models.ScriptType,
 		Environment: models.Environment{Name: environment},
 		Generator: &models.VariableGenerator{
@@ -223,13 +503,118 @@ func createRequestVariable(cmd *cobra.Command, args []string) {
 	}
 	environment, _ := cmd.Flags().GetString("environment")
 	jPath, _ := cmd.Flags().GetString("jsonpath")
+	secret, _ := cmd.Flags().GetBool("secret")
 	variable := &models.Variable{
 		Name:        args[0],
+		Secret:      secret,
 		Type:        models.RequestType,
 		Environment: models.Environment{Name: environment},
 		Generator: &models.VariableGenerator{
 			RequestName: args[1],
 			RequestPath: jPath,
+			// Run the request in the environment of the variable
+			RequestEnvironment: environment,
+		},
+	}
+	if err := variable.Save(); err != nil {
+		log.Errorf("Could not save variable: %+v\n", err)
+		os.Exit(1)
+	}
+}
+
+func createEnvVariable(cmd *cobra.Command, args []string) {
+	if interactive, _ := cmd.Flags().GetBool("interactive"); interactive {
+		createEnvVariableI(cmd, args)
+		return
+	}
+	environment, _ := cmd.Flags().GetString("environment")
+	variable := &models.Variable{
+		Name:        args[0],
+		Type:        models.EnvType,
+		Environment: models.Environment{Name: environment},
+		Generator: &models.VariableGenerator{
+			Key: args[1],
+		},
+	}
+	if err := variable.Save(); err != nil {
+		log.Errorf("Could not save variable: %+v\n", err)
+		os.Exit(1)
+	}
+}
+func createDotenvVariable(cmd *cobra.Command, args []string) {
+	if interactive, _ := cmd.Flags().GetBool("interactive"); interactive {
+		createDotenvVariableI(cmd, args)
+		return
+	}
+	environment, _ := cmd.Flags().GetString("environment")
+	key, _ := cmd.Flags().GetString("key")
+	if key == "" {
+		key = args[0]
+	}
+	variable := &models.Variable{
+		Name:        args[0],
+		Type:        models.DotenvType,
+		Environment: models.Environment{Name: environment},
+		Generator: &models.VariableGenerator{
+			Key:  key,
+			File: args[1],
+		},
+	}
+	if err := variable.Save(); err != nil {
+		log.Errorf("Could not save variable: %+v\n", err)
+		os.Exit(1)
+	}
+}
+
+func createPromptVariable(cmd *cobra.Command, args []string) {
+	if interactive, _ := cmd.Flags().GetBool("interactive"); interactive {
+		createPromptVariableI(cmd, args)
+		return
+	}
+	environment, _ := cmd.Flags().GetString("environment")
+	message, _ := cmd.Flags().GetString("message")
+	defaultValue, _ := cmd.Flags().GetString("default")
+	choices, _ := cmd.Flags().GetStringArray("choice")
+	secret, _ := cmd.Flags().GetBool("secret")
+	variable := &models.Variable{
+		Name:        args[0],
+		Secret:      secret,
+		Type:        models.PromptType,
+		Environment: models.Environment{Name: environment},
+		Generator: &models.VariableGenerator{
+			Prompt:  message,
+			Default: defaultValue,
+			Choices: choices,
+		},
+	}
+	if err := variable.Save(); err != nil {
+		log.Errorf("Could not save variable: %+v\n", err)
+		os.Exit(1)
+	}
+}
+
+func createOAuth2Variable(cmd *cobra.Command, args []string) {
+	if interactive, _ := cmd.Flags().GetBool("interactive"); interactive {
+		createOAuth2VariableI(cmd, args)
+		return
+	}
+	environment, _ := cmd.Flags().GetString("environment")
+	secret, _ := cmd.Flags().GetBool("secret")
+	settings := &models.OAuth2Settings{TokenURL: args[1]}
+	settings.GrantType, _ = cmd.Flags().GetString("grant-type")
+	settings.ClientID, _ = cmd.Flags().GetString("client-id")
+	settings.ClientSecret, _ = cmd.Flags().GetString("client-secret")
+	settings.Username, _ = cmd.Flags().GetString("username")
+	settings.Password, _ = cmd.Flags().GetString("password")
+	settings.Scope, _ = cmd.Flags().GetString("scope")
+	settings.RefreshToken, _ = cmd.Flags().GetString("refresh-token")
+	variable := &models.Variable{
+		Name:        args[0],
+		Secret:      secret,
+		Type:        models.OAuth2Type,
+		Environment: models.Environment{Name: environment},
+		Generator: &models.VariableGenerator{
+			OAuth2: settings,
 		},
 	}
 	if err := variable.Save(); err != nil {
@@ -255,6 +640,7 @@ func createRequestArgs(cmd *cobra.Command, args []string) error {
 		"HEAD":    true,
 		"POST":    true,
 		"PUT":     true,
+		"PATCH":   true,
 		"DELETE":  true,
 		"CONNECT": true,
 		"OPTIONS": true,
@@ -271,6 +657,27 @@ func createRequestArgs(cmd *cobra.Command, args []string) error {
 			return err
 		}
 	}
+	// check assertions are valid
+	if err := checkRawAssertions(cmd); err != nil {
+		return err
+	}
+	// check client and auth options are valid
+	if _, err := clientProfileFromFlags(cmd); err != nil {
+		return err
+	}
+	_, err := authFromFlags(cmd)
+	return err
+}
+func createSuiteArgs(cmd *cobra.Command, args []string) error {
+	if interactive, _ := cmd.Flags().GetBool("interactive"); interactive {
+		return nil
+	}
+	if len(args) < 2 {
+		return errorMissingArgs("NAME REQUEST")
+	}
+	if !flagsAreSet(cmd, "environment") {
+		return errorMissingFlag("--environment")
+	}
 	return nil
 }
 func createEnvironmentArgs(cmd *cobra.Command, args []string) error {
@@ -280,7 +687,14 @@ func createEnvironmentArgs(cmd *cobra.Command, args []string) error {
 	if len(args) != 1 {
 		return errorMissingArg("NAME")
 	}
-	return nil
+	if _, err := clientProfileFromFlags(cmd); err != nil {
+		return err
+	}
+	if _, err := clientCertificateFromFlags(cmd); err != nil {
+		return err
+	}
+	_, err := authFromFlags(cmd)
+	return err
 }
 func createConstVariableArgs(cmd *cobra.Command, args []string) error {
 	if interactive, _ := cmd.Flags().GetBool("interactive"); interactive {
@@ -319,6 +733,57 @@ func createRequestVariableArgs(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func createEnvVariableArgs(cmd *cobra.Command, args []string) error {
+	if interactive, _ := cmd.Flags().GetBool("interactive"); interactive {
+		return nil
+	}
+	if len(args) != 2 {
+		return errorMissingArgs("NAME VARIABLE")
+	}
+	if !flagsAreSet(cmd, "environment") {
+		return errorMissingFlag("--environment")
+	}
+	return nil
+}
+func createDotenvVariableArgs(cmd *cobra.Command, args []string) error {
+	if interactive, _ := cmd.Flags().GetBool("interactive"); interactive {
+		return nil
+	}
+	if len(args) != 2 {
+		return errorMissingArgs("NAME PATH")
+	}
+	if !flagsAreSet(cmd, "environment") {
+		return errorMissingFlag("--environment")
+	}
+	return nil
+}
+
+func createPromptVariableArgs(cmd *cobra.Command, args []string) error {
+	if interactive, _ := cmd.Flags().GetBool("interactive"); interactive {
+		return nil
+	}
+	if len(args) != 1 {
+		return errorMissingArg("NAME")
+	}
+	if !flagsAreSet(cmd, "environment") {
+		return errorMissingFlag("--environment")
+	}
+	return nil
+}
+
+func createOAuth2VariableArgs(cmd *cobra.Command, args []string) error {
+	if interactive, _ := cmd.Flags().GetBool("interactive"); interactive {
+		return nil
+	}
+	if len(args) != 2 {
+		return errorMissingArgs("NAME TOKEN_URL")
+	}
+	if !flagsAreSet(cmd, "environment") {
+		return errorMissingFlag("--environment")
+	}
+	return nil
+}
+
 // helper functions
 func rawHeaderToSlice(header string) ([]string, error) {
 	values := strings.SplitN(header, ":", 2)
@@ -357,6 +822,25 @@ func createRequestI(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 }
+func createSuiteI(cmd *cobra.Command, args []string) {
+	template := suiteTemplate()
+	var err error
+	data, _ := yaml.Marshal(template)
+	data, err = updateData(data)
+	if err != nil {
+		log.Errorf("Failed to create suite: %+v\n", err)
+		os.Exit(1)
+	}
+	resource := Suite{}
+	if err := yaml.Unmarshal([]byte(data), &resource); err != nil {
+		log.Errorf("Failed to create suite: %+v\n", err)
+		os.Exit(1)
+	}
+	if err := resource.Save(); err != nil {
+		log.Errorf("Failed to create suite: %+v\n", err)
+		os.Exit(1)
+	}
+}
 func createEnvironmentI(cmd *cobra.Command, args []string) {
 	template := environmentTemplate()
 	var err error
@@ -433,6 +917,82 @@ func createRequestVariableI(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 }
+func createEnvVariableI(cmd *cobra.Command, args []string) {
+	template := envVariableTemplate()
+	var err error
+	data, _ := yaml.Marshal(template)
+	data, err = updateData(data)
+	if err != nil {
+		log.Errorf("Failed to create variable: %+v\n", err)
+		os.Exit(1)
+	}
+	resource := Variable{}
+	if err := yaml.Unmarshal([]byte(data), &resource); err != nil {
+		log.Errorf("Failed to create variable: %+v\n", err)
+		os.Exit(1)
+	}
+	if err := resource.Save(); err != nil {
+		log.Errorf("Failed to create variable: %+v\n", err)
+		os.Exit(1)
+	}
+}
+func createDotenvVariableI(cmd *cobra.Command, args []string) {
+	template := dotenvVariableTemplate()
+	var err error
+	data, _ := yaml.Marshal(template)
+	data, err = updateData(data)
+	if err != nil {
+		log.Errorf("Failed to create variable: %+v\n", err)
+		os.Exit(1)
+	}
+	resource := Variable{}
+	if err := yaml.Unmarshal([]byte(data), &resource); err != nil {
+		log.Errorf("Failed to create variable: %+v\n", err)
+		os.Exit(1)
+	}
+	if err := resource.Save(); err != nil {
+		log.Errorf("Failed to create variable: %+v\n", err)
+		os.Exit(1)
+	}
+}
+func createPromptVariableI(cmd *cobra.Command, args []string) {
+	template := promptVariableTemplate()
+	var err error
+	data, _ := yaml.Marshal(template)
+	data, err = updateData(data)
+	if err != nil {
+		log.Errorf("Failed to create variable: %+v\n", err)
+		os.Exit(1)
+	}
+	resource := Variable{}
+	if err := yaml.Unmarshal([]byte(data), &resource); err != nil {
+		log.Errorf("Failed to create variable: %+v\n", err)
+		os.Exit(1)
+	}
+	if err := resource.Save(); err != nil {
+		log.Errorf("Failed to create variable: %+v\n", err)
+		os.Exit(1)
+	}
+}
+func createOAuth2VariableI(cmd *cobra.Command, args []string) {
+	template := oauth2VariableTemplate()
+	var err error
+	data, _ := yaml.Marshal(template)
+	data, err = updateData(data)
+	if err != nil {
+		log.Errorf("Failed to create variable: %+v\n", err)
+		os.Exit(1)
+	}
+	resource := Variable{}
+	if err := yaml.Unmarshal([]byte(data), &resource); err != nil {
+		log.Errorf("Failed to create variable: %+v\n", err)
+		os.Exit(1)
+	}
+	if err := resource.Save(); err != nil {
+		log.Errorf("Failed to create variable: %+v\n", err)
+		os.Exit(1)
+	}
+}
 func flagsAreSet(cmd *cobra.Command, flagNames ...string) bool {
 	if len(flagNames) == 0 {
 		return true
@@ -22,6 +22,14 @@ var deleteRequestCmd = &cobra.Command{
 `,
 	Run: deleteRequest,
 }
+var deleteSuiteCmd = &cobra.Command{
+	Use:     "suite [SUITE_NAME ...]",
+	Aliases: []string{"suites", "s"},
+	Short:   "Delete suite resources",
+	Long: `Delete suite resources.
+`,
+	Run: deleteSuite,
+}
 var deleteEnvironmentCmd = &cobra.Command{
 	Use:     "environment [ENVIRONMENT_NAME ...]",
 	Aliases: []string{"environments", "env", "envs", "e"},
@@ -42,6 +50,7 @@ var deleteVariableCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(deleteCmd)
 	deleteCmd.AddCommand(deleteRequestCmd)
+	deleteCmd.AddCommand(deleteSuiteCmd)
 	deleteCmd.AddCommand(deleteEnvironmentCmd)
 	deleteCmd.AddCommand(deleteVariableCmd)
 }
@@ -60,6 +69,19 @@ func deleteRequest(cmd *cobra.Command, args []string) {
 		}
 	}
 }
+func deleteSuite(cmd *cobra.Command, args []string) {
+	for _, suite := range args {
+		mSuite, err := models.GetSuiteByName(suite)
+		if err != nil {
+			log.Errorf("Failed to delete %s: %+v\n", suite, err)
+			os.Exit(1)
+		}
+		if err := mSuite.Delete(); err != nil {
+			log.Errorf("Failed to delete %s: %+v\n", suite, err)
+			os.Exit(1)
+		}
+	}
+}
 func deleteEnvironment(cmd *cobra.Command, args []string) {
 	for _, environment := range args {
 		mEnv, err := models.GetEnvironmentByName(environment)
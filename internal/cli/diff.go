@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/mcastorina/poster/internal/models"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	diffIgnoreKey        = "diff.ignore"
+	diffIgnoreHeadersKey = "diff.ignore-headers"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff LEFT RIGHT",
+	Short: "Compare two responses",
+	Long: `Compare two responses.
+
+Each response is either the ID of a response in the history, or a request to
+run in the form NAME or NAME@ENVIRONMENT. For example:
+
+    poster diff items@staging items@production
+    poster diff 12 items
+
+The status, headers and body of the responses are compared. JSON bodies are
+compared structurally and each difference is printed with its path. Paths
+that change on every run, such as $.timestamp or $.items[*].id, can be
+ignored with --ignore or the diff.ignore configuration key. Headers are
+ignored with --ignore-header or the diff.ignore-headers configuration key,
+which defaults to Date.
+
+poster exits with a non-zero status if the responses differ.
+`,
+	Run:  diff,
+	Args: diffArgs,
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	viper.SetDefault(diffIgnoreKey, []string{})
+	viper.SetDefault(diffIgnoreHeadersKey, []string{"Date"})
+
+	// diff flags
+	diffCmd.Flags().StringArray("ignore", []string{}, "Ignore a JSON path in the body")
+	diffCmd.Flags().StringArray("ignore-header", []string{}, "Ignore a header")
+}
+
+// run functions
+func diff(cmd *cobra.Command, args []string) {
+	verboseFlag, _ := cmd.Flags().GetBool("verbose")
+	responses := []models.Response{}
+	for _, arg := range args {
+		response, err := getDiffResponse(arg)
+		if err != nil {
+			log.Errorf("Could not get response for %s: %+v\n", arg, err)
+			os.Exit(1)
+		}
+		if verboseFlag {
+			fmt.Fprintf(os.Stderr, "< %s: %s %s\n", arg, response.Proto, response.Status)
+		}
+		responses = append(responses, response)
+	}
+
+	diffs := models.DiffResponses(responses[0], responses[1], getDiffOptions(cmd))
+	if len(diffs) > 0 {
+		fmt.Print(models.DiffReport(args[0], args[1], diffs))
+		os.Exit(1)
+	}
+}
+
+// argument functions
+func diffArgs(cmd *cobra.Command, args []string) error {
+	if len(args) < 2 {
+		return errorMissingArgs("LEFT RIGHT")
+	}
+	if len(args) > 2 {
+		return errorTooManyArgs
+	}
+	return nil
+}
+
+// helper functions
+
+// getDiffResponse loads a response from the history or runs a request
+func getDiffResponse(arg string) (models.Response, error) {
+	if id, err := strconv.ParseInt(arg, 10, 64); err == nil {
+		return models.GetResponseByID(id)
+	}
+
+	nameEnv := strings.SplitN(arg, "@", 2)
+	request, err := models.GetRequestByName(nameEnv[0])
+	if err != nil {
+		return models.Response{}, err
+	}
+	env := request.Environment
+	if len(nameEnv) == 2 {
+		if env, err = models.GetEnvironmentByName(nameEnv[1]); err != nil {
+			return models.Response{}, err
+		}
+	}
+	resp, err := request.RunEnv(env)
+	if err != nil {
+		if _, ok := err.(*models.AssertionError); !ok {
+			return models.Response{}, err
+		}
+	}
+	return models.ReadResponse(resp)
+}
+
+// getDiffOptions combines the ignore flags with the configured defaults
+func getDiffOptions(cmd *cobra.Command) models.DiffOptions {
+	ignorePaths, _ := cmd.Flags().GetStringArray("ignore")
+	ignoreHeaders, _ := cmd.Flags().GetStringArray("ignore-header")
+	return models.DiffOptions{
+		IgnorePaths:   append(viper.GetStringSlice(diffIgnoreKey), ignorePaths...),
+		IgnoreHeaders: append(viper.GetStringSlice(diffIgnoreHeadersKey), ignoreHeaders...),
+	}
+}
@@ -0,0 +1,189 @@
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/mcastorina/poster/internal/models"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+var dumpCmd = &cobra.Command{
+	Use:   "dump",
+	Short: "Print all resources as YAML",
+	Long: `Print all resources as YAML.
+
+The output is read by poster apply, so resources can be kept in version
+control. With --directory, the resources are written to environments.yaml,
+requests.yaml, variables.yaml and suites.yaml in the directory instead.
+
+Variables with the same definition in several environments are written once.
+Generated values are not written, as they are generated again after apply.
+Secret values are written encrypted, and can only be applied with the same key.
+`,
+	Run:  dump,
+	Args: dumpArgs,
+}
+
+func init() {
+	rootCmd.AddCommand(dumpCmd)
+
+	// dump flags
+	dumpCmd.Flags().StringP("directory", "d", "", "Write one file per resource type to this directory")
+}
+
+// run functions
+func dump(cmd *cobra.Command, args []string) {
+	resources := dumpResources()
+
+	directory, _ := cmd.Flags().GetString("directory")
+	if directory == "" {
+		data, err := yaml.Marshal(resources)
+		if err != nil {
+			log.Errorf("Could not dump resources: %+v\n", err)
+			os.Exit(1)
+		}
+		fmt.Print(string(data))
+		return
+	}
+
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		log.Errorf("Could not create %s: %+v\n", directory, err)
+		os.Exit(1)
+	}
+	files := map[string]resourceFile{
+		"environments.yaml": {Environments: resources.Environments},
+		"requests.yaml":     {Requests: resources.Requests},
+		"variables.yaml":    {Variables: resources.Variables},
+		"suites.yaml":       {Suites: resources.Suites},
+	}
+	for name, file := range files {
+		path := filepath.Join(directory, name)
+		data, err := yaml.Marshal(file)
+		if err == nil {
+			err = ioutil.WriteFile(path, data, 0644)
+		}
+		if err != nil {
+			log.Errorf("Could not write %s: %+v\n", path, err)
+			os.Exit(1)
+		}
+	}
+}
+
+// argument functions
+func dumpArgs(cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return errorTooManyArgs
+	}
+	return nil
+}
+
+// helper functions
+
+// dumpResources converts every resource into the format read by apply
+func dumpResources() resourceFile {
+	resources := resourceFile{
+		Environments: []Environment{},
+		Requests:     []Request{},
+		Variables:    []Variable{},
+		Suites:       []Suite{},
+	}
+	for _, environment := range models.GetAllEnvironments() {
+		if environment.Name == globalEnvironment {
+			continue
+		}
+		resources.Environments = append(resources.Environments, Environment{
+			Name:        environment.Name,
+			Parent:      environment.Parent,
+			Client:      environment.Client,
+			Certificate: environment.Certificate,
+			Auth:        environment.Auth,
+		})
+	}
+	for _, request := range models.GetAllRequests() {
+		headers := map[string]string{}
+		for _, header := range request.Headers {
+			headers[header.Key] = header.Value
+		}
+		assertions := []string{}
+		for _, assertion := range request.Assertions {
+			assertions = append(assertions, assertion.String())
+		}
+		resources.Requests = append(resources.Requests, Request{
+			Name:        request.Name,
+			Method:      request.Method,
+			URL:         request.URL,
+			Environment: request.Environment.Name,
+			Body:        request.Body,
+			Headers:     headers,
+			Assertions:  assertions,
+			Client:      request.Client,
+			Auth:        request.Auth,
+		})
+	}
+	for _, suite := range models.GetAllSuites() {
+		resources.Suites = append(resources.Suites, Suite{
+			Name:        suite.Name,
+			Environment: suite.Environment.Name,
+			Requests:    suite.Requests,
+		})
+	}
+
+	// Group variables that only differ by environment
+	groups := map[string]int{}
+	for _, variable := range models.GetAllVariables() {
+		v := Variable{
+			Name:         variable.Name,
+			Type:         variable.Type,
+			Environments: []string{variable.Environment.Name},
+			Secret:       variable.Secret,
+		}
+		if variable.Type == models.ConstType {
+			v.Value = variable.Value
+		}
+		if g := variable.Generator; g != nil && variable.Type != models.ConstType {
+			v.Generator = &VariableGenerator{
+				RequestName:        g.RequestName,
+				RequestPath:        g.RequestPath,
+				RequestEnvironment: g.RequestEnvironment,
+				Script:             g.Script,
+				Key:                g.Key,
+				File:               g.File,
+				Prompt:             g.Prompt,
+				Default:            g.Default,
+				Choices:            g.Choices,
+				Timeout:            g.Timeout,
+			}
+			if g.OAuth2 != nil {
+				// The token state is not part of the configuration
+				settings := g.OAuth2.Config()
+				v.Generator.OAuth2 = &settings
+			}
+			if g.RequestEnvironment == variable.Environment.Name {
+				v.Generator.RequestEnvironment = "parent"
+			}
+		}
+		key := fmt.Sprintf("%s\n%s\n%s", v.Name, v.Type, v.Value)
+		if v.Generator != nil {
+			key = fmt.Sprintf("%s\n%+v", key, *v.Generator)
+			if v.Generator.OAuth2 != nil {
+				key = fmt.Sprintf("%s\n%+v", key, *v.Generator.OAuth2)
+			}
+		}
+		if i, ok := groups[key]; ok {
+			resources.Variables[i].Environments = append(resources.Variables[i].Environments, variable.Environment.Name)
+			continue
+		}
+		groups[key] = len(resources.Variables)
+		resources.Variables = append(resources.Variables, v)
+	}
+	for _, variable := range resources.Variables {
+		sort.Strings(variable.Environments)
+	}
+	sortResources(&resources)
+	return resources
+}
@@ -26,6 +26,15 @@ var editRequestCmd = &cobra.Command{
 	Run:  editRequest,
 	Args: cobra.ExactArgs(1),
 }
+var editSuiteCmd = &cobra.Command{
+	Use:     "suite SUITE_NAME",
+	Aliases: []string{"s"},
+	Short:   "Modify a suite resource",
+	Long: `Modify a suite resource.
+`,
+	Run:  editSuite,
+	Args: cobra.ExactArgs(1),
+}
 var editEnvironmentCmd = &cobra.Command{
 	Use:     "environment ENVIRONMENT_NAME",
 	Aliases: []string{"env", "e"},
@@ -48,6 +57,7 @@ var editVariableCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(editCmd)
 	editCmd.AddCommand(editRequestCmd)
+	editCmd.AddCommand(editSuiteCmd)
 	editCmd.AddCommand(editEnvironmentCmd)
 	editCmd.AddCommand(editVariableCmd)
 }
@@ -81,6 +91,34 @@ func editRequest(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 }
+func editSuite(cmd *cobra.Command, args []string) {
+	suite, err := models.GetSuiteByName(args[0])
+	if err != nil {
+		log.Errorf("Failed to get suite: %+v\n", err)
+		os.Exit(1)
+	}
+
+	data, _ := yaml.Marshal(suite)
+	data, err = updateData(data)
+	if err != nil {
+		log.Errorf("Failed to update suite: %+v\n", err)
+		os.Exit(1)
+	}
+
+	newSuite := models.Suite{}
+	err = yaml.Unmarshal([]byte(data), &newSuite)
+	if err != nil {
+		log.Errorf("Failed to update suite: %+v\n", err)
+		os.Exit(1)
+	}
+
+	suite.Delete()
+	if err := newSuite.Save(); err != nil {
+		suite.Save() // Rollback changes
+		log.Errorf("Failed to update suite: %+v\n", err)
+		os.Exit(1)
+	}
+}
 func editEnvironment(cmd *cobra.Command, args []string) {
 	environment, err := models.GetEnvironmentByName(args[0])
 	if err != nil {
@@ -102,6 +140,14 @@ func editEnvironment(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	if newEnvironment.Name == environment.Name {
+		// Update the parent, client options, certificate and auth in place
+		if err := newEnvironment.Save(); err != nil {
+			log.Errorf("Failed to update environment: %+v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
 	// This can fail due to foreign key constraint
 	if err := environment.Delete(); err != nil {
 		log.Errorf("Failed to update environment: %+v\n", err)
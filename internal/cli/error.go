@@ -13,6 +13,23 @@ var (
 	errorFileUnchanged              = errors.New("no changes")
 	errorNoEditorFound              = errors.New("no editor found")
 	errorInvalidVariableFormat      = errors.New("variable should be in the format \"key=value\"")
+	errorInvalidExportFormat        = errors.New("export format not recognized")
+	errorUnterminatedQuote          = errors.New("unterminated quote")
+	errorInvalidImportFile          = errors.New("file format not recognized")
+	errorInvalidAssertionFormat     = errors.New("assertion not recognized (see poster run --help)")
+	errorInvalidResponseID          = errors.New("response ID should be a number")
+	errorTooManyArgs                = errors.New("too many args")
+	errorBaselineNotRequest         = errors.New("baselines can only be compared for requests")
+	errorInvalidWorkspaceName       = errors.New("workspace name should only contain letters, digits, '_' and '-'")
+	errorWorkspaceExists            = errors.New("workspace already exists")
+	errorWorkspaceNotFound          = errors.New("workspace not found")
+	errorInvalidGraphFormat         = errors.New("graph format should be text or dot")
+	errorInvalidOutputFormat        = errors.New("output format not recognized")
+	errorMissingEnvironments        = errors.New("expected at least one environment")
+	errorInvalidCustomColumns       = errors.New("custom columns should be in the format \"NAME:EXPR,...\"")
+	errorNoFollowRedirects          = errors.New("--no-follow cannot be used with --max-redirects")
+	errorSessionNotFound            = errors.New("session not found")
+	errorNegativeServer             = errors.New("server index cannot be negative")
 
 	missingFlagBase  = "expected flag missing: %s"
 	missingFlagsBase = "expected flags missing: %s"
@@ -0,0 +1,221 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/mcastorina/poster/internal/models"
+	"github.com/spf13/cobra"
+)
+
+const (
+	curlFormat           = "curl"
+	httpieFormat         = "httpie"
+	wgetFormat           = "wget"
+	goFormat             = "go"
+	pythonRequestsFormat = "python-requests"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export RESOURCE",
+	Short: "Export a resource in a specific format",
+	Long: `Export a resource in a specific format.
+`,
+}
+var exportRequestCmd = &cobra.Command{
+	Use:     "request REQUEST_NAME",
+	Aliases: []string{"req", "r"},
+	Short:   "Export a request resource",
+	Long: `Export a request as a command or code snippet. Valid formats:
+
+    curl                curl command line (default)
+    httpie              HTTPie command line
+    wget                wget command line
+    go                  Go program using net/http
+    python-requests     Python script using the requests library
+
+Variables are generated and replaced with their current value in the default
+environment of the request, unless overridden with the --env flag. Use
+--unresolved to leave the :variables in the output as they are.
+
+Basic and bearer auth are exported with the request. Digest and aws-sigv4
+auth sign every request, so they are left out with a warning.
+`,
+	Run:  exportRequest,
+	Args: exportRequestArgs,
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.AddCommand(exportRequestCmd)
+
+	// export request flags
+	exportRequestCmd.Flags().StringP("env", "e", "", "Resolve variables in the specified environment")
+	exportRequestCmd.Flags().StringP("format", "f", curlFormat, "Output format")
+	exportRequestCmd.Flags().BoolP("unresolved", "u", false, "Do not replace variables with their values")
+}
+
+// run functions
+func exportRequest(cmd *cobra.Command, args []string) {
+	request, err := models.GetRequestByName(args[0])
+	if err != nil {
+		log.Errorf("Could not export %s: %+v\n", args[0], err)
+		os.Exit(1)
+	}
+
+	if unresolved, _ := cmd.Flags().GetBool("unresolved"); !unresolved {
+		env := request.Environment
+		if e, _ := cmd.Flags().GetString("env"); e != "" {
+			env, err = models.GetEnvironmentByName(e)
+			if err != nil {
+				log.Errorf("%+v\n", err)
+				os.Exit(1)
+			}
+		}
+		request, err = request.Resolve(env)
+		if err != nil {
+			log.Errorf("Could not export %s: %+v\n", args[0], err)
+			os.Exit(1)
+		}
+	}
+
+	format, _ := cmd.Flags().GetString("format")
+	output, err := exportRequestFormat(request, format)
+	if err != nil {
+		log.Errorf("Could not export %s: %+v\n", args[0], err)
+		os.Exit(1)
+	}
+	fmt.Print(output)
+}
+
+// argument functions
+func exportRequestArgs(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return errorMissingArg("REQUEST_NAME")
+	}
+	format, _ := cmd.Flags().GetString("format")
+	if _, err := exportRequestFormat(models.Request{}, format); err != nil {
+		return err
+	}
+	return nil
+}
+
+// helper functions
+func exportRequestFormat(r models.Request, format string) (string, error) {
+	switch r.Auth.Type {
+	case models.AuthBearer:
+		headers := append([]models.Header{}, r.Headers...)
+		r.Headers = append(headers, models.Header{Key: "Authorization", Value: "Bearer " + r.Auth.Token})
+	case models.AuthDigest, models.AuthAWSSigV4:
+		// Both sign every request, which a static command cannot do
+		log.Warnf("The %s auth of %s cannot be exported, it is left out\n", r.Auth.Type, r.Name)
+	}
+	switch format {
+	case curlFormat:
+		return exportCurl(r), nil
+	case httpieFormat:
+		return exportHttpie(r), nil
+	case wgetFormat:
+		return exportWget(r), nil
+	case goFormat:
+		return exportGo(r), nil
+	case pythonRequestsFormat:
+		return exportPythonRequests(r), nil
+	}
+	return "", errorInvalidExportFormat
+}
+func exportCurl(r models.Request) string {
+	parts := []string{"curl", "-X", r.Method, shellQuote(r.URL)}
+	if r.Auth.Type == models.AuthBasic {
+		parts = append(parts, "-u", shellQuote(r.Auth.Username+":"+r.Auth.Password))
+	}
+	for _, header := range r.Headers {
+		parts = append(parts, "-H", shellQuote(header.String()))
+	}
+	if r.Body != "" {
+		parts = append(parts, "--data-raw", shellQuote(r.Body))
+	}
+	return strings.Join(parts, " ") + "\n"
+}
+func exportHttpie(r models.Request) string {
+	parts := []string{"http"}
+	if r.Body != "" {
+		parts = append(parts, "--raw", shellQuote(r.Body))
+	}
+	if r.Auth.Type == models.AuthBasic {
+		parts = append(parts, "-a", shellQuote(r.Auth.Username+":"+r.Auth.Password))
+	}
+	parts = append(parts, r.Method, shellQuote(r.URL))
+	for _, header := range r.Headers {
+		parts = append(parts, shellQuote(header.Key+":"+header.Value))
+	}
+	return strings.Join(parts, " ") + "\n"
+}
+func exportWget(r models.Request) string {
+	parts := []string{"wget", "--quiet", "--output-document=-", "--method=" + r.Method}
+	if r.Auth.Type == models.AuthBasic {
+		// wget waits for a challenge before sending credentials by default
+		parts = append(parts, "--auth-no-challenge", shellQuote("--user="+r.Auth.Username),
+			shellQuote("--password="+r.Auth.Password))
+	}
+	for _, header := range r.Headers {
+		parts = append(parts, shellQuote("--header="+header.String()))
+	}
+	if r.Body != "" {
+		parts = append(parts, shellQuote("--body-data="+r.Body))
+	}
+	parts = append(parts, shellQuote(r.URL))
+	return strings.Join(parts, " ") + "\n"
+}
+func exportGo(r models.Request) string {
+	var b strings.Builder
+	b.WriteString("package main\n\n")
+	b.WriteString("import (\n\t\"fmt\"\n\t\"io/ioutil\"\n\t\"net/http\"\n\t\"strings\"\n)\n\n")
+	b.WriteString("func main() {\n")
+	fmt.Fprintf(&b, "\tbody := strings.NewReader(%s)\n", strconv.Quote(r.Body))
+	fmt.Fprintf(&b, "\treq, err := http.NewRequest(%s, %s, body)\n",
+		strconv.Quote(r.Method), strconv.Quote(r.URL))
+	b.WriteString("\tif err != nil {\n\t\tpanic(err)\n\t}\n")
+	for _, header := range r.Headers {
+		fmt.Fprintf(&b, "\treq.Header.Add(%s, %s)\n",
+			strconv.Quote(header.Key), strconv.Quote(header.Value))
+	}
+	if r.Auth.Type == models.AuthBasic {
+		fmt.Fprintf(&b, "\treq.SetBasicAuth(%s, %s)\n",
+			strconv.Quote(r.Auth.Username), strconv.Quote(r.Auth.Password))
+	}
+	b.WriteString("\n\tresp, err := http.DefaultClient.Do(req)\n")
+	b.WriteString("\tif err != nil {\n\t\tpanic(err)\n\t}\n")
+	b.WriteString("\tdefer resp.Body.Close()\n\n")
+	b.WriteString("\tout, err := ioutil.ReadAll(resp.Body)\n")
+	b.WriteString("\tif err != nil {\n\t\tpanic(err)\n\t}\n")
+	b.WriteString("\tfmt.Println(resp.Status)\n")
+	b.WriteString("\tfmt.Printf(\"%s\", out)\n")
+	b.WriteString("}\n")
+	return b.String()
+}
+func exportPythonRequests(r models.Request) string {
+	var b strings.Builder
+	b.WriteString("import requests\n\n")
+	b.WriteString("headers = {\n")
+	for _, header := range r.Headers {
+		fmt.Fprintf(&b, "    %s: %s,\n", strconv.Quote(header.Key), strconv.Quote(header.Value))
+	}
+	b.WriteString("}\n")
+	fmt.Fprintf(&b, "data = %s\n", strconv.Quote(r.Body))
+	auth := ""
+	if r.Auth.Type == models.AuthBasic {
+		fmt.Fprintf(&b, "auth = (%s, %s)\n", strconv.Quote(r.Auth.Username), strconv.Quote(r.Auth.Password))
+		auth = ", auth=auth"
+	}
+	fmt.Fprintf(&b, "\nresponse = requests.request(%s, %s, headers=headers, data=data%s)\n",
+		strconv.Quote(r.Method), strconv.Quote(r.URL), auth)
+	b.WriteString("print(response.status_code, response.reason)\n")
+	b.WriteString("print(response.text)\n")
+	return b.String()
+}
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
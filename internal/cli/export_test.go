@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/mcastorina/poster/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var testExportRequest = models.Request{
+	Name:   "export",
+	Method: "POST",
+	URL:    "http://localhost:8080/items?q=1",
+	Body:   `{"name": "it's"}`,
+	Headers: []models.Header{
+		{
+			Key:   "Content-Type",
+			Value: "application/json",
+		},
+	},
+}
+
+func TestExportCurl(t *testing.T) {
+	expected := `curl -X POST 'http://localhost:8080/items?q=1' -H 'Content-Type: application/json' --data-raw '{"name": "it'\''s"}'` + "\n"
+	actual, err := exportRequestFormat(testExportRequest, curlFormat)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, actual)
+}
+func TestExportHttpie(t *testing.T) {
+	expected := `http --raw '{"name": "it'\''s"}' POST 'http://localhost:8080/items?q=1' 'Content-Type:application/json'` + "\n"
+	actual, err := exportRequestFormat(testExportRequest, httpieFormat)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, actual)
+}
+func TestExportWget(t *testing.T) {
+	expected := `wget --quiet --output-document=- --method=POST '--header=Content-Type: application/json' '--body-data={"name": "it'\''s"}' 'http://localhost:8080/items?q=1'` + "\n"
+	actual, err := exportRequestFormat(testExportRequest, wgetFormat)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, actual)
+}
+func TestExportGo(t *testing.T) {
+	actual, err := exportRequestFormat(testExportRequest, goFormat)
+	assert.Nil(t, err)
+	assert.Contains(t, actual, `http.NewRequest("POST", "http://localhost:8080/items?q=1", body)`)
+	assert.Contains(t, actual, `req.Header.Add("Content-Type", "application/json")`)
+	assert.Contains(t, actual, `strings.NewReader("{\"name\": \"it's\"}")`)
+}
+func TestExportPythonRequests(t *testing.T) {
+	actual, err := exportRequestFormat(testExportRequest, pythonRequestsFormat)
+	assert.Nil(t, err)
+	assert.Contains(t, actual, `"Content-Type": "application/json",`)
+	assert.Contains(t, actual, `requests.request("POST", "http://localhost:8080/items?q=1", headers=headers, data=data)`)
+}
+func TestExportInvalidFormat(t *testing.T) {
+	_, err := exportRequestFormat(testExportRequest, "postman")
+	assert.Equal(t, errorInvalidExportFormat, err)
+}
+func TestExportAuth(t *testing.T) {
+	basic := testExportRequest
+	basic.Auth = models.Auth{Type: models.AuthBasic, Username: "alice", Password: "s3cret"}
+	expected := map[string]string{
+		curlFormat:           `-u 'alice:s3cret'`,
+		httpieFormat:         `-a 'alice:s3cret'`,
+		wgetFormat:           `--auth-no-challenge '--user=alice' '--password=s3cret'`,
+		goFormat:             `req.SetBasicAuth("alice", "s3cret")`,
+		pythonRequestsFormat: `auth = ("alice", "s3cret")`,
+	}
+	for format, part := range expected {
+		actual, err := exportRequestFormat(basic, format)
+		assert.Nil(t, err)
+		assert.Contains(t, actual, part, format)
+	}
+
+	bearer := testExportRequest
+	bearer.Auth = models.Auth{Type: models.AuthBearer, Token: "abc"}
+	actual, err := exportRequestFormat(bearer, curlFormat)
+	assert.Nil(t, err)
+	assert.Contains(t, actual, `-H 'Authorization: Bearer abc'`)
+	assert.Equal(t, 1, len(testExportRequest.Headers))
+
+	// Digest and AWS Signature V4 are left out with a warning
+	InitLogger()
+	digest := testExportRequest
+	digest.Auth = models.Auth{Type: models.AuthDigest, Username: "alice", Password: "s3cret"}
+	actual, err = exportRequestFormat(digest, curlFormat)
+	assert.Nil(t, err)
+	assert.NotContains(t, actual, "alice")
+}
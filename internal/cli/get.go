@@ -3,6 +3,7 @@ package cli
 import (
 	"fmt"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"text/tabwriter"
@@ -20,6 +21,20 @@ var getCmd = &cobra.Command{
 	Aliases: []string{"print", "g", "p"},
 	Short:   "Print resources",
 	Long: `Print resources.
+
+The output format is set with --output:
+
+    wide                           Print more columns
+    json, yaml                     Print every field of the resources
+    name                           Print only the names
+    jsonpath=EXPR                  Print the result of a JSONPath expression
+                                   applied to the list of resources, e.g.
+                                   jsonpath='$[*].url'
+    custom-columns=SPEC            Print a table of NAME:EXPR columns, with
+                                   EXPR applied to each resource, e.g.
+                                   custom-columns=NAME:.name,URL:.url
+
+Resources are sorted by name and, for variables, by environment.
 `,
 }
 var getRequestCmd = &cobra.Command{
@@ -28,15 +43,30 @@ var getRequestCmd = &cobra.Command{
 	Short:   "Print request resources",
 	Long: `Print request resources.
 `,
-	Run: getRequest,
+	Run:  getRequest,
+	Args: getArgs,
+}
+var getSuiteCmd = &cobra.Command{
+	Use:     "suite [NAME ...]",
+	Aliases: []string{"suites", "s"},
+	Short:   "Print suite resources",
+	Long: `Print suite resources.
+`,
+	Run:  getSuite,
+	Args: getArgs,
 }
 var getEnvironmentCmd = &cobra.Command{
 	Use:     "environment",
 	Aliases: []string{"environments", "env", "envs", "e"},
 	Short:   "Print environment resources",
 	Long: `Print environment resources.
+
+The wide output format shows the parent of each environment, the variables
+it inherits from its ancestors, and the variables of its ancestors that it
+overrides, as name(environment).
 `,
-	Run: getEnvironment,
+	Run:  getEnvironment,
+	Args: getArgs,
 }
 var getVariableCmd = &cobra.Command{
 	Use:     "variable [NAME ...]",
@@ -44,18 +74,21 @@ var getVariableCmd = &cobra.Command{
 	Short:   "Print variable resources",
 	Long: `Print variable resources.
 `,
-	Run: getVariable,
+	Run:  getVariable,
+	Args: getArgs,
 }
 var tabWriter = tabwriter.NewWriter(os.Stdout, 0, 0, 6, ' ', 0)
 
 func init() {
 	rootCmd.AddCommand(getCmd)
 	getCmd.AddCommand(getRequestCmd)
+	getCmd.AddCommand(getSuiteCmd)
 	getCmd.AddCommand(getEnvironmentCmd)
 	getCmd.AddCommand(getVariableCmd)
 
 	// get flags
-	getCmd.PersistentFlags().StringP("output", "o", "", "Output format")
+	getCmd.PersistentFlags().StringP("output", "o", "",
+		"Output format (wide, json, yaml, name, jsonpath=EXPR or custom-columns=SPEC)")
 
 	// getRequest flags
 	getRequestCmd.Flags().StringP("method", "m", "", "Filter by method")
@@ -64,6 +97,9 @@ func init() {
 	getRequestCmd.Flags().StringArray("with-header", []string{}, "Filter by request containing header key or value")
 	getRequestCmd.Flags().StringArray("with-body", []string{}, "Filter by request containing body")
 
+	// getSuite flags
+	getSuiteCmd.Flags().StringP("environment", "e", "", "Filter by environment")
+
 	// getEnvironment flags
 	getEnvironmentCmd.Flags().StringArray("with-variable", []string{}, "Filter by environment containing variable")
 
@@ -82,28 +118,81 @@ func getRequest(cmd *cobra.Command, args []string) {
 	withBodies, _ := cmd.Flags().GetStringArray("with-body")
 
 	requests := getRequestsFromArguments(envFlag, methodFlag, withVariables, withHeaders, withBodies, args)
+	sort.Slice(requests, func(i, j int) bool { return requests[i].Name < requests[j].Name })
 	outputFormat, _ := cmd.Flags().GetString("output")
+	if isStructuredFormat(outputFormat) {
+		outputs := []requestOutput{}
+		names := []string{}
+		for _, request := range requests {
+			outputs = append(outputs, newRequestOutput(request))
+			names = append(names, request.Name)
+		}
+		exitOnOutputError(printStructured(outputFormat, outputs, names))
+		return
+	}
 	header := []interface{}{"NAME", "METHOD", "URL", "DEFAULT ENVIRONMENT"}
 	if outputFormat == wideFormat {
-		header = append(header, "HEADERS", "BODY")
+		header = append(header, "HEADERS", "BODY", "ASSERTIONS")
 	}
 	printTableRow(header...)
 	for _, request := range requests {
 		row := []interface{}{request.Name, request.Method, request.URL, request.Environment.Name}
 		if outputFormat == wideFormat {
-			row = append(row, request.Headers, request.Body)
+			row = append(row, request.Headers, request.Body, request.Assertions)
 		}
 		printTableRow(row...)
 	}
 	tabWriter.Flush()
 }
+func getSuite(cmd *cobra.Command, args []string) {
+	envFlag, _ := cmd.Flags().GetString("environment")
+	suites := getSuitesFromArguments(envFlag, args)
+	sort.Slice(suites, func(i, j int) bool { return suites[i].Name < suites[j].Name })
+	if outputFormat, _ := cmd.Flags().GetString("output"); isStructuredFormat(outputFormat) {
+		outputs := []suiteOutput{}
+		names := []string{}
+		for _, suite := range suites {
+			outputs = append(outputs, newSuiteOutput(suite))
+			names = append(names, suite.Name)
+		}
+		exitOnOutputError(printStructured(outputFormat, outputs, names))
+		return
+	}
+
+	printTableRow("NAME", "REQUESTS", "DEFAULT ENVIRONMENT")
+	for _, suite := range suites {
+		printTableRow(suite.Name, suite.Requests, suite.Environment.Name)
+	}
+	tabWriter.Flush()
+}
 func getEnvironment(cmd *cobra.Command, args []string) {
 	withVariables, _ := cmd.Flags().GetStringArray("with-variable")
 	environments := getEnvironmentsFromArguments(withVariables, args)
+	sort.Slice(environments, func(i, j int) bool { return environments[i].Name < environments[j].Name })
+	outputFormat, _ := cmd.Flags().GetString("output")
+	if isStructuredFormat(outputFormat) {
+		outputs := []environmentOutput{}
+		names := []string{}
+		for _, environment := range environments {
+			outputs = append(outputs, newEnvironmentOutput(environment))
+			names = append(names, environment.Name)
+		}
+		exitOnOutputError(printStructured(outputFormat, outputs, names))
+		return
+	}
 
-	printTableRow("NAME", "VARIABLES")
+	header := []interface{}{"NAME", "VARIABLES"}
+	if outputFormat == wideFormat {
+		header = append(header, "PARENT", "INHERITED", "OVERRIDDEN")
+	}
+	printTableRow(header...)
 	for _, env := range environments {
-		printTableRow(env.Name, env.GetVariableNames())
+		row := []interface{}{env.Name, env.GetVariableNames()}
+		if outputFormat == wideFormat {
+			inherited, overridden := env.Inheritance()
+			row = append(row, env.Parent, variableSources(inherited), variableSources(overridden))
+		}
+		printTableRow(row...)
 	}
 	tabWriter.Flush()
 }
@@ -112,7 +201,23 @@ func getVariable(cmd *cobra.Command, args []string) {
 	typeFlag, _ := cmd.Flags().GetString("type")
 
 	variables := getVariablesFromArguments(envFlag, typeFlag, args)
+	sort.Slice(variables, func(i, j int) bool {
+		if variables[i].Name != variables[j].Name {
+			return variables[i].Name < variables[j].Name
+		}
+		return variables[i].Environment.Name < variables[j].Environment.Name
+	})
 	outputFormat, _ := cmd.Flags().GetString("output")
+	if isStructuredFormat(outputFormat) {
+		outputs := []variableOutput{}
+		names := []string{}
+		for _, variable := range variables {
+			outputs = append(outputs, newVariableOutput(variable))
+			names = append(names, variable.Name)
+		}
+		exitOnOutputError(printStructured(outputFormat, outputs, names))
+		return
+	}
 	header := []interface{}{"NAME", "VALUE", "ENVIRONMENT", "TYPE"}
 	if outputFormat == wideFormat {
 		header = append(header, "GENERATOR", "TIMEOUT", "LAST GENERATED")
@@ -120,6 +225,9 @@ func getVariable(cmd *cobra.Command, args []string) {
 	printTableRow(header...)
 	for _, variable := range variables {
 		value := variable.Value
+		if variable.Secret && value != "" {
+			value = models.SecretMask
+		}
 		if len(value) > 50 {
 			value = value[:48] + ".."
 		}
@@ -135,10 +243,34 @@ func getVariable(cmd *cobra.Command, args []string) {
 				case models.RequestType:
 					generator = fmt.Sprintf("%s(%s): %s", varGen.RequestName,
 						varGen.RequestEnvironment, varGen.RequestPath)
+				case models.EnvType:
+					generator = "$" + varGen.Key
+				case models.DotenvType:
+					generator = fmt.Sprintf("%s: %s", varGen.File, varGen.Key)
+				case models.PromptType:
+					parts := []string{}
+					if varGen.Prompt != "" {
+						parts = append(parts, varGen.Prompt)
+					}
+					if len(varGen.Choices) > 0 {
+						parts = append(parts, fmt.Sprintf("(%s)", strings.Join(varGen.Choices, ", ")))
+					}
+					if varGen.Default != "" {
+						parts = append(parts, fmt.Sprintf("[%s]", varGen.Default))
+					}
+					generator = strings.Join(parts, " ")
+				case models.OAuth2Type:
+					if settings := varGen.OAuth2; settings != nil {
+						generator = fmt.Sprintf("%s(%s)", settings.TokenURL, settings.GrantType)
+					}
 				case models.ConstType:
 				}
-				timeout = strconv.FormatInt(varGen.Timeout, 10)
-				lastGenerated = varGen.LastGenerated.Format("01/02/06 15:04:05")
+				switch variable.Type {
+				case models.EnvType, models.DotenvType, models.PromptType:
+				default:
+					timeout = strconv.FormatInt(varGen.Timeout, 10)
+					lastGenerated = varGen.LastGenerated.Format("01/02/06 15:04:05")
+				}
 			}
 			row = append(row, generator, timeout, lastGenerated)
 		}
@@ -148,8 +280,25 @@ func getVariable(cmd *cobra.Command, args []string) {
 }
 
 // argument functions
+func getArgs(cmd *cobra.Command, args []string) error {
+	outputFormat, _ := cmd.Flags().GetString("output")
+	if strings.HasPrefix(outputFormat, customColumnsFormat) {
+		_, err := parseCustomColumns(strings.TrimPrefix(outputFormat, customColumnsFormat))
+		return err
+	}
+	if outputFormat != "" && outputFormat != wideFormat && !isStructuredFormat(outputFormat) {
+		return errorInvalidOutputFormat
+	}
+	return nil
+}
 
 // helper functions
+func exitOnOutputError(err error) {
+	if err != nil {
+		log.Errorf("Could not print resources: %+v\n", err)
+		os.Exit(1)
+	}
+}
 func printTableRow(cols ...interface{}) {
 	formatStr := ""
 	for i := 0; i < len(cols); i++ {
@@ -260,6 +409,22 @@ func getRequestsFromArguments(envFlag, methodFlag string, withVariables, withHea
 
 	return requests
 }
+func getSuitesFromArguments(envFlag string, args []string) []models.Suite {
+	if len(args) == 0 {
+		if envFlag != "" {
+			return models.GetSuitesByEnvironment(envFlag)
+		}
+		return models.GetAllSuites()
+	}
+	suites := []models.Suite{}
+	for _, arg := range args {
+		if suite, err := models.GetSuiteByName(arg); err == nil &&
+			(envFlag == "" || suite.Environment.Name == envFlag) {
+			suites = append(suites, suite)
+		}
+	}
+	return suites
+}
 func getEnvironmentsFromArguments(withVariables, args []string) []models.Environment {
 	environments := []models.Environment{}
 	if len(args) > 0 {
@@ -342,3 +507,12 @@ func getVariablesFromArguments(envFlag, typeFlag string, args []string) []models
 	}
 	return variables
 }
+
+// variableSources formats variables as name(environment)
+func variableSources(variables []models.Variable) []string {
+	sources := []string{}
+	for _, variable := range variables {
+		sources = append(sources, fmt.Sprintf("%s(%s)", variable.Name, variable.Environment.Name))
+	}
+	return sources
+}
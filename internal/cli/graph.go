@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mcastorina/poster/internal/models"
+	"github.com/spf13/cobra"
+)
+
+const (
+	textFormat = "text"
+	dotFormat  = "dot"
+)
+
+var graphCmd = &cobra.Command{
+	Use:   "graph REQUEST",
+	Short: "Print the dependencies of a request",
+	Long: `Print the dependencies of a request.
+
+Prints the variables a request uses in an environment and, for request
+variables, the requests that generate them along with their own variables.
+The graph is printed as a tree, or in the Graphviz DOT language with
+--output dot, e.g.
+
+    poster graph check-auth -e staging -o dot | dot -Tpng > graph.png
+
+Requests that depend on themselves through their variables cannot be run, and
+the chain of requests and variables is printed instead.
+`,
+	Run:  graph,
+	Args: graphArgs,
+}
+
+func init() {
+	rootCmd.AddCommand(graphCmd)
+
+	// graph flags
+	graphCmd.Flags().StringP("environment", "e", "", "Environment of the request (default is its default environment)")
+	graphCmd.Flags().StringP("output", "o", textFormat, "Output format (text or dot)")
+}
+
+// run functions
+func graph(cmd *cobra.Command, args []string) {
+	request, err := models.GetRequestByName(args[0])
+	if err != nil {
+		log.Errorf("Could not find request %s: %+v\n", args[0], err)
+		os.Exit(1)
+	}
+	env := request.Environment
+	if envFlag, _ := cmd.Flags().GetString("environment"); envFlag != "" {
+		env, err = models.GetEnvironmentByName(envFlag)
+		if err != nil {
+			log.Errorf("Could not find environment %s: %+v\n", envFlag, err)
+			os.Exit(1)
+		}
+	}
+
+	node, err := request.DependencyGraph(env)
+	if err != nil {
+		log.Errorf("Could not graph %s: %+v\n", args[0], err)
+		os.Exit(1)
+	}
+	outputFormat, _ := cmd.Flags().GetString("output")
+	if outputFormat == dotFormat {
+		fmt.Print(node.DOT())
+	} else {
+		fmt.Print(node.Text())
+	}
+}
+
+// argument functions
+func graphArgs(cmd *cobra.Command, args []string) error {
+	if len(args) < 1 {
+		return errorMissingArg("REQUEST")
+	}
+	if len(args) > 1 {
+		return errorTooManyArgs
+	}
+	outputFormat, _ := cmd.Flags().GetString("output")
+	if outputFormat != textFormat && outputFormat != dotFormat {
+		return errorInvalidGraphFormat
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mcastorina/poster/internal/models"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	historyMaxAgeKey     = "history.max-age"
+	historyMaxEntriesKey = "history.max-entries"
+	historyTimeFormat    = "2006-01-02 15:04:05"
+)
+
+var historyCmd = &cobra.Command{
+	Use:     "history [REQUEST]",
+	Aliases: []string{"hist"},
+	Short:   "Print the responses of previous runs",
+	Long: `Print the responses of previous runs.
+
+Every run records the request as it was sent, with its variables resolved, and
+the response that was received. Use 'history show ID' to print a recorded run.
+
+Old responses are removed after each run. By default, responses older than 30
+days and all but the latest 100 responses of each request are removed. These
+limits can be changed with the history.max-age and history.max-entries
+configuration keys, where 0 disables the limit. Baselines, marked with a * after
+their ID, are always kept.
+`,
+	Run:  history,
+	Args: cobra.MaximumNArgs(1),
+}
+var historyShowCmd = &cobra.Command{
+	Use:   "show ID",
+	Short: "Print a recorded run",
+	Long: `Print a recorded run.
+`,
+	Run:  historyShow,
+	Args: historyShowArgs,
+}
+var historyBaselineCmd = &cobra.Command{
+	Use:   "baseline ID",
+	Short: "Use a recorded run as a baseline",
+	Long: `Use a recorded run as a baseline.
+
+The response becomes the baseline of its request and environment, replacing
+the previous one. Baselines are compared against by run --compare-baseline
+and are never removed by the retention policy.
+`,
+	Run:  historyBaseline,
+	Args: historyShowArgs,
+}
+var historyPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove old responses",
+	Long: `Remove old responses.
+
+The limits default to the history.max-age and history.max-entries
+configuration keys.
+`,
+	Run: historyPrune,
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	historyCmd.AddCommand(historyShowCmd)
+	historyCmd.AddCommand(historyBaselineCmd)
+	historyCmd.AddCommand(historyPruneCmd)
+
+	viper.SetDefault(historyMaxAgeKey, 30*24*time.Hour)
+	viper.SetDefault(historyMaxEntriesKey, 100)
+
+	// history flags
+	historyCmd.Flags().StringP("environment", "e", "", "Filter by environment")
+	historyCmd.Flags().IntP("limit", "n", 20, "Maximum number of responses to print (0 for all)")
+
+	// historyShow flags
+	historyShowCmd.Flags().Bool("body-only", false, "Only print the response body")
+
+	// historyPrune flags
+	historyPruneCmd.Flags().Duration("older-than", 0, "Remove responses older than this duration")
+	historyPruneCmd.Flags().Int("keep", 0, "Number of responses to keep for each request")
+}
+
+// run functions
+func history(cmd *cobra.Command, args []string) {
+	envFlag, _ := cmd.Flags().GetString("environment")
+	limit, _ := cmd.Flags().GetInt("limit")
+
+	responses := []models.Response{}
+	if len(args) > 0 {
+		responses = models.GetResponsesByRequest(args[0])
+	} else {
+		responses = models.GetAllResponses()
+	}
+
+	printTableRow("ID", "TIME", "REQUEST", "ENVIRONMENT", "METHOD", "URL", "STATUS", "ELAPSED")
+	count := 0
+	for _, response := range responses {
+		if envFlag != "" && response.Request.Environment.Name != envFlag {
+			continue
+		}
+		if limit > 0 && count >= limit {
+			break
+		}
+		count++
+		id := strconv.FormatInt(response.ID, 10)
+		if response.Baseline {
+			id += "*"
+		}
+		printTableRow(id, response.Time.Local().Format(historyTimeFormat),
+			response.Request.Name, response.Request.Environment.Name,
+			response.Request.Method, response.Request.URL, strconv.Itoa(response.StatusCode),
+			response.Elapsed.Round(time.Millisecond).String())
+	}
+	tabWriter.Flush()
+}
+func historyShow(cmd *cobra.Command, args []string) {
+	id, _ := strconv.ParseInt(args[0], 10, 64)
+	response, err := models.GetResponseByID(id)
+	if err != nil {
+		log.Errorf("Could not find response %d: %+v\n", id, err)
+		os.Exit(1)
+	}
+
+	bodyOnly, _ := cmd.Flags().GetBool("body-only")
+	if bodyOnly {
+		fmt.Print(response.Body)
+		return
+	}
+	fmt.Print(formatResponse(response))
+}
+func historyBaseline(cmd *cobra.Command, args []string) {
+	id, _ := strconv.ParseInt(args[0], 10, 64)
+	response, err := models.GetResponseByID(id)
+	if err != nil {
+		log.Errorf("Could not find response %d: %+v\n", id, err)
+		os.Exit(1)
+	}
+	if err := response.MarkBaseline(); err != nil {
+		log.Errorf("Could not mark %d as baseline: %+v\n", id, err)
+		os.Exit(1)
+	}
+}
+func historyPrune(cmd *cobra.Command, args []string) {
+	maxAge := viper.GetDuration(historyMaxAgeKey)
+	maxEntries := viper.GetInt(historyMaxEntriesKey)
+	if cmd.Flags().Changed("older-than") {
+		maxAge, _ = cmd.Flags().GetDuration("older-than")
+	}
+	if cmd.Flags().Changed("keep") {
+		maxEntries, _ = cmd.Flags().GetInt("keep")
+	}
+	if err := models.PruneHistory(maxAge, maxEntries); err != nil {
+		log.Errorf("Could not prune history: %+v\n", err)
+		os.Exit(1)
+	}
+}
+
+// argument functions
+func historyShowArgs(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return errorMissingArg("ID")
+	}
+	if _, err := strconv.ParseInt(args[0], 10, 64); err != nil {
+		return errorInvalidResponseID
+	}
+	return nil
+}
+
+// helper functions
+
+// formatResponse prints a recorded run in the style of curl --verbose
+func formatResponse(response models.Response) string {
+	r := response.Request
+	output := fmt.Sprintf("# %s (%s) at %s, took %s", r.Name, r.Environment.Name,
+		response.Time.Local().Format(historyTimeFormat), response.Elapsed.Round(time.Millisecond))
+	if response.Baseline {
+		output += ", baseline"
+	}
+	output += "\n"
+	output += fmt.Sprintf("> %s %s\n", r.Method, r.URL)
+	for _, header := range r.Headers {
+		output += "> " + header.String() + "\n"
+	}
+	if r.Body != "" {
+		output += ">\n" + prefixBody("> ", r.Body)
+	}
+	output += fmt.Sprintf("\n< %s %s\n", response.Proto, response.Status)
+	for _, header := range response.Headers {
+		output += "< " + header.String() + "\n"
+	}
+	if response.Body != "" {
+		output += "\n" + response.Body
+		if response.Body[len(response.Body)-1] != '\n' {
+			output += "\n"
+		}
+	}
+	return output
+}
+func prefixBody(prefix, body string) string {
+	output := ""
+	for _, line := range strings.Split(strings.TrimSuffix(body, "\n"), "\n") {
+		output += prefix + line + "\n"
+	}
+	return output
+}
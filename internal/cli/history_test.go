@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mcastorina/poster/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatResponse(t *testing.T) {
+	response := models.Response{
+		ID: 1,
+		Request: models.Request{
+			Name:        "create",
+			Method:      "POST",
+			URL:         "http://localhost:8080/items",
+			Environment: models.Environment{Name: "local"},
+			Body:        "{\n  \"name\": \"item\"\n}",
+			Headers:     []models.Header{{Key: "Content-Type", Value: "application/json"}},
+		},
+		Proto:      "HTTP/1.1",
+		Status:     "201 Created",
+		StatusCode: 201,
+		Headers:    []models.Header{{Key: "Location", Value: "/items/1"}},
+		Body:       `{"id": 1}`,
+		Elapsed:    1234 * time.Microsecond,
+		Time:       time.Date(2020, 1, 2, 3, 4, 5, 0, time.Local),
+	}
+	expected := `# create (local) at 2020-01-02 03:04:05, took 1ms
+> POST http://localhost:8080/items
+> Content-Type: application/json
+>
+> {
+>   "name": "item"
+> }
+
+< HTTP/1.1 201 Created
+< Location: /items/1
+
+{"id": 1}
+`
+	assert.Equal(t, expected, formatResponse(response))
+}
@@ -0,0 +1,565 @@
+package cli
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/mcastorina/poster/internal/models"
+	"github.com/spf13/cobra"
+	flag "github.com/spf13/pflag"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import FORMAT",
+	Short: "Create resources from another format",
+	Long: `Create resources from another format.
+`,
+}
+var importCurlCmd = &cobra.Command{
+	Use:   "curl [CURL_COMMAND ...]",
+	Short: "Create a request resource from a curl command",
+	Long: `Create a request resource from a curl command line. The command is read
+from the arguments, or from stdin if there are none. Quote the command or
+separate it with -- so its flags are not parsed by poster:
+
+    poster import curl -n get-items -e local -- curl -H 'Accept: application/json' localhost:8080
+
+The following curl options are supported:
+
+    -X, --request               Request method
+    -H, --header                Request header
+    -d, --data, --data-ascii    Request body (@file reads the body from a file)
+    --data-raw                  Request body (no @file handling)
+    --data-binary               Request body (@file reads the body from a file)
+    --data-urlencode            URL encoded request body
+    -G, --get                   Send the data in the query string
+    -I, --head                  Use the HEAD method
+    -u, --user                  Basic authorization credentials
+    -A, --user-agent            User-Agent header
+    -e, --referer               Referer header
+    -b, --cookie                Cookie header
+    --compressed                Accept-Encoding header
+    --url                       Request URL
+
+Options that only affect how curl prints or transfers the response are
+ignored. Use --variables-from to replace the values of const variables in an
+environment with their :{variable} reference (e.g. hosts or tokens). Values
+shorter than 4 characters or inside a longer word are not replaced.
+`,
+	Run:  importCurl,
+	Args: importCurlArgs,
+}
+var importPostmanCmd = &cobra.Command{
+	Use:   "postman FILE [FILE ...]",
+	Short: "Create resources from Postman v2.1 collections and environments",
+	Long: `Create resources from Postman v2.1 collection and environment exports.
+
+Requests in a collection are named after their folders and stored with the
+default environment given by --environment. Collection variables are stored
+as const variables in that environment, and each environment export is stored
+as an environment of the same name. Placeholders such as {{host}} are
+translated to :host, or :{host} when a name character follows, and other
+colons that would start a placeholder are escaped as \:.
+
+Anything that cannot be converted (scripts, dynamic variables, form data,
+unsupported auth types) is reported as a warning.
+`,
+	Run:  importPostman,
+	Args: importCollectionArgs,
+}
+var importInsomniaCmd = &cobra.Command{
+	Use:   "insomnia FILE [FILE ...]",
+	Short: "Create resources from an Insomnia export",
+	Long: `Create resources from an Insomnia v4 export.
+
+Requests are named after their folders and stored with the default environment
+given by --environment. Variables in the base environment are stored as const
+variables in that environment, and each sub environment is stored as an
+environment of the same name. Placeholders such as {{ _.host }} are translated
+to :host, or :{host} when a name character follows, and other colons that would
+start a placeholder are escaped as \:.
+
+Anything that cannot be converted (template tags, multipart bodies,
+unsupported auth types) is reported as a warning.
+`,
+	Run:  importInsomnia,
+	Args: importCollectionArgs,
+}
+var importOpenAPICmd = &cobra.Command{
+	Use:     "openapi FILE",
+	Aliases: []string{"swagger"},
+	Short:   "Create request resources from an OpenAPI 3 or Swagger 2 document",
+	Long: `Create a request resource for every operation in an OpenAPI 3 or Swagger 2
+document (YAML or JSON).
+
+Requests are named after the operationId, so importing the document again
+updates the existing requests instead of creating new ones. The URL of each
+request is the :base-url variable followed by the path, with path parameters
+turned into variables (e.g. /items/{id} becomes :base-url/items/:id, and
+/items/{id}-x becomes :base-url/items/:{id}-x). Required query and header
+parameters are referenced as variables as well.
+
+The server URL is stored as the :base-url const variable in the environment
+given by --environment. Use --server to choose a server other than the first.
+Request bodies are filled in from the examples in the document, or generated
+from the schema when there are none.
+`,
+	Run:  importOpenAPI,
+	Args: importOpenAPIArgs,
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+	importCmd.AddCommand(importCurlCmd)
+	importCmd.AddCommand(importPostmanCmd)
+	importCmd.AddCommand(importInsomniaCmd)
+	importCmd.AddCommand(importOpenAPICmd)
+	importCmd.SetGlobalNormalizationFunc(func(f *flag.FlagSet, name string) flag.NormalizedName {
+		// Allow --env as well as --environment
+		if name == "env" {
+			name = "environment"
+		}
+		return flag.NormalizedName(name)
+	})
+
+	// import curl flags
+	importCurlCmd.Flags().StringP("name", "n", "", "Name of request for ease of use")
+	importCurlCmd.Flags().StringP("environment", "e", "", "Default environment for this request")
+	importCurlCmd.Flags().String("variables-from", "", "Replace values of const variables in this environment with references")
+
+	// import postman flags
+	importPostmanCmd.Flags().StringP("environment", "e", "", "Default environment for the requests")
+
+	// import insomnia flags
+	importInsomniaCmd.Flags().StringP("environment", "e", "", "Default environment for the requests")
+
+	// import openapi flags
+	importOpenAPICmd.Flags().StringP("environment", "e", "", "Default environment for the requests")
+	importOpenAPICmd.Flags().Int("server", 0, "Index of the server to use as the base URL")
+}
+
+// run functions
+func importCurl(cmd *cobra.Command, args []string) {
+	var words []string
+	if len(args) == 0 {
+		data, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			log.Errorf("Could not read curl command: %+v\n", err)
+			os.Exit(1)
+		}
+		args = []string{string(data)}
+	}
+	if len(args) == 1 {
+		var err error
+		if words, err = shellSplit(args[0]); err != nil {
+			log.Errorf("Could not parse curl command: %+v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		words = args
+	}
+
+	request, err := parseCurl(words)
+	if err != nil {
+		log.Errorf("Could not parse curl command: %+v\n", err)
+		os.Exit(1)
+	}
+	request.Name, _ = cmd.Flags().GetString("name")
+	environment, _ := cmd.Flags().GetString("environment")
+	request.Environment = models.Environment{Name: environment}
+
+	variables := []models.Variable{}
+	if variablesFrom, _ := cmd.Flags().GetString("variables-from"); variablesFrom != "" {
+		env, err := models.GetEnvironmentByName(variablesFrom)
+		if err != nil {
+			log.Errorf("%+v\n", err)
+			os.Exit(1)
+		}
+		variables = env.GetVariablesWithGlobal()
+	}
+	replaceValuesWithVariables(&request, variables)
+
+	if err := request.Save(); err != nil {
+		log.Errorf("Could not save request: %+v\n", err)
+		os.Exit(1)
+	}
+}
+func importPostman(cmd *cobra.Command, args []string) {
+	environment, _ := cmd.Flags().GetString("environment")
+	for _, arg := range args {
+		importCollection(arg, environment, parsePostman)
+	}
+}
+func importInsomnia(cmd *cobra.Command, args []string) {
+	environment, _ := cmd.Flags().GetString("environment")
+	for _, arg := range args {
+		importCollection(arg, environment, parseInsomnia)
+	}
+}
+func importOpenAPI(cmd *cobra.Command, args []string) {
+	environment, _ := cmd.Flags().GetString("environment")
+	server, _ := cmd.Flags().GetInt("server")
+	importCollection(args[0], environment, func(data []byte, environment string) (collection, error) {
+		return parseOpenAPI(data, environment, server)
+	})
+}
+
+// argument functions
+func importCurlArgs(cmd *cobra.Command, args []string) error {
+	if !flagsAreSet(cmd, "name", "environment") {
+		return errorMissingFlags("--name, --environment")
+	}
+	return nil
+}
+func importCollectionArgs(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return errorMissingArg("FILE")
+	}
+	if !flagsAreSet(cmd, "environment") {
+		return errorMissingFlag("--environment")
+	}
+	return nil
+}
+func importOpenAPIArgs(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return errorMissingArg("FILE")
+	}
+	if !flagsAreSet(cmd, "environment") {
+		return errorMissingFlag("--environment")
+	}
+	if server, _ := cmd.Flags().GetInt("server"); server < 0 {
+		return errorNegativeServer
+	}
+	return nil
+}
+
+// helper functions
+func importCollection(fileName, environment string, parse func([]byte, string) (collection, error)) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		log.Errorf("Could not import %s: %+v\n", fileName, err)
+		os.Exit(1)
+	}
+	c, err := parse(data, environment)
+	if err != nil {
+		log.Errorf("Could not import %s: %+v\n", fileName, err)
+		os.Exit(1)
+	}
+	for _, warning := range c.warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s: %s\n", fileName, warning)
+	}
+	if err := c.save(); err != nil {
+		log.Errorf("Could not import %s: %+v\n", fileName, err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "%s: imported %d environments, %d variables and %d requests\n",
+		fileName, len(c.environments), len(c.variables), len(c.requests))
+}
+
+// parseCurl converts the words of a curl command line into a request.
+func parseCurl(words []string) (models.Request, error) {
+	if len(words) > 0 && words[0] == "curl" {
+		words = words[1:]
+	}
+
+	// Options that take a value but have no effect on the request
+	ignoredValueOptions := map[string]bool{
+		"-o": true, "--output": true, "-w": true, "--write-out": true,
+		"-m": true, "--max-time": true, "--connect-timeout": true,
+		"--retry": true, "-x": true, "--proxy": true, "--cacert": true,
+		"-E": true, "--cert": true, "--key": true, "-c": true, "--cookie-jar": true,
+	}
+	// Options whose short form takes a value
+	shortValueOptions := "XHduAebowmxEc"
+
+	request := models.Request{Headers: []models.Header{}}
+	method := ""
+	urlStr := ""
+	data := []string{}
+	getData := false
+
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		if !strings.HasPrefix(word, "-") || word == "-" {
+			urlStr = word
+			continue
+		}
+
+		// Split the option from its value
+		option, value, hasValue := word, "", false
+		if strings.HasPrefix(word, "--") {
+			if parts := strings.SplitN(word, "=", 2); len(parts) == 2 {
+				option, value, hasValue = parts[0], parts[1], true
+			}
+		} else if len(word) > 2 {
+			if strings.IndexByte(shortValueOptions, word[1]) != -1 {
+				option, value, hasValue = word[:2], word[2:], true
+			} else {
+				// Combined boolean flags (e.g. -sSL)
+				for _, c := range word[1:] {
+					if c == 'G' {
+						getData = true
+					} else if c == 'I' {
+						method = "HEAD"
+					}
+				}
+				continue
+			}
+		}
+		nextValue := func() (string, error) {
+			if hasValue {
+				return value, nil
+			}
+			if i+1 >= len(words) {
+				return "", fmt.Errorf("option %s requires a value", option)
+			}
+			i++
+			return words[i], nil
+		}
+
+		switch option {
+		case "-X", "--request":
+			v, err := nextValue()
+			if err != nil {
+				return request, err
+			}
+			method = strings.ToUpper(v)
+		case "-H", "--header":
+			v, err := nextValue()
+			if err != nil {
+				return request, err
+			}
+			header, err := rawHeaderToSlice(v)
+			if err != nil {
+				return request, err
+			}
+			request.Headers = append(request.Headers, models.Header{Key: header[0], Value: header[1]})
+		case "-d", "--data", "--data-ascii", "--data-binary":
+			v, err := nextValue()
+			if err != nil {
+				return request, err
+			}
+			if strings.HasPrefix(v, "@") {
+				contents, err := ioutil.ReadFile(v[1:])
+				if err != nil {
+					return request, err
+				}
+				v = string(contents)
+				if option != "--data-binary" {
+					v = strings.NewReplacer("\r", "", "\n", "").Replace(v)
+				}
+			}
+			data = append(data, v)
+		case "--data-raw":
+			v, err := nextValue()
+			if err != nil {
+				return request, err
+			}
+			data = append(data, v)
+		case "--data-urlencode":
+			v, err := nextValue()
+			if err != nil {
+				return request, err
+			}
+			if parts := strings.SplitN(v, "=", 2); len(parts) == 2 {
+				v = parts[0] + "=" + url.QueryEscape(parts[1])
+			} else {
+				v = url.QueryEscape(v)
+			}
+			data = append(data, v)
+		case "-u", "--user":
+			v, err := nextValue()
+			if err != nil {
+				return request, err
+			}
+			request.Headers = append(request.Headers, models.Header{
+				Key:   "Authorization",
+				Value: "Basic " + base64.StdEncoding.EncodeToString([]byte(v)),
+			})
+		case "-A", "--user-agent":
+			v, err := nextValue()
+			if err != nil {
+				return request, err
+			}
+			request.Headers = append(request.Headers, models.Header{Key: "User-Agent", Value: v})
+		case "-e", "--referer":
+			v, err := nextValue()
+			if err != nil {
+				return request, err
+			}
+			request.Headers = append(request.Headers, models.Header{Key: "Referer", Value: v})
+		case "-b", "--cookie":
+			v, err := nextValue()
+			if err != nil {
+				return request, err
+			}
+			request.Headers = append(request.Headers, models.Header{Key: "Cookie", Value: v})
+		case "--url":
+			v, err := nextValue()
+			if err != nil {
+				return request, err
+			}
+			urlStr = v
+		case "--compressed":
+			request.Headers = append(request.Headers, models.Header{Key: "Accept-Encoding", Value: "deflate, gzip"})
+		case "-G", "--get":
+			getData = true
+		case "-I", "--head":
+			method = "HEAD"
+		case "-F", "--form":
+			return request, fmt.Errorf("option %s is not supported", option)
+		default:
+			if ignoredValueOptions[option] {
+				if _, err := nextValue(); err != nil {
+					return request, err
+				}
+			}
+		}
+	}
+
+	if urlStr == "" {
+		return request, errorMissingArg("URL")
+	}
+	body := strings.Join(data, "&")
+	if getData && body != "" {
+		if strings.Contains(urlStr, "?") {
+			urlStr += "&" + body
+		} else {
+			urlStr += "?" + body
+		}
+		body = ""
+	}
+	if method == "" {
+		method = "GET"
+		if body != "" {
+			method = "POST"
+		}
+	}
+
+	request.Method = method
+	request.URL = urlStr
+	request.Body = body
+	return request, nil
+}
+
+// shellSplit splits a command line into words following the POSIX shell
+// quoting rules, ignoring escaped newlines used for line continuation.
+func shellSplit(line string) ([]string, error) {
+	words := []string{}
+	var word strings.Builder
+	inWord := false
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '\\':
+			if i+1 < len(line) {
+				i++
+				if line[i] != '\n' {
+					word.WriteByte(line[i])
+					inWord = true
+				}
+			}
+		case c == '\'':
+			end := strings.IndexByte(line[i+1:], '\'')
+			if end == -1 {
+				return nil, errorUnterminatedQuote
+			}
+			word.WriteString(line[i+1 : i+1+end])
+			i += end + 1
+			inWord = true
+		case c == '"':
+			i++
+			for ; i < len(line) && line[i] != '"'; i++ {
+				if line[i] == '\\' && i+1 < len(line) && strings.IndexByte("$`\"\\\n", line[i+1]) != -1 {
+					i++
+					if line[i] == '\n' {
+						continue
+					}
+				}
+				word.WriteByte(line[i])
+			}
+			if i >= len(line) {
+				return nil, errorUnterminatedQuote
+			}
+			inWord = true
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// minReplacedValueLength is the length of the shortest const value that is
+// replaced with its variable, so values like 1 or 8 are left alone
+const minReplacedValueLength = 4
+
+// replaceValuesWithVariables replaces the values of const variables found in
+// the request with a reference to the variable. Longer values take priority,
+// and only values that are not part of a longer word are replaced. The colons
+// of the remaining text are escaped so they are not read as placeholders.
+func replaceValuesWithVariables(r *models.Request, variables []models.Variable) {
+	consts := []models.Variable{}
+	for _, variable := range variables {
+		if variable.Type == models.ConstType && len(variable.Value) >= minReplacedValueLength {
+			consts = append(consts, variable)
+		}
+	}
+	sort.Slice(consts, func(i, j int) bool {
+		return len(consts[i].Value) > len(consts[j].Value)
+	})
+
+	r.URL = replaceValues(r.URL, consts)
+	r.Body = replaceValues(r.Body, consts)
+	for i := range r.Headers {
+		r.Headers[i].Value = replaceValues(r.Headers[i].Value, consts)
+	}
+}
+
+// replaceValues replaces the first of consts found at each word boundary of
+// input with a :{name} reference, and escapes the text in between
+func replaceValues(input string, consts []models.Variable) string {
+	output := strings.Builder{}
+	text := strings.Builder{}
+	for i := 0; i < len(input); {
+		replaced := false
+		for _, variable := range consts {
+			end := i + len(variable.Value)
+			if !strings.HasPrefix(input[i:], variable.Value) || !isWordBoundary(input, i) || !isWordBoundary(input, end) {
+				continue
+			}
+			output.WriteString(models.EscapeText(text.String()))
+			text.Reset()
+			output.WriteString(":{" + variable.Name + "}")
+			i = end
+			replaced = true
+			break
+		}
+		if !replaced {
+			text.WriteByte(input[i])
+			i++
+		}
+	}
+	output.WriteString(models.EscapeText(text.String()))
+	return output.String()
+}
+
+// isWordBoundary reports whether index i of s is not between two name
+// characters, like the 8080 of host:8080 but not the 8080 of 18080
+func isWordBoundary(s string, i int) bool {
+	return i == 0 || i == len(s) || !models.IsNameChar(s[i-1]) || !models.IsNameChar(s[i])
+}
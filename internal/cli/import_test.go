@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/mcastorina/poster/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShellSplit(t *testing.T) {
+	words, err := shellSplit(`curl -H 'Accept: */*' -d "{\"a\": 1}" \
+	  http://localhost:8080/a\ b`)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"curl", "-H", "Accept: */*", "-d", `{"a": 1}`, "http://localhost:8080/a b"}, words)
+
+	_, err = shellSplit(`curl 'localhost`)
+	assert.Equal(t, errorUnterminatedQuote, err)
+}
+func TestParseCurlGet(t *testing.T) {
+	request, err := parseCurl([]string{"curl", "-sSL", "--compressed", "https://example.com/items?page=2"})
+	assert.Nil(t, err)
+	assert.Equal(t, "GET", request.Method)
+	assert.Equal(t, "https://example.com/items?page=2", request.URL)
+	assert.Equal(t, []models.Header{{Key: "Accept-Encoding", Value: "deflate, gzip"}}, request.Headers)
+}
+func TestParseCurlPost(t *testing.T) {
+	request, err := parseCurl([]string{"curl", "-XPUT", "localhost:8080", "-H", "Content-Type: application/json",
+		"--data-raw", `{"a":1}`, "-u", "user:pass"})
+	assert.Nil(t, err)
+	assert.Equal(t, "PUT", request.Method)
+	assert.Equal(t, "localhost:8080", request.URL)
+	assert.Equal(t, `{"a":1}`, request.Body)
+	assert.Equal(t, []models.Header{
+		{Key: "Content-Type", Value: "application/json"},
+		{Key: "Authorization", Value: "Basic dXNlcjpwYXNz"},
+	}, request.Headers)
+
+	request, err = parseCurl([]string{"curl", "localhost", "-d", "a=1", "--data=b=2"})
+	assert.Nil(t, err)
+	assert.Equal(t, "POST", request.Method)
+	assert.Equal(t, "a=1&b=2", request.Body)
+}
+func TestParseCurlDataFile(t *testing.T) {
+	file, _ := ioutil.TempFile("", "poster-test-")
+	defer os.Remove(file.Name())
+	file.WriteString("line1\nline2\n")
+	file.Close()
+
+	request, err := parseCurl([]string{"curl", "localhost", "--data-binary", "@" + file.Name()})
+	assert.Nil(t, err)
+	assert.Equal(t, "line1\nline2\n", request.Body)
+
+	request, err = parseCurl([]string{"curl", "localhost", "-d", "@" + file.Name()})
+	assert.Nil(t, err)
+	assert.Equal(t, "line1line2", request.Body)
+}
+func TestParseCurlGetData(t *testing.T) {
+	request, err := parseCurl([]string{"curl", "-G", "localhost?a=1", "-d", "b=2", "--data-urlencode", "c=x y"})
+	assert.Nil(t, err)
+	assert.Equal(t, "GET", request.Method)
+	assert.Equal(t, "localhost?a=1&b=2&c=x+y", request.URL)
+	assert.Equal(t, "", request.Body)
+}
+func TestParseCurlErrors(t *testing.T) {
+	_, err := parseCurl([]string{"curl", "-X"})
+	assert.NotNil(t, err)
+	_, err = parseCurl([]string{"curl", "-H", "Accept"})
+	assert.Equal(t, errorInvalidHeaderFormat, err)
+	_, err = parseCurl([]string{"curl", "-F", "a=b", "localhost"})
+	assert.NotNil(t, err)
+	_, err = parseCurl([]string{"curl", "-o", "out.txt"})
+	assert.NotNil(t, err)
+}
+func TestReplaceValuesWithVariables(t *testing.T) {
+	request := models.Request{
+		URL:     "https://api.example.com:8443/items",
+		Body:    `{"token": "abc123"}`,
+		Headers: []models.Header{{Key: "Authorization", Value: "Bearer abc123"}},
+	}
+	variables := []models.Variable{
+		{Name: "host", Value: "api.example.com", Type: models.ConstType},
+		{Name: "base-url", Value: "https://api.example.com:8443", Type: models.ConstType},
+		{Name: "token", Value: "abc123", Type: models.ConstType},
+		{Name: "generated", Value: "items", Type: models.ScriptType},
+	}
+	replaceValuesWithVariables(&request, variables)
+	assert.Equal(t, ":{base-url}/items", request.URL)
+	assert.Equal(t, `{"token": ":{token}"}`, request.Body)
+	assert.Equal(t, "Bearer :{token}", request.Headers[0].Value)
+}
+func TestReplaceValuesWithVariablesBoundaries(t *testing.T) {
+	request := models.Request{
+		URL:     "http://localhost:8080/items/18/versions",
+		Body:    `{"id": 18, "port": 18080, "token": "abc123-8080"}`,
+		Headers: []models.Header{{Key: "X-Port", Value: "8080"}},
+	}
+	variables := []models.Variable{
+		{Name: "id", Value: "18", Type: models.ConstType},
+		{Name: "port", Value: "8080", Type: models.ConstType},
+		{Name: "resource", Value: "items", Type: models.ConstType},
+	}
+	replaceValuesWithVariables(&request, variables)
+	// Short values and values inside longer words are left alone
+	assert.Equal(t, "http://localhost::{port}/:{resource}/18/versions", request.URL)
+	assert.Equal(t, `{"id": 18, "port": 18080, "token": "abc123-8080"}`, request.Body)
+	assert.Equal(t, ":{port}", request.Headers[0].Value)
+}
+func TestReplaceValuesWithVariablesEscapes(t *testing.T) {
+	request := models.Request{
+		URL:     "http://localhost:8080/urn:isbn:0451450523",
+		Body:    `{"id":"a:b"}`,
+		Headers: []models.Header{{Key: "Authorization", Value: "Bearer abc123"}},
+	}
+	replaceValuesWithVariables(&request, []models.Variable{})
+	assert.Equal(t, "http://localhost:8080/urn\\:isbn:0451450523", request.URL)
+	assert.Equal(t, `{"id":"a\:b"}`, request.Body)
+	assert.Equal(t, "Bearer abc123", request.Headers[0].Value)
+
+	replaceValuesWithVariables(&request, []models.Variable{{Name: "token", Value: "abc123", Type: models.ConstType}})
+	assert.Equal(t, "Bearer :{token}", request.Headers[0].Value)
+}
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mcastorina/poster/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var lintCmd = &cobra.Command{
+	Use:   "lint [REQUEST ...]",
+	Short: "Check the placeholders of requests",
+	Long: `Check the placeholders of requests.
+
+Warns about placeholders in the stored requests, or the named requests, that
+are probably not replaced as intended:
+
+    :port-name is not a variable, but :port is (write :{port}-name)
+    :tenant is not a variable in any environment
+    :$nope is not a built-in function
+
+A placeholder is a colon followed by a name that starts with a letter or an
+underscore, so :8080 and 12:30 are never placeholders. Use :{name} when the
+name is followed by a name character, and \: for a colon that is not a
+placeholder. poster exits with a non-zero status if there are any warnings.
+`,
+	Run: lint,
+}
+
+func init() {
+	rootCmd.AddCommand(lintCmd)
+}
+
+// run functions
+func lint(cmd *cobra.Command, args []string) {
+	requests := []models.Request{}
+	if len(args) == 0 {
+		requests = models.GetAllRequests()
+	}
+	for _, arg := range args {
+		request, err := models.GetRequestByName(arg)
+		if err != nil {
+			log.Errorf("Could not find request %s\n", arg)
+			os.Exit(1)
+		}
+		requests = append(requests, request)
+	}
+
+	warnings := 0
+	for _, request := range requests {
+		for _, warning := range request.Lint() {
+			fmt.Fprintln(os.Stderr, warning)
+			warnings++
+		}
+	}
+	if warnings > 0 {
+		os.Exit(1)
+	}
+}
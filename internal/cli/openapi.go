@@ -0,0 +1,343 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mcastorina/poster/internal/models"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	baseURLVariable = "base-url"
+
+	// Maximum depth of generated example bodies, to stop recursive schemas
+	maxExampleDepth = 8
+)
+
+var openAPIMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// openAPIDocument is an OpenAPI 3 or Swagger 2 document
+type openAPIDocument struct {
+	doc map[string]interface{}
+	c   *collection
+}
+
+func parseOpenAPI(data []byte, environment string, server int) (collection, error) {
+	c := collection{}
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return c, err
+	}
+	doc, ok := normalizeYAML(raw).(map[string]interface{})
+	if !ok {
+		return c, errorInvalidImportFile
+	}
+	d := openAPIDocument{doc: doc, c: &c}
+
+	swagger := d.str(doc["swagger"])
+	if !strings.HasPrefix(d.str(doc["openapi"]), "3.") && swagger != "2.0" {
+		return c, errorInvalidImportFile
+	}
+
+	c.addEnvironment(environment)
+	if baseURL := d.baseURL(server); baseURL != "" {
+		c.addVariable(baseURLVariable, baseURL, environment)
+	} else {
+		c.warnf("no server URL found, :%s must be created manually", baseURLVariable)
+	}
+	if _, ok := doc["security"]; ok {
+		c.warnf("security requirements are not supported")
+	}
+
+	paths := d.obj(doc["paths"])
+	pathNames := []string{}
+	for path := range paths {
+		pathNames = append(pathNames, path)
+	}
+	sort.Strings(pathNames)
+
+	for _, path := range pathNames {
+		pathItem := d.obj(paths[path])
+		for _, method := range openAPIMethods {
+			operation, ok := pathItem[method].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			parameters := []interface{}{}
+			parameters = append(parameters, d.list(pathItem["parameters"])...)
+			parameters = append(parameters, d.list(operation["parameters"])...)
+			c.addRequest(d.request(environment, path, method, operation, parameters))
+		}
+	}
+	return c, nil
+}
+
+// request converts an operation into a request named after its operationId
+func (d *openAPIDocument) request(environment, path, method string, operation map[string]interface{}, parameters []interface{}) models.Request {
+	name := d.str(operation["operationId"])
+	if name == "" {
+		name = method + "-" + path
+	}
+	request := models.Request{
+		Name:        name,
+		Method:      strings.ToUpper(method),
+		Environment: models.Environment{Name: environment},
+		Headers:     []models.Header{},
+	}
+
+	// Path parameters become variables
+	re := regexp.MustCompile(`\{([^{}]+)\}`)
+	urlStr := models.VariableReference(baseURLVariable, path)
+	last := 0
+	for _, match := range re.FindAllStringSubmatchIndex(path, -1) {
+		urlStr += models.EscapeText(path[last:match[0]])
+		urlStr += models.VariableReference(sanitizeVariableName(path[match[2]:match[3]]), path[match[1]:])
+		last = match[1]
+	}
+	urlStr += models.EscapeText(path[last:])
+
+	query := []string{}
+	var bodySchema map[string]interface{}
+	consumes := d.list(operation["consumes"])
+	if len(consumes) == 0 {
+		consumes = d.list(d.doc["consumes"])
+	}
+	for _, p := range parameters {
+		param := d.resolve(d.obj(p))
+		paramName := d.str(param["name"])
+		required, _ := param["required"].(bool)
+		switch d.str(param["in"]) {
+		case "query":
+			if required {
+				query = append(query, models.EscapeText(paramName)+"=:"+sanitizeVariableName(paramName))
+			}
+		case "header":
+			if required {
+				request.Headers = append(request.Headers, models.Header{
+					Key:   paramName,
+					Value: ":" + sanitizeVariableName(paramName),
+				})
+			}
+		case "body":
+			bodySchema = d.obj(param["schema"])
+		case "formData":
+			d.c.warnf("form data parameter %s in %s is not supported", paramName, name)
+		}
+	}
+	if len(query) > 0 {
+		urlStr += "?" + strings.Join(query, "&")
+	}
+	request.URL = urlStr
+
+	// Swagger 2 body parameter
+	if bodySchema != nil {
+		mediaType := "application/json"
+		if len(consumes) > 0 {
+			mediaType = d.str(consumes[0])
+		}
+		d.setBody(&request, mediaType, d.example(bodySchema, 0))
+	}
+	// OpenAPI 3 request body
+	if requestBody := d.resolve(d.obj(operation["requestBody"])); len(requestBody) > 0 {
+		content := d.obj(requestBody["content"])
+		mediaTypes := []string{}
+		for mediaType := range content {
+			mediaTypes = append(mediaTypes, mediaType)
+		}
+		sort.Strings(mediaTypes)
+		// Prefer JSON bodies
+		for i, mediaType := range mediaTypes {
+			if strings.Contains(mediaType, "json") {
+				mediaTypes[0], mediaTypes[i] = mediaTypes[i], mediaTypes[0]
+				break
+			}
+		}
+		if len(mediaTypes) > 0 {
+			media := d.obj(content[mediaTypes[0]])
+			var example interface{}
+			if value, ok := media["example"]; ok {
+				example = value
+			} else if examples := d.obj(media["examples"]); len(examples) > 0 {
+				keys := []string{}
+				for key := range examples {
+					keys = append(keys, key)
+				}
+				sort.Strings(keys)
+				example = d.resolve(d.obj(examples[keys[0]]))["value"]
+			} else {
+				example = d.example(d.obj(media["schema"]), 0)
+			}
+			d.setBody(&request, mediaTypes[0], example)
+		}
+	}
+	return request
+}
+
+func (d *openAPIDocument) setBody(r *models.Request, mediaType string, example interface{}) {
+	switch {
+	case strings.Contains(mediaType, "json"):
+		data, err := json.MarshalIndent(example, "", "  ")
+		if err != nil {
+			d.c.warnf("example body for %s could not be encoded: %+v", r.Name, err)
+			return
+		}
+		r.Body = models.EscapeText(string(data))
+	case strings.HasPrefix(mediaType, "text/"):
+		r.Body = models.EscapeText(d.str(example))
+	default:
+		d.c.warnf("body type %s in %s is not supported", mediaType, r.Name)
+		return
+	}
+	addHeaderIfMissing(r, "Content-Type", mediaType)
+}
+
+// baseURL returns the URL of the selected server with its variables replaced
+// by their default values.
+func (d *openAPIDocument) baseURL(server int) string {
+	if host := d.str(d.doc["host"]); host != "" {
+		scheme := "http"
+		if schemes := d.list(d.doc["schemes"]); len(schemes) > 0 {
+			scheme = d.str(schemes[0])
+		}
+		return scheme + "://" + host + strings.TrimSuffix(d.str(d.doc["basePath"]), "/")
+	}
+	servers := d.list(d.doc["servers"])
+	if server < 0 || server >= len(servers) {
+		return ""
+	}
+	s := d.obj(servers[server])
+	baseURL := d.str(s["url"])
+	for name, variable := range d.obj(s["variables"]) {
+		baseURL = strings.Replace(baseURL, "{"+name+"}", d.str(d.obj(variable)["default"]), -1)
+	}
+	return strings.TrimSuffix(baseURL, "/")
+}
+
+// example builds an example value from a schema, preferring the examples and
+// defaults it declares.
+func (d *openAPIDocument) example(schema map[string]interface{}, depth int) interface{} {
+	schema = d.resolve(schema)
+	if value, ok := schema["example"]; ok {
+		return value
+	}
+	if value, ok := schema["default"]; ok {
+		return value
+	}
+	if enum := d.list(schema["enum"]); len(enum) > 0 {
+		return enum[0]
+	}
+	if depth > maxExampleDepth {
+		return nil
+	}
+	for _, key := range []string{"allOf", "oneOf", "anyOf"} {
+		schemas := d.list(schema[key])
+		if len(schemas) == 0 {
+			continue
+		}
+		if key != "allOf" {
+			return d.example(d.obj(schemas[0]), depth+1)
+		}
+		merged := map[string]interface{}{}
+		for _, s := range schemas {
+			if value, ok := d.example(d.obj(s), depth+1).(map[string]interface{}); ok {
+				for k, v := range value {
+					merged[k] = v
+				}
+			}
+		}
+		return merged
+	}
+
+	switch d.str(schema["type"]) {
+	case "array":
+		return []interface{}{d.example(d.obj(schema["items"]), depth+1)}
+	case "string":
+		switch d.str(schema["format"]) {
+		case "date":
+			return "2006-01-02"
+		case "date-time":
+			return "2006-01-02T15:04:05Z"
+		case "uuid":
+			return "00000000-0000-0000-0000-000000000000"
+		case "email":
+			return "user@example.com"
+		}
+		return "string"
+	case "integer", "number":
+		return 0
+	case "boolean":
+		return false
+	}
+	// Objects, including schemas that only declare properties
+	object := map[string]interface{}{}
+	for name, property := range d.obj(schema["properties"]) {
+		object[name] = d.example(d.obj(property), depth+1)
+	}
+	return object
+}
+
+// resolve follows a local $ref (e.g. #/components/schemas/Item)
+func (d *openAPIDocument) resolve(object map[string]interface{}) map[string]interface{} {
+	for i := 0; i < maxExampleDepth; i++ {
+		ref := d.str(object["$ref"])
+		if ref == "" {
+			return object
+		}
+		if !strings.HasPrefix(ref, "#/") {
+			d.c.warnf("external reference %s is not supported", ref)
+			return map[string]interface{}{}
+		}
+		var current interface{} = d.doc
+		for _, part := range strings.Split(ref[2:], "/") {
+			part = strings.Replace(strings.Replace(part, "~1", "/", -1), "~0", "~", -1)
+			current = d.obj(current)[part]
+		}
+		object = d.obj(current)
+	}
+	return object
+}
+
+func (d *openAPIDocument) obj(v interface{}) map[string]interface{} {
+	if m, ok := v.(map[string]interface{}); ok {
+		return m
+	}
+	return map[string]interface{}{}
+}
+func (d *openAPIDocument) list(v interface{}) []interface{} {
+	if l, ok := v.([]interface{}); ok {
+		return l
+	}
+	return []interface{}{}
+}
+func (d *openAPIDocument) str(v interface{}) string {
+	switch s := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return s
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// normalizeYAML converts the map[interface{}]interface{} values produced by
+// the yaml package so they can be encoded as JSON.
+func normalizeYAML(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{})
+		for key, item := range value {
+			m[fmt.Sprintf("%v", key)] = normalizeYAML(item)
+		}
+		return m
+	case []interface{}:
+		for i, item := range value {
+			value[i] = normalizeYAML(item)
+		}
+		return value
+	}
+	return v
+}
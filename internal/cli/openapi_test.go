@@ -0,0 +1,156 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/mcastorina/poster/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+const testOpenAPI3 = `
+openapi: 3.0.0
+servers:
+  - url: https://{region}.example.com/v1/
+    variables:
+      region:
+        default: us
+  - url: http://localhost:8080/v1
+paths:
+  /items/{itemId}:
+    parameters:
+      - name: itemId
+        in: path
+        required: true
+    get:
+      operationId: getItem
+      parameters:
+        - name: X-Tenant
+          in: header
+          required: true
+        - name: verbose
+          in: query
+    patch:
+      operationId: updateItem
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Item'
+  /items:
+    post:
+      requestBody:
+        content:
+          application/json:
+            example:
+              name: widget
+components:
+  schemas:
+    Item:
+      type: object
+      properties:
+        name:
+          type: string
+          example: widget
+        count:
+          type: integer
+        tags:
+          type: array
+          items:
+            type: string
+`
+
+const testSwagger2 = `{
+	"swagger": "2.0",
+	"host": "api.example.com",
+	"basePath": "/v2",
+	"schemes": ["https"],
+	"paths": {
+		"/pets": {
+			"post": {
+				"operationId": "addPet",
+				"parameters": [{"in": "body", "name": "body", "schema": {"$ref": "#/definitions/Pet"}}]
+			}
+		}
+	},
+	"definitions": {
+		"Pet": {"type": "object", "properties": {"status": {"type": "string", "enum": ["available", "sold"]}}}
+	}
+}`
+
+func TestParseOpenAPI3(t *testing.T) {
+	c, err := parseOpenAPI([]byte(testOpenAPI3), "local", 0)
+	assert.Nil(t, err)
+
+	assert.Equal(t, []models.Environment{{Name: "local"}}, c.environments)
+	assert.Equal(t, 1, len(c.variables))
+	assert.Equal(t, "base-url", c.variables[0].Name)
+	assert.Equal(t, "https://us.example.com/v1", c.variables[0].Value)
+
+	assert.Equal(t, 3, len(c.requests))
+	post := c.requests[0]
+	assert.Equal(t, "post-items", post.Name)
+	assert.Equal(t, ":base-url/items", post.URL)
+	assert.JSONEq(t, `{"name": "widget"}`, post.Body)
+
+	get := c.requests[1]
+	assert.Equal(t, "get-item", get.Name)
+	assert.Equal(t, "GET", get.Method)
+	assert.Equal(t, ":base-url/items/:itemId", get.URL)
+	assert.Equal(t, []models.Header{{Key: "X-Tenant", Value: ":X-Tenant"}}, get.Headers)
+
+	patch := c.requests[2]
+	assert.Equal(t, "update-item", patch.Name)
+	assert.Equal(t, "PATCH", patch.Method)
+	assert.JSONEq(t, `{"name": "widget", "count": 0, "tags": ["string"]}`, patch.Body)
+	assert.Equal(t, []models.Header{{Key: "Content-Type", Value: "application/json"}}, patch.Headers)
+}
+func TestParseOpenAPI3Server(t *testing.T) {
+	c, err := parseOpenAPI([]byte(testOpenAPI3), "local", 1)
+	assert.Nil(t, err)
+	assert.Equal(t, "http://localhost:8080/v1", c.variables[0].Value)
+
+	c, err = parseOpenAPI([]byte(testOpenAPI3), "local", 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(c.variables))
+	assert.Contains(t, c.warnings, "no server URL found, :base-url must be created manually")
+}
+func TestParseSwagger2(t *testing.T) {
+	c, err := parseOpenAPI([]byte(testSwagger2), "local", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, "https://api.example.com/v2", c.variables[0].Value)
+	assert.Equal(t, 1, len(c.requests))
+	assert.Equal(t, "add-pet", c.requests[0].Name)
+	assert.JSONEq(t, `{"status": "available"}`, c.requests[0].Body)
+}
+func TestParseOpenAPIInvalid(t *testing.T) {
+	_, err := parseOpenAPI([]byte(`{"info": {}}`), "local", 0)
+	assert.Equal(t, errorInvalidImportFile, err)
+}
+func TestParseOpenAPIPlaceholders(t *testing.T) {
+	c, err := parseOpenAPI([]byte(`
+openapi: 3.0.0
+servers:
+  - url: http://localhost:8080
+paths:
+  /books/{id}.json:
+    get:
+      operationId: getBook
+  /books/{id}-{format}:
+    get:
+      operationId: exportBook
+  /urn:isbn:{isbn}:
+    post:
+      operationId: postIsbn
+      requestBody:
+        content:
+          application/json:
+            example:
+              isbn: urn:isbn:0451450523
+`), "local", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, ":base-url/books/:{id}-:format", c.requests[0].URL)
+	assert.Equal(t, ":base-url/books/:id.json", c.requests[1].URL)
+	assert.Equal(t, ":base-url/urn\\:isbn::isbn", c.requests[2].URL)
+	assert.Equal(t, "{\n  \"isbn\": \"urn\\:isbn:0451450523\"\n}", c.requests[2].Body)
+	assert.Equal(t, []string(nil), c.warnings)
+}
@@ -0,0 +1,317 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mcastorina/poster/internal/models"
+	"github.com/yalp/jsonpath"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	jsonFormat          = "json"
+	yamlFormat          = "yaml"
+	nameFormat          = "name"
+	jsonPathFormat      = "jsonpath="
+	customColumnsFormat = "custom-columns="
+
+	noneValue = "<none>"
+)
+
+// Output types of get. The field names are part of the interface used by
+// scripts, so they must not change.
+type requestOutput struct {
+	Name        string                `json:"name" yaml:"name"`
+	Method      string                `json:"method" yaml:"method"`
+	URL         string                `json:"url" yaml:"url"`
+	Environment string                `json:"default-environment" yaml:"default-environment"`
+	Body        string                `json:"body" yaml:"body"`
+	Headers     []headerOutput        `json:"headers" yaml:"headers"`
+	Assertions  []string              `json:"assertions" yaml:"assertions"`
+	Client      *models.ClientProfile `json:"client,omitempty" yaml:"client,omitempty"`
+	Auth        *models.Auth          `json:"auth,omitempty" yaml:"auth,omitempty"`
+}
+type headerOutput struct {
+	Key   string `json:"key" yaml:"key"`
+	Value string `json:"value" yaml:"value"`
+}
+type suiteOutput struct {
+	Name        string   `json:"name" yaml:"name"`
+	Environment string   `json:"default-environment" yaml:"default-environment"`
+	Requests    []string `json:"requests" yaml:"requests"`
+}
+type environmentOutput struct {
+	Name        string                    `json:"name" yaml:"name"`
+	Parent      string                    `json:"parent" yaml:"parent"`
+	Variables   []string                  `json:"variables" yaml:"variables"`
+	Client      *models.ClientProfile     `json:"client,omitempty" yaml:"client,omitempty"`
+	Certificate *models.ClientCertificate `json:"certificate,omitempty" yaml:"certificate,omitempty"`
+	Auth        *models.Auth              `json:"auth,omitempty" yaml:"auth,omitempty"`
+}
+type variableOutput struct {
+	Name        string           `json:"name" yaml:"name"`
+	Value       string           `json:"value" yaml:"value"`
+	Environment string           `json:"environment" yaml:"environment"`
+	Type        string           `json:"type" yaml:"type"`
+	Generator   *generatorOutput `json:"generator,omitempty" yaml:"generator,omitempty"`
+	Secret      bool             `json:"secret" yaml:"secret"`
+}
+type generatorOutput struct {
+	RequestName        string                 `json:"name,omitempty" yaml:"name,omitempty"`
+	RequestEnvironment string                 `json:"environment,omitempty" yaml:"environment,omitempty"`
+	RequestPath        string                 `json:"jsonpath,omitempty" yaml:"jsonpath,omitempty"`
+	Script             string                 `json:"script,omitempty" yaml:"script,omitempty"`
+	Key                string                 `json:"key,omitempty" yaml:"key,omitempty"`
+	File               string                 `json:"file,omitempty" yaml:"file,omitempty"`
+	Prompt             string                 `json:"prompt,omitempty" yaml:"prompt,omitempty"`
+	Default            string                 `json:"default,omitempty" yaml:"default,omitempty"`
+	Choices            []string               `json:"choices,omitempty" yaml:"choices,omitempty"`
+	OAuth2             *models.OAuth2Settings `json:"oauth2,omitempty" yaml:"oauth2,omitempty"`
+	Timeout            int64                  `json:"timeout" yaml:"timeout"`
+	LastGenerated      string                 `json:"last-generated,omitempty" yaml:"last-generated,omitempty"`
+}
+type sessionOutput struct {
+	Name        string         `json:"name" yaml:"name"`
+	Environment string         `json:"environment" yaml:"environment"`
+	Cookies     []cookieOutput `json:"cookies" yaml:"cookies"`
+}
+type cookieOutput struct {
+	Name     string `json:"name" yaml:"name"`
+	Value    string `json:"value" yaml:"value"`
+	Domain   string `json:"domain" yaml:"domain"`
+	Path     string `json:"path" yaml:"path"`
+	HostOnly bool   `json:"host-only" yaml:"host-only"`
+	Secure   bool   `json:"secure" yaml:"secure"`
+	HTTPOnly bool   `json:"http-only" yaml:"http-only"`
+	Expires  string `json:"expires,omitempty" yaml:"expires,omitempty"`
+}
+
+func newRequestOutput(r models.Request) requestOutput {
+	output := requestOutput{
+		Name:        r.Name,
+		Method:      r.Method,
+		URL:         r.URL,
+		Environment: r.Environment.Name,
+		Body:        r.Body,
+		Headers:     []headerOutput{},
+		Assertions:  []string{},
+	}
+	for _, header := range r.Headers {
+		output.Headers = append(output.Headers, headerOutput{Key: header.Key, Value: header.Value})
+	}
+	for _, assertion := range r.Assertions {
+		output.Assertions = append(output.Assertions, assertion.String())
+	}
+	if !r.Client.IsEmpty() {
+		output.Client = &r.Client
+	}
+	if !r.Auth.IsEmpty() {
+		auth := r.Auth.Masked()
+		output.Auth = &auth
+	}
+	return output
+}
+func newSuiteOutput(s models.Suite) suiteOutput {
+	return suiteOutput{
+		Name:        s.Name,
+		Environment: s.Environment.Name,
+		Requests:    append([]string{}, s.Requests...),
+	}
+}
+func newEnvironmentOutput(e models.Environment) environmentOutput {
+	output := environmentOutput{
+		Name:      e.Name,
+		Parent:    e.Parent,
+		Variables: e.GetVariableNames(),
+	}
+	if !e.Client.IsEmpty() {
+		output.Client = &e.Client
+	}
+	if !e.Certificate.IsEmpty() {
+		certificate := e.Certificate.Masked()
+		output.Certificate = &certificate
+	}
+	if !e.Auth.IsEmpty() {
+		auth := e.Auth.Masked()
+		output.Auth = &auth
+	}
+	return output
+}
+func newSessionOutput(s models.Session) sessionOutput {
+	output := sessionOutput{
+		Name:        s.Name,
+		Environment: s.Environment.Name,
+		Cookies:     []cookieOutput{},
+	}
+	for _, cookie := range s.Cookies {
+		cookieOut := cookieOutput{
+			Name:     cookie.Name,
+			Value:    cookie.Value,
+			Domain:   cookie.Domain,
+			Path:     cookie.Path,
+			HostOnly: cookie.HostOnly,
+			Secure:   cookie.Secure,
+			HTTPOnly: cookie.HTTPOnly,
+		}
+		if !cookie.Expires.IsZero() {
+			cookieOut.Expires = cookie.Expires.UTC().Format(time.RFC3339)
+		}
+		output.Cookies = append(output.Cookies, cookieOut)
+	}
+	return output
+}
+func newVariableOutput(v models.Variable) variableOutput {
+	output := variableOutput{
+		Name:        v.Name,
+		Value:       v.Value,
+		Environment: v.Environment.Name,
+		Type:        v.Type,
+		Secret:      v.Secret,
+	}
+	if v.Secret && v.Value != "" {
+		output.Value = models.SecretMask
+	}
+	if g := v.Generator; g != nil {
+		output.Generator = &generatorOutput{
+			RequestName:        g.RequestName,
+			RequestEnvironment: g.RequestEnvironment,
+			RequestPath:        g.RequestPath,
+			Script:             g.Script,
+			Key:                g.Key,
+			File:               g.File,
+			Prompt:             g.Prompt,
+			Default:            g.Default,
+			Choices:            g.Choices,
+			Timeout:            g.Timeout,
+		}
+		if g.OAuth2 != nil {
+			settings := g.OAuth2.Masked()
+			output.Generator.OAuth2 = &settings
+		}
+		if !g.LastGenerated.IsZero() {
+			output.Generator.LastGenerated = g.LastGenerated.UTC().Format(time.RFC3339)
+		}
+	}
+	return output
+}
+
+// isStructuredFormat returns whether format is handled by printStructured
+// instead of printing a table
+func isStructuredFormat(format string) bool {
+	switch format {
+	case jsonFormat, yamlFormat, nameFormat:
+		return true
+	}
+	return strings.HasPrefix(format, jsonPathFormat) || strings.HasPrefix(format, customColumnsFormat)
+}
+
+// printStructured prints resources, a slice of output types, in format.
+// names are the names of the resources, in the same order.
+func printStructured(format string, resources interface{}, names []string) error {
+	switch format {
+	case jsonFormat:
+		data, err := json.MarshalIndent(resources, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	case yamlFormat:
+		data, err := yaml.Marshal(resources)
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(data))
+		return nil
+	case nameFormat:
+		for _, name := range names {
+			fmt.Println(name)
+		}
+		return nil
+	}
+
+	// Convert to generic values for JSONPath
+	data, err := json.Marshal(resources)
+	if err != nil {
+		return err
+	}
+	var items []interface{}
+	if err := json.Unmarshal(data, &items); err != nil {
+		return err
+	}
+
+	if strings.HasPrefix(format, jsonPathFormat) {
+		value, err := jsonpath.Read(items, jsonPathExpression(strings.TrimPrefix(format, jsonPathFormat)))
+		if err != nil {
+			return err
+		}
+		if values, ok := value.([]interface{}); ok {
+			for _, v := range values {
+				fmt.Println(formatOutputValue(v))
+			}
+		} else {
+			fmt.Println(formatOutputValue(value))
+		}
+		return nil
+	}
+
+	columns, err := parseCustomColumns(strings.TrimPrefix(format, customColumnsFormat))
+	if err != nil {
+		return err
+	}
+	header := []interface{}{}
+	for _, column := range columns {
+		header = append(header, column[0])
+	}
+	printTableRow(header...)
+	for _, item := range items {
+		row := []interface{}{}
+		for _, column := range columns {
+			value, err := jsonpath.Read(item, column[1])
+			if err != nil || value == nil {
+				row = append(row, noneValue)
+				continue
+			}
+			row = append(row, formatOutputValue(value))
+		}
+		printTableRow(row...)
+	}
+	tabWriter.Flush()
+	return nil
+}
+
+// parseCustomColumns splits NAME:.path,... into pairs of header and JSONPath
+func parseCustomColumns(spec string) ([][2]string, error) {
+	columns := [][2]string{}
+	for _, column := range strings.Split(spec, ",") {
+		parts := strings.SplitN(column, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, errorInvalidCustomColumns
+		}
+		columns = append(columns, [2]string{parts[0], jsonPathExpression(parts[1])})
+	}
+	return columns, nil
+}
+
+// jsonPathExpression accepts expressions with or without the leading $ and
+// the braces used by kubectl, e.g. {.name}
+func jsonPathExpression(expression string) string {
+	expression = strings.TrimSuffix(strings.TrimPrefix(expression, "{"), "}")
+	if !strings.HasPrefix(expression, "$") {
+		expression = "$" + expression
+	}
+	return expression
+}
+func formatOutputValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
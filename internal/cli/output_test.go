@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bouk/monkey"
+	"github.com/mcastorina/poster/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCustomColumns(t *testing.T) {
+	columns, err := parseCustomColumns("NAME:.name,URL:$.url,HEADER:{.headers[0].key}")
+	assert.Nil(t, err)
+	assert.Equal(t, [][2]string{
+		{"NAME", "$.name"},
+		{"URL", "$.url"},
+		{"HEADER", "$.headers[0].key"},
+	}, columns)
+
+	for _, spec := range []string{"", "NAME", "NAME:", ":.name", "NAME:.name,"} {
+		_, err := parseCustomColumns(spec)
+		assert.Equal(t, errorInvalidCustomColumns, err, spec)
+	}
+}
+func TestIsStructuredFormat(t *testing.T) {
+	for _, format := range []string{"json", "yaml", "name", "jsonpath=$[*].name", "custom-columns=A:.a"} {
+		assert.True(t, isStructuredFormat(format), format)
+	}
+	for _, format := range []string{"", "wide", "jsonpath", "table"} {
+		assert.False(t, isStructuredFormat(format), format)
+	}
+}
+func TestVariableOutput(t *testing.T) {
+	variable := models.Variable{
+		Name:        "token",
+		Value:       "abc",
+		Environment: models.Environment{Name: "local"},
+		Type:        models.RequestType,
+		Generator: &models.VariableGenerator{
+			RequestName:        "get-token",
+			RequestEnvironment: "local",
+			RequestPath:        "$.token",
+			Timeout:            5,
+			LastGenerated:      time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+	}
+	data, err := json.Marshal(newVariableOutput(variable))
+	assert.Nil(t, err)
+	expected := `{"name":"token","value":"abc","environment":"local","type":"request",` +
+		`"generator":{"name":"get-token","environment":"local","jsonpath":"$.token",` +
+		`"timeout":5,"last-generated":"2020-01-02T03:04:05Z"},"secret":false}`
+	assert.Equal(t, expected, string(data))
+}
+func TestCredentialOutput(t *testing.T) {
+	defer monkey.UnpatchAll()
+	var envPtr *models.Environment
+	monkey.PatchInstanceMethod(reflect.TypeOf(envPtr), "GetVariableNames", func(e *models.Environment) []string {
+		return []string{}
+	})
+
+	request := models.Request{
+		Name: "ledger", Method: "GET", URL: "localhost",
+		Auth: models.Auth{Type: models.AuthBasic, Username: "alice", Password: "hunter2"},
+	}
+	output := newRequestOutput(request)
+	assert.Equal(t, models.Auth{Type: models.AuthBasic, Username: "alice", Password: models.SecretMask}, *output.Auth)
+	assert.Equal(t, "hunter2", request.Auth.Password)
+
+	auth := models.Auth{Type: models.AuthAWSSigV4, AccessKey: "AKID", SecretKey: "secret", Region: "us-east-1", Service: "s3"}
+	environment := models.Environment{
+		Name:        "prod",
+		Auth:        auth,
+		Certificate: models.ClientCertificate{PKCS12: "client.p12", Password: ":p12-password"},
+	}
+	envOutput := newEnvironmentOutput(environment)
+	auth.SecretKey = models.SecretMask
+	assert.Equal(t, auth, *envOutput.Auth)
+	assert.Equal(t, models.ClientCertificate{PKCS12: "client.p12", Password: models.SecretMask}, *envOutput.Certificate)
+
+	variable := models.Variable{
+		Name: "token", Environment: models.Environment{Name: "prod"}, Type: models.OAuth2Type,
+		Generator: &models.VariableGenerator{OAuth2: &models.OAuth2Settings{
+			TokenURL: "https://auth.example.com/token", GrantType: models.GrantPassword,
+			ClientID: "poster", ClientSecret: "s3cret", Username: "alice", Password: "hunter2",
+		}},
+	}
+	data, err := json.Marshal(newVariableOutput(variable).Generator.OAuth2)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(data), "s3cret")
+	assert.NotContains(t, string(data), "hunter2")
+	assert.Contains(t, string(data), `"client-id":"poster"`)
+	assert.Equal(t, "s3cret", variable.Generator.OAuth2.ClientSecret)
+}
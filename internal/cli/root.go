@@ -42,6 +42,10 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "",
 		"Config file (default is $HOME/.poster.yaml)")
 	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Verbose output")
+	rootCmd.PersistentFlags().String("db", "",
+		"Database file (default is the database of the workspace in use)")
+	viper.BindPFlag(databaseKey, rootCmd.PersistentFlags().Lookup("db"))
+	viper.BindEnv(databaseKey, "POSTER_DB")
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -68,4 +72,14 @@ func initConfig() {
 	if err := viper.ReadInConfig(); err == nil {
 		fmt.Println("Using config file:", viper.ConfigFileUsed())
 	}
+
+	// Open the database
+	path, err := databasePath()
+	if err == nil {
+		err = openDatabase(path)
+	}
+	if err != nil {
+		log.Errorf("Could not open database %s: %+v\n", path, err)
+		os.Exit(1)
+	}
 }
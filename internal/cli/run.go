@@ -9,8 +9,11 @@ import (
 
 	"github.com/mcastorina/poster/internal/models"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
+const runStrictKey = "run.strict"
+
 var runCmd = &cobra.Command{
 	Use:     "run RESOURCE_NAME [RESOURCE_NAME ...]",
 	Aliases: []string{"execute", "exec", "r"},
@@ -22,7 +25,63 @@ unless overridden with the --env flag.  For suite types, it will send all HTTP
 requests in the suite.
 
 All parts of the resource will be parsed for variables and replaced with their
-current value.
+current value. A variable is written as :name, where the name starts with a
+letter or an underscore, or as :{name} when it is followed by a letter, digit,
+underscore or hyphen, e.g. :{host}-api. Write \: for a colon that is not a
+variable. poster lint checks the variables of stored requests.
+
+Built-in functions can be used like variables, and are evaluated again for
+every occurrence in every request:
+
+    :$uuid                   Random UUID (version 4)
+    :$timestamp              Current Unix time in seconds
+    :$isotime                Current UTC time in RFC 3339 format
+    :$randint(MIN,MAX)       Random integer from MIN to MAX (default 0 to 1000)
+    :$randstring(LENGTH)     Random letters and digits (default length 16)
+    :$base64(VALUE)          Base64 encoding of VALUE
+    :$urlencode(VALUE)       URL query encoding of VALUE
+    :$sha256(VALUE)          Hex encoded SHA-256 hash of VALUE
+    :$hmac(KEY,VALUE)        Hex encoded HMAC-SHA256 of VALUE with KEY
+
+Arguments are separated by commas and may contain variables and other
+functions, e.g. :$base64(:user::pass). Use a backslash to escape a comma,
+parenthesis or backslash in an argument, e.g. :$urlencode(a\,b). Unknown
+functions and calls with invalid arguments are left unchanged.
+
+With --strict, or the run.strict configuration key, a request is not sent if
+it uses variables that are not defined in its environment, global or with
+--variable. Every undefined variable is listed with the part of the request it
+is used in instead.
+
+The HTTP client is configured by the client profiles of the environment, its
+ancestors and the request, set with poster create or poster edit, and then by
+the flags --timeout, --max-redirects, --no-follow, --proxy, --insecure and
+--cacert, which take precedence. Without any of them, there is no timeout and
+up to 10 redirects are followed.
+
+Cookies set by a response are sent by the following steps of a suite. With
+--session NAME, they are also saved in the named session of the environment
+and sent by every later run with the same session. See poster session --help.
+
+Every response is recorded in the history, unless --no-history is set. See
+poster history --help.
+
+With --compare-baseline, each response is compared against the baseline of its
+request and environment, which is set with poster history baseline ID. The
+differences are printed as with poster diff and poster exits with a non-zero
+status if there are any.
+
+Assertions stored with a request, and any given with --expect, are checked
+against the response. If any of them fail, a report of the expected and actual
+values is printed and poster exits with a non-zero status. Assertions have one
+of the following forms:
+
+    status 200 | status 2xx | status 200-299
+    header NAME exists | header NAME equals VALUE | header NAME matches REGEX
+    jsonpath PATH exists | jsonpath PATH equals JSON | jsonpath PATH matches REGEX
+    jsonpath PATH type string|number|boolean|object|array|null
+    body equals VALUE | body contains VALUE | body matches REGEX
+    time under MILLISECONDS
 `,
 	Run:  run,
 	Args: runArgs,
@@ -36,6 +95,15 @@ func init() {
 	runCmd.Flags().StringArrayP("header", "H", []string{}, "Add or overwrite request headers")
 	runCmd.Flags().StringP("data", "d", "", "Add or overwrite the request body")
 	runCmd.Flags().StringArrayP("variable", "V", []string{}, "Add or overwrite request variables")
+	runCmd.Flags().StringArray("expect", []string{}, "Add an assertion on the response")
+	runCmd.Flags().Bool("no-history", false, "Do not record the responses in the history")
+	runCmd.Flags().Bool("compare-baseline", false, "Compare the responses against their baselines")
+	runCmd.Flags().StringArray("ignore", []string{}, "Ignore a JSON path in the body when comparing")
+	runCmd.Flags().StringArray("ignore-header", []string{}, "Ignore a header when comparing")
+	runCmd.Flags().Bool("strict", false, "Fail if a request uses undefined variables")
+	runCmd.Flags().String("session", "", "Keep cookies in the named session of the environment")
+	viper.BindPFlag(runStrictKey, runCmd.Flags().Lookup("strict"))
+	addClientFlags(runCmd.Flags())
 }
 
 func run(cmd *cobra.Command, args []string) {
@@ -51,7 +119,21 @@ func run(cmd *cobra.Command, args []string) {
 	}
 
 	verboseFlag, _ := cmd.Flags().GetBool("verbose")
+	compareBaseline, _ := cmd.Flags().GetBool("compare-baseline")
+	if noHistory, _ := cmd.Flags().GetBool("no-history"); noHistory {
+		models.DisableHistory()
+	}
+	models.SetHistoryRetention(viper.GetDuration(historyMaxAgeKey), viper.GetInt(historyMaxEntriesKey))
+	if viper.GetBool(runStrictKey) {
+		models.EnableStrict()
+	}
+	client, _ := clientProfileFromFlags(cmd)
+	models.OverrideClient(client)
+	if session, _ := cmd.Flags().GetString("session"); session != "" {
+		models.UseSession(session)
+	}
 
+	differs := false
 	for _, arg := range args {
 		resource, err := models.GetRunnableResourceByName(arg)
 		if err != nil {
@@ -97,6 +179,17 @@ func run(cmd *cobra.Command, args []string) {
 			log.Errorf("Could not update headers for %s: %+v\n", arg, err)
 			os.Exit(1)
 		}
+		// Get expect flags
+		rawAssertions, _ := cmd.Flags().GetStringArray("expect")
+		assertions := []models.Assertion{}
+		for _, rawAssertion := range rawAssertions {
+			assertion, _ := models.ParseAssertion(rawAssertion)
+			assertions = append(assertions, assertion)
+		}
+		if err := resource.UpdateAssertions(assertions); err != nil {
+			log.Errorf("Could not update assertions for %s: %+v\n", arg, err)
+			os.Exit(1)
+		}
 
 		var resp *http.Response
 		if env.Name == "" {
@@ -106,13 +199,34 @@ func run(cmd *cobra.Command, args []string) {
 			// Override environment
 			resp, err = resource.RunEnv(env)
 		}
+		if assertionErr, ok := err.(*models.AssertionError); ok {
+			printResponse(resp, verboseFlag)
+			fmt.Fprintf(os.Stderr, "%s\n%s", assertionErr, assertionErr.Report())
+			os.Exit(1)
+		}
 		if err != nil {
 			log.Errorf("Could not run %s: %+v\n", arg, err)
 			os.Exit(1)
 		}
 
+		if compareBaseline {
+			response, err := models.ReadResponse(resp)
+			if err != nil {
+				log.Errorf("Could not read the response of %s: %+v\n", arg, err)
+				os.Exit(1)
+			}
+			printResponse(resp, verboseFlag)
+			if !compareWithBaseline(cmd, arg, env, response) {
+				differs = true
+			}
+			continue
+		}
+
 		printResponse(resp, verboseFlag)
 	}
+	if differs {
+		os.Exit(1)
+	}
 }
 
 // argument functions
@@ -131,6 +245,26 @@ func runArgs(cmd *cobra.Command, args []string) error {
 			return err
 		}
 	}
+	// check assertions are valid
+	if err := checkRawAssertions(cmd); err != nil {
+		return err
+	}
+	// check client options are valid
+	if _, err := clientProfileFromFlags(cmd); err != nil {
+		return err
+	}
+	// check baselines can be compared
+	if compareBaseline, _ := cmd.Flags().GetBool("compare-baseline"); compareBaseline {
+		for _, arg := range args {
+			resource, err := models.GetRunnableResourceByName(arg)
+			if err != nil {
+				continue
+			}
+			if _, ok := resource.(*models.Request); !ok {
+				return fmt.Errorf("%s: %s", errorBaselineNotRequest, arg)
+			}
+		}
+	}
 	return nil
 }
 
@@ -158,6 +292,38 @@ func printResponse(resp *http.Response, verbose bool) error {
 
 	return nil
 }
+
+// compareWithBaseline prints the differences between the response of the
+// request and its baseline, and returns whether they match.
+func compareWithBaseline(cmd *cobra.Command, name string, env models.Environment, response models.Response) bool {
+	if env.Name == "" {
+		request, _ := models.GetRequestByName(name)
+		env = request.Environment
+	}
+	baseline, err := models.GetBaseline(name, env.Name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: no baseline for %s in %s (see poster history baseline --help)\n",
+			name, env.Name)
+		return false
+	}
+	diffs := models.DiffResponses(baseline, response, getDiffOptions(cmd))
+	if len(diffs) > 0 {
+		fmt.Fprint(os.Stderr, models.DiffReport(
+			fmt.Sprintf("%s@%s (baseline %d)", name, env.Name, baseline.ID),
+			fmt.Sprintf("%s@%s", name, env.Name), diffs))
+		return false
+	}
+	return true
+}
+func checkRawAssertions(cmd *cobra.Command) error {
+	assertions, _ := cmd.Flags().GetStringArray("expect")
+	for _, assertion := range assertions {
+		if _, err := models.ParseAssertion(assertion); err != nil {
+			return fmt.Errorf("%s: %q", errorInvalidAssertionFormat, assertion)
+		}
+	}
+	return nil
+}
 func rawVariableToSlice(variable string) ([]string, error) {
 	values := strings.SplitN(variable, "=", 2)
 	if len(values) != 2 {
@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/mcastorina/poster/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var sessionCmd = &cobra.Command{
+	Use:     "session",
+	Aliases: []string{"sessions", "sess"},
+	Short:   "Manage cookie sessions",
+	Long: `Manage cookie sessions.
+
+A session is a named cookie jar of an environment, stored in the database.
+Requests run with --session NAME send the cookies of the session and save the
+cookies they receive, so a login is kept across runs. Each environment has its
+own jar, e.g. run --session alice -e prod and run --session alice -e qa use
+different cookies.
+
+The steps of a suite always share their cookies. With --session, the suite
+starts with the cookies of the session and saves them after each step.
+`,
+}
+var sessionListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls", "get"},
+	Short:   "Print sessions",
+	Long: `Print the sessions that have cookies.
+`,
+	Run:  sessionList,
+	Args: cobra.NoArgs,
+}
+var sessionShowCmd = &cobra.Command{
+	Use:   "show NAME",
+	Short: "Print the cookies of a session",
+	Long: `Print the cookies of a session, in every environment unless --env is set.
+`,
+	Run:  sessionShow,
+	Args: cobra.ExactArgs(1),
+}
+var sessionClearCmd = &cobra.Command{
+	Use:   "clear NAME",
+	Short: "Remove the cookies of a session",
+	Long: `Remove the cookies of a session, in every environment unless --env is set.
+`,
+	Run:  sessionClear,
+	Args: cobra.ExactArgs(1),
+}
+
+func init() {
+	rootCmd.AddCommand(sessionCmd)
+	sessionCmd.AddCommand(sessionListCmd)
+	sessionCmd.AddCommand(sessionShowCmd)
+	sessionCmd.AddCommand(sessionClearCmd)
+
+	// session flags
+	sessionCmd.PersistentFlags().StringP("env", "e", "", "Filter by environment")
+
+	// sessionList and sessionShow flags
+	for _, cmd := range []*cobra.Command{sessionListCmd, sessionShowCmd} {
+		cmd.Flags().StringP("output", "o", "", "Output format (json, yaml, name, jsonpath=EXPR or custom-columns=SPEC)")
+	}
+}
+
+// run functions
+func sessionList(cmd *cobra.Command, args []string) {
+	sessions := getSessionsFromArguments(cmd, "")
+	if outputFormat, _ := cmd.Flags().GetString("output"); outputFormat != "" {
+		printSessions(outputFormat, sessions)
+		return
+	}
+	printTableRow("NAME", "ENVIRONMENT", "COOKIES")
+	for _, session := range sessions {
+		printTableRow(session.Name, session.Environment.Name, strconv.Itoa(len(session.Cookies)))
+	}
+	tabWriter.Flush()
+}
+func sessionShow(cmd *cobra.Command, args []string) {
+	sessions := getSessionsFromArguments(cmd, args[0])
+	if len(sessions) == 0 {
+		log.Errorf("Could not show session %s: %+v\n", args[0], errorSessionNotFound)
+		os.Exit(1)
+	}
+	if outputFormat, _ := cmd.Flags().GetString("output"); outputFormat != "" {
+		printSessions(outputFormat, sessions)
+		return
+	}
+	printTableRow("ENVIRONMENT", "DOMAIN", "PATH", "NAME", "VALUE", "EXPIRES")
+	for _, session := range sessions {
+		for _, cookie := range session.Cookies {
+			expires := "session"
+			if !cookie.Expires.IsZero() {
+				expires = cookie.Expires.Local().Format(historyTimeFormat)
+			}
+			domain := cookie.Domain
+			if !cookie.HostOnly {
+				domain = "." + domain
+			}
+			printTableRow(session.Environment.Name, domain, cookie.Path, cookie.Name,
+				cookie.Value, expires)
+		}
+	}
+	tabWriter.Flush()
+}
+func sessionClear(cmd *cobra.Command, args []string) {
+	sessions := getSessionsFromArguments(cmd, args[0])
+	if len(sessions) == 0 {
+		log.Errorf("Could not clear session %s: %+v\n", args[0], errorSessionNotFound)
+		os.Exit(1)
+	}
+	for _, session := range sessions {
+		if err := session.Clear(); err != nil {
+			log.Errorf("Could not clear session %s: %+v\n", args[0], err)
+			os.Exit(1)
+		}
+	}
+}
+
+// helper functions
+
+// getSessionsFromArguments returns the sessions with cookies, filtered by
+// the --env flag and name if it is not empty
+func getSessionsFromArguments(cmd *cobra.Command, name string) []models.Session {
+	envFlag, _ := cmd.Flags().GetString("env")
+	sessions := []models.Session{}
+	for _, session := range models.GetAllSessions() {
+		if envFlag != "" && session.Environment.Name != envFlag {
+			continue
+		}
+		if name != "" && session.Name != name {
+			continue
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions
+}
+func printSessions(format string, sessions []models.Session) {
+	if !isStructuredFormat(format) {
+		exitOnOutputError(errorInvalidOutputFormat)
+	}
+	outputs := []sessionOutput{}
+	names := []string{}
+	for _, session := range sessions {
+		outputs = append(outputs, newSessionOutput(session))
+		names = append(names, session.Name)
+	}
+	exitOnOutputError(printStructured(format, outputs, names))
+}
@@ -5,12 +5,15 @@ import (
 )
 
 type Request struct {
-	Name        string            `yaml:"name"`
-	Method      string            `yaml:"method"`
-	URL         string            `yaml:"url"`
-	Environment string            `yaml:"default-environment"`
-	Body        string            `yaml:"body,omitempty"`
-	Headers     map[string]string `yaml:"headers"`
+	Name        string               `yaml:"name"`
+	Method      string               `yaml:"method"`
+	URL         string               `yaml:"url"`
+	Environment string               `yaml:"default-environment"`
+	Body        string               `yaml:"body,omitempty"`
+	Headers     map[string]string    `yaml:"headers"`
+	Assertions  []string             `yaml:"assertions,omitempty"`
+	Client      models.ClientProfile `yaml:"client,omitempty"`
+	Auth        models.Auth          `yaml:"auth,omitempty"`
 }
 
 func (r *Request) Save() error {
@@ -25,6 +28,14 @@ func (r *Request) Save() error {
 			Value: value,
 		})
 	}
+	assertions := []models.Assertion{}
+	for _, rawAssertion := range r.Assertions {
+		assertion, err := models.ParseAssertion(rawAssertion)
+		if err != nil {
+			return err
+		}
+		assertions = append(assertions, assertion)
+	}
 	request := models.Request{
 		Name:        r.Name,
 		Method:      r.Method,
@@ -32,36 +43,82 @@ func (r *Request) Save() error {
 		Environment: env,
 		Body:        r.Body,
 		Headers:     headers,
+		Assertions:  assertions,
+		Client:      r.Client,
+		Auth:        r.Auth,
 	}
 	return request.Save()
 }
 
+type Suite struct {
+	Name        string   `yaml:"name"`
+	Environment string   `yaml:"default-environment"`
+	Requests    []string `yaml:"requests"`
+}
+
+func (s *Suite) Save() error {
+	env, err := models.GetEnvironmentByName(s.Environment)
+	if err != nil {
+		return err
+	}
+	suite := models.Suite{
+		Name:        s.Name,
+		Environment: env,
+		Requests:    s.Requests,
+	}
+	return suite.Save()
+}
+
 type Environment struct {
-	Name      string   `yaml:"name"`
-	Variables []string `yaml:"variables"`
+	Name        string                   `yaml:"name"`
+	Parent      string                   `yaml:"parent,omitempty"`
+	Client      models.ClientProfile     `yaml:"client,omitempty"`
+	Certificate models.ClientCertificate `yaml:"certificate,omitempty"`
+	Auth        models.Auth              `yaml:"auth,omitempty"`
+	Variables   []Variable               `yaml:"variables,omitempty"`
 }
 
 func (e *Environment) Save() error {
 	env := models.Environment{
-		Name: e.Name,
+		Name:        e.Name,
+		Parent:      e.Parent,
+		Client:      e.Client,
+		Certificate: e.Certificate,
+		Auth:        e.Auth,
+	}
+	if err := env.Save(); err != nil {
+		return err
 	}
-	// TODO: Do something with e.Variables
-	return env.Save()
+	for _, variable := range e.Variables {
+		// Variables defined in an environment belong to it
+		variable.Environments = []string{e.Name}
+		if err := variable.Save(); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 type Variable struct {
 	Name         string             `yaml:"name"`
 	Type         string             `yaml:"type"`
 	Value        string             `yaml:"value,omitempty"`
-	Environments []string           `yaml:"environments"`
+	Environments []string           `yaml:"environments,omitempty"`
 	Generator    *VariableGenerator `yaml:"generator,omitempty"`
+	Secret       bool               `yaml:"secret,omitempty"`
 }
 type VariableGenerator struct {
-	RequestName        string `yaml:"name,omitempty"`
-	RequestPath        string `yaml:"jsonpath,omitempty"`
-	RequestEnvironment string `yaml:"environment,omitempty"`
-	Script             string `yaml:"script,omitempty"`
-	Timeout            int64  `yaml:"timeout,omitempty"`
+	RequestName        string                 `yaml:"name,omitempty"`
+	RequestPath        string                 `yaml:"jsonpath,omitempty"`
+	RequestEnvironment string                 `yaml:"environment,omitempty"`
+	Script             string                 `yaml:"script,omitempty"`
+	Key                string                 `yaml:"key,omitempty"`
+	File               string                 `yaml:"file,omitempty"`
+	Prompt             string                 `yaml:"prompt,omitempty"`
+	Default            string                 `yaml:"default,omitempty"`
+	Choices            []string               `yaml:"choices,omitempty"`
+	OAuth2             *models.OAuth2Settings `yaml:"oauth2,omitempty"`
+	Timeout            int64                  `yaml:"timeout,omitempty"`
 }
 
 func (v *Variable) Save() error {
@@ -73,6 +130,12 @@ func (v *Variable) Save() error {
 			RequestPath:        v.Generator.RequestPath,
 			RequestEnvironment: v.Generator.RequestEnvironment,
 			Script:             v.Generator.Script,
+			Key:                v.Generator.Key,
+			File:               v.Generator.File,
+			Prompt:             v.Generator.Prompt,
+			Default:            v.Generator.Default,
+			Choices:            v.Generator.Choices,
+			OAuth2:             v.Generator.OAuth2,
 			Timeout:            v.Generator.Timeout,
 		}
 		parent = generator.RequestEnvironment == "parent"
@@ -82,6 +145,7 @@ func (v *Variable) Save() error {
 		Value:     v.Value,
 		Type:      v.Type,
 		Generator: generator,
+		Secret:    v.Secret,
 	}
 	for _, environment := range v.Environments {
 		env, err := models.GetEnvironmentByName(environment)
@@ -109,13 +173,29 @@ func requestTemplate() Request {
 		Headers: map[string]string{
 			"Content-Type": "application/json",
 		},
+		Assertions: []string{"status 2xx"},
+	}
+}
+
+func suiteTemplate() Suite {
+	return Suite{
+		Name:        "my-super-awesome-suite",
+		Environment: "local",
+		Requests:    []string{"first-request", "second-request"},
 	}
 }
 
 func environmentTemplate() Environment {
 	return Environment{
-		Name:      "my-super-awesome-environment",
-		Variables: []string{},
+		Name:   "my-super-awesome-environment",
+		Parent: "global",
+		Variables: []Variable{
+			{
+				Name:  "host",
+				Type:  models.ConstType,
+				Value: "localhost:8080",
+			},
+		},
 	}
 }
 
@@ -151,3 +231,56 @@ func requestVariableTemplate() Variable {
 		},
 	}
 }
+
+func envVariableTemplate() Variable {
+	return Variable{
+		Name:         "my-super-awesome-variable",
+		Type:         models.EnvType,
+		Environments: []string{"global"},
+		Generator: &VariableGenerator{
+			Key: "API_TOKEN",
+		},
+	}
+}
+
+func dotenvVariableTemplate() Variable {
+	return Variable{
+		Name:         "my-super-awesome-variable",
+		Type:         models.DotenvType,
+		Environments: []string{"global"},
+		Generator: &VariableGenerator{
+			Key:  "API_TOKEN",
+			File: ".env",
+		},
+	}
+}
+
+func promptVariableTemplate() Variable {
+	return Variable{
+		Name:         "my-super-awesome-variable",
+		Type:         models.PromptType,
+		Environments: []string{"global"},
+		Generator: &VariableGenerator{
+			Prompt:  "Enter the value",
+			Default: "",
+			Choices: []string{},
+		},
+	}
+}
+
+func oauth2VariableTemplate() Variable {
+	return Variable{
+		Name:         "my-super-awesome-variable",
+		Type:         models.OAuth2Type,
+		Environments: []string{"global"},
+		Secret:       true,
+		Generator: &VariableGenerator{
+			OAuth2: &models.OAuth2Settings{
+				TokenURL:     "https://auth.example.com/oauth/token",
+				GrantType:    models.GrantClientCredentials,
+				ClientID:     "client-id",
+				ClientSecret: ":client-secret",
+			},
+		},
+	}
+}
@@ -0,0 +1,219 @@
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mcastorina/poster/internal/store"
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	databaseKey      = "database"
+	defaultWorkspace = "default"
+)
+
+var workspaceCmd = &cobra.Command{
+	Use:     "workspace",
+	Aliases: []string{"workspaces", "ws"},
+	Short:   "Manage workspaces",
+	Long: `Manage workspaces.
+
+A workspace is a named database of resources, e.g. one for each project. The
+databases are stored in $XDG_DATA_HOME/poster (~/.local/share/poster by
+default) and the default workspace is poster.db in that directory.
+
+The database can also be set with the --db flag, the POSTER_DB environment
+variable or the database configuration key, in that order of precedence. Any
+of them overrides the workspace in use.
+`,
+}
+var workspaceCreateCmd = &cobra.Command{
+	Use:   "create NAME",
+	Short: "Create a workspace",
+	Long: `Create a workspace.
+`,
+	Run:  workspaceCreate,
+	Args: workspaceArgs,
+}
+var workspaceUseCmd = &cobra.Command{
+	Use:   "use NAME",
+	Short: "Switch to a workspace",
+	Long: `Switch to a workspace.
+
+All following commands use the database of the workspace.
+`,
+	Run:  workspaceUse,
+	Args: workspaceArgs,
+}
+var workspaceListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls", "get"},
+	Short:   "Print workspaces",
+	Long: `Print workspaces. The workspace in use is marked with a *.
+`,
+	Run: workspaceList,
+}
+
+func init() {
+	rootCmd.AddCommand(workspaceCmd)
+	workspaceCmd.AddCommand(workspaceCreateCmd)
+	workspaceCmd.AddCommand(workspaceUseCmd)
+	workspaceCmd.AddCommand(workspaceListCmd)
+
+	// workspaceCreate flags
+	workspaceCreateCmd.Flags().Bool("use", false, "Switch to the workspace after creating it")
+}
+
+// run functions
+func workspaceCreate(cmd *cobra.Command, args []string) {
+	path, err := workspacePath(args[0])
+	if err != nil {
+		log.Errorf("%+v\n", err)
+		os.Exit(1)
+	}
+	if _, err := os.Stat(path); err == nil {
+		log.Errorf("Could not create workspace %s: %+v\n", args[0], errorWorkspaceExists)
+		os.Exit(1)
+	}
+	if err := openDatabase(path); err != nil {
+		log.Errorf("Could not create workspace %s: %+v\n", args[0], err)
+		os.Exit(1)
+	}
+
+	if use, _ := cmd.Flags().GetBool("use"); use {
+		workspaceUse(cmd, args)
+	}
+}
+func workspaceUse(cmd *cobra.Command, args []string) {
+	path, err := workspacePath(args[0])
+	if err != nil {
+		log.Errorf("%+v\n", err)
+		os.Exit(1)
+	}
+	if _, err := os.Stat(path); err != nil {
+		log.Errorf("Could not use workspace %s: %+v\n", args[0], errorWorkspaceNotFound)
+		os.Exit(1)
+	}
+	statePath, err := workspaceStatePath()
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(statePath), 0755)
+	}
+	if err == nil {
+		err = ioutil.WriteFile(statePath, []byte(args[0]+"\n"), 0644)
+	}
+	if err != nil {
+		log.Errorf("Could not use workspace %s: %+v\n", args[0], err)
+		os.Exit(1)
+	}
+	if viper.GetString(databaseKey) != "" {
+		fmt.Fprintf(os.Stderr, "warning: the database is set to %s, which overrides the workspace\n",
+			viper.GetString(databaseKey))
+	}
+}
+func workspaceList(cmd *cobra.Command, args []string) {
+	dir, err := dataDir()
+	if err != nil {
+		log.Errorf("%+v\n", err)
+		os.Exit(1)
+	}
+	names := []string{defaultWorkspace}
+	files, _ := filepath.Glob(filepath.Join(dir, "workspaces", "*.db"))
+	for _, file := range files {
+		names = append(names, strings.TrimSuffix(filepath.Base(file), ".db"))
+	}
+	sort.Strings(names)
+
+	current := currentWorkspace()
+	if viper.GetString(databaseKey) != "" {
+		current = ""
+	}
+	printTableRow("NAME", "DATABASE")
+	for _, name := range names {
+		path, _ := workspacePath(name)
+		if name == current {
+			name += "*"
+		}
+		printTableRow(name, path)
+	}
+	tabWriter.Flush()
+}
+
+// argument functions
+func workspaceArgs(cmd *cobra.Command, args []string) error {
+	if len(args) < 1 {
+		return errorMissingArg("NAME")
+	}
+	if len(args) > 1 {
+		return errorTooManyArgs
+	}
+	if !regexp.MustCompile(`^[\w-]+$`).MatchString(args[0]) {
+		return errorInvalidWorkspaceName
+	}
+	return nil
+}
+
+// helper functions
+
+// databasePath returns the configured database, or the database of the
+// workspace in use.
+func databasePath() (string, error) {
+	if path := viper.GetString(databaseKey); path != "" {
+		return homedir.Expand(path)
+	}
+	return workspacePath(currentWorkspace())
+}
+func openDatabase(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return store.Open(path)
+}
+func workspacePath(name string) (string, error) {
+	dir, err := dataDir()
+	if err != nil {
+		return "", err
+	}
+	if name == defaultWorkspace {
+		return filepath.Join(dir, "poster.db"), nil
+	}
+	return filepath.Join(dir, "workspaces", name+".db"), nil
+}
+func currentWorkspace() string {
+	statePath, err := workspaceStatePath()
+	if err != nil {
+		return defaultWorkspace
+	}
+	data, err := ioutil.ReadFile(statePath)
+	if name := strings.TrimSpace(string(data)); err == nil && name != "" {
+		return name
+	}
+	return defaultWorkspace
+}
+func workspaceStatePath() (string, error) {
+	dir, err := dataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "workspace"), nil
+}
+
+// dataDir returns the directory poster stores its data in, following the XDG
+// base directory specification
+func dataDir() (string, error) {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "poster"), nil
+	}
+	home, err := homedir.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", "poster"), nil
+}
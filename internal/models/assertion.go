@@ -0,0 +1,309 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yalp/jsonpath"
+)
+
+const (
+	StatusAssertion   = "status"
+	HeaderAssertion   = "header"
+	JSONPathAssertion = "jsonpath"
+	BodyAssertion     = "body"
+	TimeAssertion     = "time"
+
+	ExistsOperator   = "exists"
+	EqualsOperator   = "equals"
+	MatchesOperator  = "matches"
+	ContainsOperator = "contains"
+	TypeOperator     = "type"
+	UnderOperator    = "under"
+)
+
+// Assertion is a check on the response of a request. Assertions are written
+// as a single line:
+//
+//	status 200 | status 2xx | status 200-299
+//	header NAME exists|equals VALUE|matches REGEX
+//	jsonpath PATH exists|equals JSON|matches REGEX|type TYPE
+//	body equals VALUE|contains VALUE|matches REGEX
+//	time under MILLISECONDS
+type Assertion struct {
+	Kind     string
+	Target   string
+	Operator string
+	Value    string
+}
+
+// AssertionResult is the outcome of checking an assertion
+type AssertionResult struct {
+	Assertion Assertion
+	Expected  string
+	Actual    string
+	Passed    bool
+}
+
+// AssertionError is returned when a response fails any of its assertions
+type AssertionError struct {
+	Request string
+	Results []AssertionResult
+}
+
+func ParseAssertion(input string) (Assertion, error) {
+	parts := strings.SplitN(strings.TrimSpace(input), " ", 2)
+	a := Assertion{Kind: strings.ToLower(parts[0])}
+	rest := ""
+	if len(parts) == 2 {
+		rest = strings.TrimSpace(parts[1])
+	}
+
+	switch a.Kind {
+	case StatusAssertion:
+		a.Operator = EqualsOperator
+		a.Value = rest
+		if _, _, ok := parseStatusRange(rest); !ok {
+			return a, errorInvalidAssertion
+		}
+	case TimeAssertion:
+		fields := strings.Fields(rest)
+		if len(fields) != 2 || fields[0] != UnderOperator {
+			return a, errorInvalidAssertion
+		}
+		a.Operator = UnderOperator
+		a.Value = strings.TrimSuffix(fields[1], "ms")
+		if _, err := strconv.Atoi(a.Value); err != nil {
+			return a, errorInvalidAssertion
+		}
+	case HeaderAssertion, JSONPathAssertion:
+		fields := strings.SplitN(rest, " ", 3)
+		if len(fields) < 2 {
+			return a, errorInvalidAssertion
+		}
+		a.Target = fields[0]
+		a.Operator = fields[1]
+		if len(fields) == 3 {
+			a.Value = fields[2]
+		}
+		validOperators := map[string]bool{ExistsOperator: true, EqualsOperator: true, MatchesOperator: true}
+		if a.Kind == JSONPathAssertion {
+			validOperators[TypeOperator] = true
+		}
+		if !validOperators[a.Operator] || (a.Operator != ExistsOperator && len(fields) != 3) {
+			return a, errorInvalidAssertion
+		}
+	case BodyAssertion:
+		fields := strings.SplitN(rest, " ", 2)
+		if len(fields) != 2 {
+			return a, errorInvalidAssertion
+		}
+		a.Operator = fields[0]
+		a.Value = fields[1]
+		if a.Operator != EqualsOperator && a.Operator != ContainsOperator && a.Operator != MatchesOperator {
+			return a, errorInvalidAssertion
+		}
+	default:
+		return a, errorInvalidAssertion
+	}
+
+	if a.Operator == MatchesOperator {
+		if _, err := regexp.Compile(a.Value); err != nil {
+			return a, errorInvalidAssertion
+		}
+	}
+	return a, nil
+}
+func (a Assertion) String() string {
+	switch a.Kind {
+	case StatusAssertion:
+		return a.Kind + " " + a.Value
+	case BodyAssertion, TimeAssertion:
+		return a.Kind + " " + a.Operator + " " + a.Value
+	}
+	return strings.TrimSpace(a.Kind + " " + a.Target + " " + a.Operator + " " + a.Value)
+}
+func (a Assertion) MarshalYAML() (interface{}, error) {
+	return a.String(), nil
+}
+func (a *Assertion) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	assertion, err := ParseAssertion(s)
+	if err != nil {
+		return err
+	}
+	*a = assertion
+	return nil
+}
+
+// Check compares the response against the assertion. The body must have been
+// read from the response already.
+func (a Assertion) Check(resp *http.Response, body []byte, elapsed time.Duration) AssertionResult {
+	result := AssertionResult{Assertion: a, Expected: a.Value}
+
+	switch a.Kind {
+	case StatusAssertion:
+		low, high, _ := parseStatusRange(a.Value)
+		result.Actual = strconv.Itoa(resp.StatusCode)
+		result.Passed = resp.StatusCode >= low && resp.StatusCode <= high
+	case TimeAssertion:
+		limit, _ := strconv.Atoi(a.Value)
+		result.Expected = "< " + a.Value + "ms"
+		result.Actual = strconv.FormatInt(int64(elapsed/time.Millisecond), 10) + "ms"
+		result.Passed = elapsed < time.Duration(limit)*time.Millisecond
+	case HeaderAssertion:
+		values, ok := resp.Header[http.CanonicalHeaderKey(a.Target)]
+		result.Actual = strings.Join(values, ", ")
+		if !ok {
+			result.Actual = "<missing>"
+		}
+		result.Passed = ok && a.compare(result.Actual)
+		if a.Operator == ExistsOperator {
+			result.Expected = "<present>"
+		}
+	case BodyAssertion:
+		result.Actual = string(body)
+		result.Passed = a.compare(result.Actual)
+	case JSONPathAssertion:
+		var jBody interface{}
+		if err := json.Unmarshal(body, &jBody); err != nil {
+			result.Actual = "<invalid JSON>"
+			return result
+		}
+		val, err := jsonpath.Read(jBody, a.Target)
+		if err != nil {
+			result.Actual = "<missing>"
+			if a.Operator == ExistsOperator {
+				result.Expected = "<present>"
+			}
+			return result
+		}
+		actual, _ := json.Marshal(val)
+		result.Actual = string(actual)
+		switch a.Operator {
+		case ExistsOperator:
+			result.Expected = "<present>"
+			result.Passed = true
+		case EqualsOperator:
+			var expected interface{}
+			if err := json.Unmarshal([]byte(a.Value), &expected); err != nil {
+				expected = a.Value
+			}
+			data, _ := json.Marshal(expected)
+			result.Expected = string(data)
+			result.Passed = reflect.DeepEqual(expected, val)
+		case TypeOperator:
+			result.Actual = jsonType(val)
+			result.Passed = result.Actual == a.Value
+		case MatchesOperator:
+			if s, ok := val.(string); ok {
+				result.Actual = s
+			}
+			result.Passed = a.compare(result.Actual)
+		}
+	}
+	return result
+}
+func (a Assertion) compare(actual string) bool {
+	switch a.Operator {
+	case ExistsOperator:
+		return true
+	case EqualsOperator:
+		return actual == a.Value
+	case ContainsOperator:
+		return strings.Contains(actual, a.Value)
+	case MatchesOperator:
+		re, err := regexp.Compile(a.Value)
+		return err == nil && re.MatchString(actual)
+	}
+	return false
+}
+
+func (e *AssertionError) Error() string {
+	failed := 0
+	for _, result := range e.Results {
+		if !result.Passed {
+			failed++
+		}
+	}
+	return fmt.Sprintf("%d of %d assertions failed for %s", failed, len(e.Results), e.Request)
+}
+
+// Report returns the failed assertions as a diff of the expected and actual
+// values.
+func (e *AssertionError) Report() string {
+	report := "--- expected\n+++ actual\n"
+	for _, result := range e.Results {
+		if result.Passed {
+			continue
+		}
+		report += fmt.Sprintf("@@ %s: %s @@\n", e.Request, result.Assertion)
+		report += prefixLines("- ", result.Expected)
+		report += prefixLines("+ ", result.Actual)
+	}
+	return report
+}
+
+// checkAssertions returns an AssertionError if any of the assertions fail
+func checkAssertions(name string, assertions []Assertion, resp *http.Response, body []byte, elapsed time.Duration) error {
+	results := []AssertionResult{}
+	failed := false
+	for _, assertion := range assertions {
+		result := assertion.Check(resp, body, elapsed)
+		failed = failed || !result.Passed
+		results = append(results, result)
+	}
+	if failed {
+		return &AssertionError{Request: name, Results: results}
+	}
+	return nil
+}
+func parseStatusRange(value string) (int, int, bool) {
+	if len(value) == 3 && strings.HasSuffix(strings.ToLower(value), "xx") {
+		class, err := strconv.Atoi(value[:1])
+		return class * 100, class*100 + 99, err == nil
+	}
+	bounds := strings.SplitN(value, "-", 2)
+	low, err := strconv.Atoi(bounds[0])
+	if err != nil {
+		return 0, 0, false
+	}
+	high := low
+	if len(bounds) == 2 {
+		if high, err = strconv.Atoi(bounds[1]); err != nil {
+			return 0, 0, false
+		}
+	}
+	return low, high, low <= high
+}
+func jsonType(val interface{}) string {
+	switch val.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "array"
+	}
+	return "object"
+}
+func prefixLines(prefix, s string) string {
+	output := ""
+	for _, line := range strings.Split(strings.TrimRight(s, "\n"), "\n") {
+		output += prefix + line + "\n"
+	}
+	return output
+}
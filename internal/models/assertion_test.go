@@ -0,0 +1,83 @@
+package models
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAssertion(t *testing.T) {
+	valid := map[string]Assertion{
+		"status 2xx":                         {Kind: "status", Operator: "equals", Value: "2xx"},
+		"status 200-204":                     {Kind: "status", Operator: "equals", Value: "200-204"},
+		"header Content-Type matches ^app/":  {Kind: "header", Target: "Content-Type", Operator: "matches", Value: "^app/"},
+		"header X-Id exists":                 {Kind: "header", Target: "X-Id", Operator: "exists"},
+		"jsonpath $.items type array":        {Kind: "jsonpath", Target: "$.items", Operator: "type", Value: "array"},
+		"jsonpath $.name equals hello world": {Kind: "jsonpath", Target: "$.name", Operator: "equals", Value: "hello world"},
+		"body contains ok":                   {Kind: "body", Operator: "contains", Value: "ok"},
+		"time under 500ms":                   {Kind: "time", Operator: "under", Value: "500"},
+	}
+	for input, expected := range valid {
+		actual, err := ParseAssertion(input)
+		assert.Nil(t, err, input)
+		assert.Equal(t, expected, actual, input)
+	}
+
+	invalid := []string{"", "status", "status abc", "status 300-200", "header X-Id",
+		"header X-Id type string", "jsonpath $.a equals", "body matches (", "time 500", "size under 5"}
+	for _, input := range invalid {
+		_, err := ParseAssertion(input)
+		assert.Equal(t, errorInvalidAssertion, err, input)
+	}
+}
+func TestAssertionString(t *testing.T) {
+	for _, input := range []string{"status 2xx", "header X-Id exists", "jsonpath $.a equals 1", "body matches ^ok$", "time under 10"} {
+		assertion, _ := ParseAssertion(input)
+		assert.Equal(t, input, assertion.String())
+	}
+}
+func TestAssertionCheck(t *testing.T) {
+	resp := &http.Response{
+		StatusCode: 201,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+	}
+	body := []byte(`{"id": 7, "name": "widget", "tags": ["a"]}`)
+
+	passing := []string{"status 2xx", "status 201", "header content-type equals application/json",
+		"jsonpath $.id equals 7", "jsonpath $.name equals widget", "jsonpath $.name equals \"widget\"",
+		"jsonpath $.tags type array", "jsonpath $.id exists", "body contains widget", "time under 100"}
+	for _, input := range passing {
+		assertion, _ := ParseAssertion(input)
+		assert.True(t, assertion.Check(resp, body, 50*time.Millisecond).Passed, input)
+	}
+
+	failing := []string{"status 200", "header X-Id exists", "jsonpath $.id equals \"7\"",
+		"jsonpath $.missing exists", "jsonpath $.name type number", "body matches ^ok$", "time under 10"}
+	for _, input := range failing {
+		assertion, _ := ParseAssertion(input)
+		assert.False(t, assertion.Check(resp, body, 50*time.Millisecond).Passed, input)
+	}
+}
+func TestCheckAssertionsReport(t *testing.T) {
+	resp := &http.Response{StatusCode: 404, Header: http.Header{}}
+	status, _ := ParseAssertion("status 2xx")
+	body, _ := ParseAssertion("body contains ok")
+	err := checkAssertions("get-item", []Assertion{status, body}, resp, []byte("ok"), 0)
+
+	assertionErr, ok := err.(*AssertionError)
+	assert.True(t, ok)
+	assert.Equal(t, "1 of 2 assertions failed for get-item", assertionErr.Error())
+	assert.Equal(t, strings.Join([]string{
+		"--- expected",
+		"+++ actual",
+		"@@ get-item: status 2xx @@",
+		"- 2xx",
+		"+ 404",
+		"",
+	}, "\n"), assertionErr.Report())
+
+	assert.Nil(t, checkAssertions("get-item", []Assertion{body}, resp, []byte("ok"), 0))
+}
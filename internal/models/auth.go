@@ -0,0 +1,358 @@
+package models
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	AuthNone     = "none"
+	AuthBasic    = "basic"
+	AuthBearer   = "bearer"
+	AuthDigest   = "digest"
+	AuthAWSSigV4 = "aws-sigv4"
+)
+
+// Auth is how a request authenticates. Requests without an auth block use
+// the one of their environment, or of its nearest ancestor that has one,
+// unless the type is none. Every field may contain variables.
+type Auth struct {
+	Type     string `yaml:"type" json:"type"`
+	Username string `yaml:"username,omitempty" json:"username,omitempty"`
+	Password string `yaml:"password,omitempty" json:"password,omitempty"`
+	Token    string `yaml:"token,omitempty" json:"token,omitempty"`
+	// AWS Signature Version 4 credentials and scope
+	AccessKey    string `yaml:"access-key,omitempty" json:"access-key,omitempty"`
+	SecretKey    string `yaml:"secret-key,omitempty" json:"secret-key,omitempty"`
+	SessionToken string `yaml:"session-token,omitempty" json:"session-token,omitempty"`
+	Region       string `yaml:"region,omitempty" json:"region,omitempty"`
+	Service      string `yaml:"service,omitempty" json:"service,omitempty"`
+}
+
+func (a Auth) IsEmpty() bool {
+	return a == Auth{}
+}
+func (a Auth) Validate() error {
+	switch a.Type {
+	case "":
+		if !a.IsEmpty() {
+			return errorInvalidAuthType
+		}
+	case AuthNone:
+	case AuthBasic, AuthDigest:
+		if a.Username == "" {
+			return errorInvalidAuth
+		}
+	case AuthBearer:
+		if a.Token == "" {
+			return errorInvalidAuth
+		}
+	case AuthAWSSigV4:
+		if a.AccessKey == "" || a.SecretKey == "" || a.Region == "" || a.Service == "" {
+			return errorInvalidAuth
+		}
+	default:
+		return errorInvalidAuthType
+	}
+	return nil
+}
+
+// Masked returns the auth with its passwords, tokens and secret keys
+// replaced by SecretMask, for printing
+func (a Auth) Masked() Auth {
+	for _, field := range []*string{&a.Password, &a.Token, &a.SecretKey, &a.SessionToken} {
+		*field = maskValue(*field)
+	}
+	return a
+}
+
+// values returns the fields that may contain variables
+func (a Auth) values() []string {
+	return []string{a.Username, a.Password, a.Token, a.AccessKey, a.SecretKey,
+		a.SessionToken, a.Region, a.Service}
+}
+
+// replaceVariables returns the auth with the variables of every field
+// replaced in environment e
+func (a Auth) replaceVariables(e *Environment) Auth {
+	return Auth{
+		Type:         a.Type,
+		Username:     e.ReplaceVariables(a.Username),
+		Password:     e.ReplaceVariables(a.Password),
+		Token:        e.ReplaceVariables(a.Token),
+		AccessKey:    e.ReplaceVariables(a.AccessKey),
+		SecretKey:    e.ReplaceVariables(a.SecretKey),
+		SessionToken: e.ReplaceVariables(a.SessionToken),
+		Region:       e.ReplaceVariables(a.Region),
+		Service:      e.ReplaceVariables(a.Service),
+	}
+}
+
+// authFor returns the auth of the request, or the one it inherits from
+// environment e
+func (r *Request) authFor(e Environment) Auth {
+	if !r.Auth.IsEmpty() {
+		return r.Auth
+	}
+	for _, env := range e.Chain() {
+		if !env.Auth.IsEmpty() {
+			return env.Auth
+		}
+	}
+	return Auth{}
+}
+
+// apply adds the credentials to req, whose body is body. Digest
+// credentials are only added in response to a challenge, see
+// applyDigest.
+func (a Auth) apply(req *http.Request, body string) {
+	switch a.Type {
+	case AuthBasic:
+		req.SetBasicAuth(a.Username, a.Password)
+	case AuthBearer:
+		req.Header.Set("Authorization", "Bearer "+a.Token)
+	case AuthAWSSigV4:
+		a.signV4(req, body, time.Now())
+	}
+}
+
+// digestChallenge returns the parameters of the Digest challenge of a 401
+// response, or nil if there is none
+func digestChallenge(resp *http.Response) map[string]string {
+	if resp.StatusCode != http.StatusUnauthorized {
+		return nil
+	}
+	for _, challenge := range resp.Header["Www-Authenticate"] {
+		if len(challenge) > 7 && strings.EqualFold(challenge[:7], "digest ") {
+			return parseAuthParams(challenge[7:])
+		}
+	}
+	return nil
+}
+
+// parseAuthParams parses a list of name=value or name="quoted value" pairs
+func parseAuthParams(s string) map[string]string {
+	params := make(map[string]string)
+	for {
+		s = strings.TrimLeft(s, " \t,")
+		i := strings.IndexByte(s, '=')
+		if i < 0 {
+			return params
+		}
+		name := strings.ToLower(strings.TrimSpace(s[:i]))
+		s = strings.TrimLeft(s[i+1:], " \t")
+		value := strings.Builder{}
+		if strings.HasPrefix(s, `"`) {
+			i = 1
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				}
+				value.WriteByte(s[i])
+			}
+			if i < len(s) {
+				// Skip the closing quote
+				i++
+			}
+			s = s[i:]
+		} else {
+			i = strings.IndexByte(s, ',')
+			if i < 0 {
+				i = len(s)
+			}
+			value.WriteString(strings.TrimSpace(s[:i]))
+			s = s[i:]
+		}
+		params[name] = value.String()
+	}
+}
+
+// applyDigest answers the Digest challenge with the credentials, as
+// described by RFC 7616
+func (a Auth) applyDigest(req *http.Request, body string, challenge map[string]string) error {
+	cnonce := make([]byte, 16)
+	if _, err := rand.Read(cnonce); err != nil {
+		return err
+	}
+	value, err := a.digestAuthorization(req.Method, req.URL.RequestURI(), body, challenge,
+		hex.EncodeToString(cnonce))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", value)
+	return nil
+}
+func (a Auth) digestAuthorization(method, uri, body string, challenge map[string]string, cnonce string) (string, error) {
+	algorithm := challenge["algorithm"]
+	session := strings.HasSuffix(strings.ToUpper(algorithm), "-SESS")
+	var newHash func() hash.Hash
+	switch strings.TrimSuffix(strings.ToUpper(algorithm), "-SESS") {
+	case "", "MD5":
+		newHash = md5.New
+	case "SHA-256":
+		newHash = sha256.New
+	default:
+		return "", errorUnsupportedDigest
+	}
+	h := func(s string) string {
+		digest := newHash()
+		digest.Write([]byte(s))
+		return hex.EncodeToString(digest.Sum(nil))
+	}
+
+	qop := ""
+	for _, option := range strings.Split(challenge["qop"], ",") {
+		option = strings.TrimSpace(option)
+		if option == "auth" || (option == "auth-int" && qop == "") {
+			qop = option
+		}
+	}
+	nonce, nc := challenge["nonce"], "00000001"
+	ha1 := h(a.Username + ":" + challenge["realm"] + ":" + a.Password)
+	if session {
+		ha1 = h(ha1 + ":" + nonce + ":" + cnonce)
+	}
+	ha2 := h(method + ":" + uri)
+	if qop == "auth-int" {
+		ha2 = h(method + ":" + uri + ":" + h(body))
+	}
+	response := h(ha1 + ":" + nonce + ":" + ha2)
+	if qop != "" {
+		response = h(ha1 + ":" + nonce + ":" + nc + ":" + cnonce + ":" + qop + ":" + ha2)
+	}
+
+	quote := func(s string) string {
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+	}
+	params := []string{
+		"username=" + quote(a.Username),
+		"realm=" + quote(challenge["realm"]),
+		"nonce=" + quote(nonce),
+		"uri=" + quote(uri),
+	}
+	if algorithm != "" {
+		params = append(params, "algorithm="+algorithm)
+	}
+	params = append(params, "response="+quote(response))
+	if opaque, ok := challenge["opaque"]; ok {
+		params = append(params, "opaque="+quote(opaque))
+	}
+	if qop != "" {
+		params = append(params, "qop="+qop, "nc="+nc, "cnonce="+quote(cnonce))
+	}
+	return "Digest " + strings.Join(params, ", "), nil
+}
+
+// signV4 signs req with AWS Signature Version 4 at time t. The host and
+// every header of req are signed.
+func (a Auth) signV4(req *http.Request, body string, t time.Time) {
+	t = t.UTC()
+	amzDate := t.Format("20060102T150405Z")
+	payloadHash := sha256Hex(body)
+	req.Header.Set("X-Amz-Date", amzDate)
+	if a.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", a.SessionToken)
+	}
+	if a.Service == "s3" {
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+	req.Header.Del("Authorization")
+
+	headers := map[string]string{"host": req.URL.Host}
+	if req.Host != "" {
+		headers["host"] = req.Host
+	}
+	for key, values := range req.Header {
+		trimmed := []string{}
+		for _, value := range values {
+			trimmed = append(trimmed, strings.Join(strings.Fields(value), " "))
+		}
+		headers[strings.ToLower(key)] = strings.Join(trimmed, ",")
+	}
+	names := []string{}
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	canonicalHeaders := ""
+	for _, name := range names {
+		canonicalHeaders += name + ":" + headers[name] + "\n"
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if a.Service != "s3" {
+		// Other services expect the path to be encoded twice
+		path = awsEscape(path, false)
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		awsCanonicalQuery(req.URL.Query()),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	date := t.Format("20060102")
+	scope := date + "/" + a.Region + "/" + a.Service + "/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex(canonicalRequest)
+	key := []byte("AWS4" + a.SecretKey)
+	for _, part := range []string{date, a.Region, a.Service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		a.AccessKey, scope, signedHeaders, signature))
+}
+
+// awsCanonicalQuery encodes the query sorted by name and then value
+func awsCanonicalQuery(query url.Values) string {
+	pairs := []string{}
+	for name, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, awsEscape(name, true)+"="+awsEscape(value, true))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// awsEscape percent-encodes every byte except the unreserved characters of
+// RFC 3986, and slashes unless encodeSlash is set
+func awsEscape(s string, encodeSlash bool) string {
+	escaped := strings.Builder{}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && !encodeSlash) {
+			escaped.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&escaped, "%%%02X", c)
+	}
+	return escaped.String()
+}
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+func hmacSHA256(key []byte, s string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(s))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,176 @@
+package models
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bouk/monkey"
+	"github.com/mcastorina/poster/internal/cache"
+	"github.com/mcastorina/poster/internal/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthValidate(t *testing.T) {
+	assert.Nil(t, Auth{}.Validate())
+	assert.Nil(t, Auth{Type: AuthNone}.Validate())
+	assert.Nil(t, Auth{Type: AuthBasic, Username: ":user"}.Validate())
+	assert.Nil(t, Auth{Type: AuthBearer, Token: ":token"}.Validate())
+	assert.Nil(t, Auth{Type: AuthAWSSigV4, AccessKey: "a", SecretKey: "s", Region: "r", Service: "s"}.Validate())
+	assert.Equal(t, errorInvalidAuth, Auth{Type: AuthDigest}.Validate())
+	assert.Equal(t, errorInvalidAuth, Auth{Type: AuthAWSSigV4, AccessKey: "a"}.Validate())
+	assert.Equal(t, errorInvalidAuthType, Auth{Type: "ntlm"}.Validate())
+	assert.Equal(t, errorInvalidAuthType, Auth{Token: "abc"}.Validate())
+
+	auth := Auth{Type: AuthBasic, Username: "user", Password: ":password"}
+	assert.Equal(t, auth, splitAuth(joinAuth(auth)))
+	assert.Equal(t, "", joinAuth(Auth{}))
+}
+
+func TestAuthInheritance(t *testing.T) {
+	defer monkey.UnpatchAll()
+	monkey.Patch(cache.GetAllEnvironments, func() []store.Environment {
+		return []store.Environment{
+			{Name: "global"},
+			{Name: "prod", Auth: `{"type":"bearer","token":":token"}`},
+			{Name: "prod-eu", Parent: "prod"},
+		}
+	})
+
+	env := Environment{Name: "prod-eu"}
+	request := Request{Name: "get-ledger"}
+	assert.Equal(t, Auth{Type: AuthBearer, Token: ":token"}, request.authFor(env))
+
+	request.Auth = Auth{Type: AuthNone}
+	assert.Equal(t, Auth{Type: AuthNone}, request.authFor(env))
+	request.Auth = Auth{}
+	assert.Equal(t, Auth{}, request.authFor(Environment{Name: "global"}))
+}
+
+func TestParseAuthParams(t *testing.T) {
+	params := parseAuthParams(`realm="a, \"b\"", qop="auth,auth-int", nonce=abc ,stale=false`)
+	assert.Equal(t, map[string]string{
+		"realm": `a, "b"`,
+		"qop":   "auth,auth-int",
+		"nonce": "abc",
+		"stale": "false",
+	}, params)
+}
+
+func TestDigestAuthorization(t *testing.T) {
+	// Example of RFC 2617 section 3.5
+	auth := Auth{Type: AuthDigest, Username: "Mufasa", Password: "Circle Of Life"}
+	challenge := map[string]string{
+		"realm":  "testrealm@host.com",
+		"qop":    "auth,auth-int",
+		"nonce":  "dcd98b7102dd2f0e8b11d0f600bfb0c093",
+		"opaque": "5ccc069c403ebaf9f0171e9517f40e41",
+	}
+	value, err := auth.digestAuthorization("GET", "/dir/index.html", "", challenge, "0a4f113b")
+	assert.Nil(t, err)
+	assert.Equal(t, `Digest username="Mufasa", realm="testrealm@host.com", `+
+		`nonce="dcd98b7102dd2f0e8b11d0f600bfb0c093", uri="/dir/index.html", `+
+		`response="6629fae49393a05397450978507c4ef1", opaque="5ccc069c403ebaf9f0171e9517f40e41", `+
+		`qop=auth, nc=00000001, cnonce="0a4f113b"`, value)
+
+	challenge["algorithm"] = "SHA-512"
+	_, err = auth.digestAuthorization("GET", "/", "", challenge, "0a4f113b")
+	assert.Equal(t, errorUnsupportedDigest, err)
+}
+
+func TestSignV4(t *testing.T) {
+	// Example of the AWS Signature Version 4 documentation
+	auth := Auth{
+		Type:      AuthAWSSigV4,
+		AccessKey: "AKIDEXAMPLE",
+		SecretKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		Region:    "us-east-1",
+		Service:   "iam",
+	}
+	req, _ := http.NewRequest("GET", "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08", nil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	auth.signV4(req, "", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	assert.Equal(t, "20150830T123600Z", req.Header.Get("X-Amz-Date"))
+	assert.Equal(t, "AWS4-HMAC-SHA256 "+
+		"Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, "+
+		"SignedHeaders=content-type;host;x-amz-date, "+
+		"Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7",
+		req.Header.Get("Authorization"))
+
+	assert.Equal(t, "a=1&b=x%20y&b=z", awsCanonicalQuery(map[string][]string{"b": {"z", "x y"}, "a": {"1"}}))
+	assert.Equal(t, "/a%2520b/c", awsEscape("/a%20b/c", false))
+}
+
+func TestAuthRun(t *testing.T) {
+	const realm, nonce = "poster", "6f1d6e2b"
+	md5Hex := func(s string) string {
+		sum := md5.Sum([]byte(s))
+		return hex.EncodeToString(sum[:])
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/basic":
+			if user, password, ok := r.BasicAuth(); !ok || user != "alice" || password != "s3cret" {
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+		case "/bearer":
+			if r.Header.Get("Authorization") != "Bearer abc" {
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+		case "/digest":
+			header := r.Header.Get("Authorization")
+			if !strings.HasPrefix(header, "Digest ") {
+				w.Header().Set("WWW-Authenticate",
+					fmt.Sprintf(`Digest realm="%s", qop="auth", nonce="%s"`, realm, nonce))
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			params := parseAuthParams(header[7:])
+			ha1 := md5Hex("alice:" + realm + ":s3cret")
+			ha2 := md5Hex(r.Method + ":" + params["uri"])
+			expected := md5Hex(ha1 + ":" + nonce + ":" + params["nc"] + ":" + params["cnonce"] + ":auth:" + ha2)
+			if params["response"] != expected || params["uri"] != r.URL.RequestURI() {
+				w.WriteHeader(http.StatusForbidden)
+			}
+		}
+	}))
+	defer server.Close()
+
+	env := Environment{Name: "auth-run", Auth: Auth{Type: AuthBearer, Token: ":token"}}
+	assert.Nil(t, env.Save())
+	for name, value := range map[string]string{"user": "alice", "password": "s3cret", "token": "abc"} {
+		variable := Variable{Name: name, Value: value, Type: ConstType, Environment: env}
+		assert.Nil(t, variable.Save())
+	}
+
+	requests := []Request{
+		{
+			Name: "auth-basic", Method: "GET", URL: server.URL + "/basic", Environment: env,
+			Auth: Auth{Type: AuthBasic, Username: ":user", Password: ":password"},
+		},
+		// The bearer token is inherited from the environment
+		{Name: "auth-bearer", Method: "GET", URL: server.URL + "/bearer", Environment: env},
+		{
+			Name: "auth-digest", Method: "GET", URL: server.URL + "/digest?page=1", Environment: env,
+			Auth: Auth{Type: AuthDigest, Username: ":user", Password: ":password"},
+		},
+	}
+	for _, request := range requests {
+		resp, err := request.Run()
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode, request.Name)
+	}
+
+	// Requests can opt out of the auth of their environment
+	request := Request{Name: "auth-none", Method: "GET", URL: server.URL + "/bearer", Environment: env,
+		Auth: Auth{Type: AuthNone}}
+	resp, err := request.Run()
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
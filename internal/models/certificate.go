@@ -0,0 +1,124 @@
+package models
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+)
+
+// ClientCertificate is the certificate an environment presents to servers
+// that ask for one, for mutual TLS. It is read from a PEM certificate and
+// key, or from a PKCS#12 file. The paths and the password may contain
+// variables, which are replaced with their current value.
+type ClientCertificate struct {
+	// Cert and Key are PEM files, Key may be empty if Cert contains both
+	Cert     string `yaml:"cert,omitempty" json:"cert,omitempty"`
+	Key      string `yaml:"key,omitempty" json:"key,omitempty"`
+	PKCS12   string `yaml:"pkcs12,omitempty" json:"pkcs12,omitempty"`
+	Password string `yaml:"password,omitempty" json:"password,omitempty"`
+}
+
+func (c ClientCertificate) IsEmpty() bool {
+	return c == ClientCertificate{}
+}
+func (c ClientCertificate) Validate() error {
+	if c.IsEmpty() {
+		return nil
+	}
+	if (c.Cert == "") == (c.PKCS12 == "") {
+		return errorInvalidCertificate
+	}
+	if c.Cert == "" && c.Key != "" {
+		return errorInvalidCertificate
+	}
+	if c.PKCS12 == "" && c.Password != "" {
+		return errorInvalidCertificate
+	}
+	return nil
+}
+
+// Masked returns the certificate with its password replaced by SecretMask,
+// for printing
+func (c ClientCertificate) Masked() ClientCertificate {
+	c.Password = maskValue(c.Password)
+	return c
+}
+
+// clientCertificate returns the certificate of the environment, or of its
+// nearest ancestor that has one
+func (e *Environment) clientCertificate() ClientCertificate {
+	for _, env := range e.Chain() {
+		if !env.Certificate.IsEmpty() {
+			return env.Certificate
+		}
+	}
+	return ClientCertificate{}
+}
+
+// load reads the certificate, replacing the variables of the paths and the
+// password in environment e
+func (c ClientCertificate) load(e *Environment) (tls.Certificate, error) {
+	if c.PKCS12 == "" {
+		certFile := e.ReplaceVariables(c.Cert)
+		keyFile := certFile
+		if c.Key != "" {
+			keyFile = e.ReplaceVariables(c.Key)
+		}
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			log.Errorf("%+v\n", err)
+			return tls.Certificate{}, errorLoadCertificateFailed
+		}
+		return cert, nil
+	}
+
+	data, err := ioutil.ReadFile(e.ReplaceVariables(c.PKCS12))
+	if err != nil {
+		log.Errorf("%+v\n", err)
+		return tls.Certificate{}, errorLoadCertificateFailed
+	}
+	key, certs, err := decodePKCS12(data, e.ReplaceVariables(c.Password))
+	if err != nil {
+		log.Errorf("%+v\n", err)
+		return tls.Certificate{}, errorLoadCertificateFailed
+	}
+	leaf, chain := splitCertificateChain(key, certs)
+	if leaf == nil {
+		log.Errorf("no certificate matches the private key\n")
+		return tls.Certificate{}, errorLoadCertificateFailed
+	}
+	cert := tls.Certificate{
+		Certificate: [][]byte{leaf.Raw},
+		PrivateKey:  key,
+		Leaf:        leaf,
+	}
+	for _, ca := range chain {
+		cert.Certificate = append(cert.Certificate, ca.Raw)
+	}
+	return cert, nil
+}
+
+// splitCertificateChain returns the certificate of the private key and the
+// other certificates, which are sent as its chain
+func splitCertificateChain(key crypto.PrivateKey, certs []*x509.Certificate) (*x509.Certificate, []*x509.Certificate) {
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, nil
+	}
+	public, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		return nil, nil
+	}
+	var leaf *x509.Certificate
+	chain := []*x509.Certificate{}
+	for _, cert := range certs {
+		if leaf == nil && bytes.Equal(cert.RawSubjectPublicKeyInfo, public) {
+			leaf = cert
+			continue
+		}
+		chain = append(chain, cert)
+	}
+	return leaf, chain
+}
@@ -0,0 +1,128 @@
+package models
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientCertificateValidate(t *testing.T) {
+	assert.Nil(t, ClientCertificate{}.Validate())
+	assert.Nil(t, ClientCertificate{Cert: "client.pem"}.Validate())
+	assert.Nil(t, ClientCertificate{Cert: "client.pem", Key: "client.key"}.Validate())
+	assert.Nil(t, ClientCertificate{PKCS12: "client.p12", Password: ":password"}.Validate())
+	assert.Equal(t, errorInvalidCertificate, ClientCertificate{Key: "client.key"}.Validate())
+	assert.Equal(t, errorInvalidCertificate, ClientCertificate{Cert: "client.pem", PKCS12: "client.p12"}.Validate())
+	assert.Equal(t, errorInvalidCertificate, ClientCertificate{Cert: "client.pem", Password: "secret"}.Validate())
+
+	certificate := ClientCertificate{PKCS12: "client.p12", Password: "secret"}
+	assert.Equal(t, certificate, splitClientCertificate(joinClientCertificate(certificate)))
+	assert.Equal(t, "", joinClientCertificate(ClientCertificate{}))
+}
+
+func TestClientCertificateTLS(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.TLS.PeerCertificates[0].Subject.CommonName)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	p12, err := filepath.Abs("testdata/client.p12")
+	assert.Nil(t, err)
+	insecure := true
+	parent := Environment{
+		Name:        "mtls",
+		Client:      ClientProfile{Insecure: &insecure},
+		Certificate: ClientCertificate{PKCS12: ":p12-file", Password: ":p12-password"},
+	}
+	assert.Nil(t, parent.Save())
+	for name, value := range map[string]string{"p12-file": p12, "p12-password": "secret"} {
+		variable := Variable{Name: name, Value: value, Type: ConstType, Environment: parent}
+		assert.Nil(t, variable.Save())
+	}
+	child := Environment{Name: "mtls-child", Parent: parent.Name}
+	assert.Nil(t, child.Save())
+
+	// The child presents the certificate of its parent
+	assert.Equal(t, "poster-client", getCommonName(t, child, server.URL))
+
+	dir, err := ioutil.TempDir("", "poster-mtls")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	cert, key := writeCertificate(t, dir, "poster-pem")
+	child.Certificate = ClientCertificate{Cert: cert, Key: key}
+	assert.Nil(t, child.Save())
+	assert.Equal(t, "poster-pem", getCommonName(t, child, server.URL))
+
+	child.Certificate = ClientCertificate{PKCS12: p12, Password: "wrong"}
+	assert.Nil(t, child.Save())
+	_, err = child.clientProfile().newClient(&child)
+	assert.Equal(t, errorLoadCertificateFailed, err)
+}
+
+func TestClientCertificateRequired(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	insecure := true
+	client, err := ClientProfile{Insecure: &insecure}.newClient(&Environment{Name: "global"})
+	assert.Nil(t, err)
+	_, err = client.Get(server.URL)
+	assert.NotNil(t, err)
+}
+
+// getCommonName sends a request to the server in environment e, which
+// responds with the common name of the client certificate
+func getCommonName(t *testing.T, e Environment, url string) string {
+	client, err := e.clientProfile().newClient(&e)
+	assert.Nil(t, err)
+	resp, err := client.Get(url)
+	if !assert.Nil(t, err) {
+		return ""
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+	return string(body)
+}
+
+// writeCertificate writes a self-signed certificate and its key to PEM
+// files in dir
+func writeCertificate(t *testing.T, dir, commonName string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.Nil(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.Nil(t, err)
+
+	certFile := filepath.Join(dir, "client.pem")
+	keyFile := filepath.Join(dir, "client.key")
+	assert.Nil(t, ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	assert.Nil(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	return certFile, keyFile
+}
@@ -0,0 +1,198 @@
+package models
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ClientProfile configures the HTTP client that sends a request. Profiles
+// of the environment, its ancestors and the request are merged, with the
+// request taking precedence. Unset fields use the default of net/http.
+type ClientProfile struct {
+	// Timeout is a duration like 30s, including reading the response body
+	Timeout string `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	// MaxRedirects is the number of redirects followed, 0 to not follow
+	MaxRedirects *int   `yaml:"max-redirects,omitempty" json:"max-redirects,omitempty"`
+	Proxy        string `yaml:"proxy,omitempty" json:"proxy,omitempty"`
+	Insecure     *bool  `yaml:"insecure-skip-verify,omitempty" json:"insecure-skip-verify,omitempty"`
+	// CACert is a PEM file of certificates trusted in addition to the
+	// system ones
+	CACert string `yaml:"ca-cert,omitempty" json:"ca-cert,omitempty"`
+}
+
+var clientOverride ClientProfile
+
+// OverrideClient sets the client options that take precedence over the
+// profiles of every request and environment
+func OverrideClient(p ClientProfile) {
+	clientOverride = p
+}
+
+func (p ClientProfile) IsEmpty() bool {
+	return p == ClientProfile{}
+}
+func (p ClientProfile) Validate() error {
+	if p.Timeout != "" {
+		if timeout, err := time.ParseDuration(p.Timeout); err != nil || timeout < 0 {
+			return errorInvalidTimeout
+		}
+	}
+	if p.MaxRedirects != nil && *p.MaxRedirects < 0 {
+		return errorInvalidRedirects
+	}
+	return nil
+}
+
+// merge returns the profile with the fields that are set in o replaced
+func (p ClientProfile) merge(o ClientProfile) ClientProfile {
+	if o.Timeout != "" {
+		p.Timeout = o.Timeout
+	}
+	if o.MaxRedirects != nil {
+		p.MaxRedirects = o.MaxRedirects
+	}
+	if o.Proxy != "" {
+		p.Proxy = o.Proxy
+	}
+	if o.Insecure != nil {
+		p.Insecure = o.Insecure
+	}
+	if o.CACert != "" {
+		p.CACert = o.CACert
+	}
+	return p
+}
+
+// clientProfile returns the profile of the environment merged with the
+// profiles of its ancestors
+func (e *Environment) clientProfile() ClientProfile {
+	profile := ClientProfile{}
+	chain := e.Chain()
+	for i := len(chain) - 1; i >= 0; i-- {
+		profile = profile.merge(chain[i].Client)
+	}
+	return profile
+}
+
+// clientFields returns the parts of the client of request r in environment
+// e that may contain variables: the paths and password of the client
+// certificate, and the proxy and CA certificate of the merged profile
+func (r *Request) clientFields(e Environment) [][2]string {
+	certificate := e.clientCertificate()
+	profile := e.clientProfile().merge(r.Client).merge(clientOverride)
+	fields := [][2]string{}
+	for _, field := range [][2]string{
+		{"cert", certificate.Cert},
+		{"key", certificate.Key},
+		{"pkcs12", certificate.PKCS12},
+		{"pkcs12 password", certificate.Password},
+		{"proxy", profile.Proxy},
+		{"ca-cert", profile.CACert},
+	} {
+		if field[1] != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// newClient creates the client for the profile, which presents the client
+// certificate of environment e. The proxy and CA certificate may contain
+// variables, which are replaced in environment e.
+func (p ClientProfile) newClient(e *Environment) (*http.Client, error) {
+	certificate := e.clientCertificate()
+	if p.IsEmpty() && certificate.IsEmpty() {
+		return http.DefaultClient, nil
+	}
+	client := &http.Client{}
+	if p.Timeout != "" {
+		timeout, err := time.ParseDuration(p.Timeout)
+		if err != nil {
+			return nil, errorInvalidTimeout
+		}
+		client.Timeout = timeout
+	}
+	if p.MaxRedirects != nil {
+		max := *p.MaxRedirects
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			if max == 0 {
+				// Return the redirect response itself
+				return http.ErrUseLastResponse
+			}
+			if len(via) > max {
+				return fmt.Errorf("stopped after %d redirects", max)
+			}
+			return nil
+		}
+	}
+
+	transport := newTransport()
+	if p.Proxy != "" {
+		proxy, err := url.Parse(e.ReplaceVariables(p.Proxy))
+		if err != nil {
+			log.Errorf("%+v\n", err)
+			return nil, errorInvalidProxy
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	if p.Insecure != nil && *p.Insecure {
+		transport.TLSClientConfig.InsecureSkipVerify = true
+	}
+	if p.CACert != "" {
+		pool, err := certPool(e.ReplaceVariables(p.CACert))
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig.RootCAs = pool
+	}
+	if !certificate.IsEmpty() {
+		cert, err := certificate.load(e)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig.Certificates = []tls.Certificate{cert}
+	}
+	client.Transport = transport
+	return client, nil
+}
+
+// newTransport returns a transport with the settings of
+// http.DefaultTransport
+func newTransport() *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+		TLSClientConfig:       &tls.Config{},
+	}
+}
+
+// certPool returns the system certificates with the PEM certificates of
+// file added
+func certPool(file string) (*x509.CertPool, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		log.Errorf("%+v\n", err)
+		return nil, errorInvalidCACert
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, errorInvalidCACert
+	}
+	return pool, nil
+}
@@ -0,0 +1,130 @@
+package models
+
+import (
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bouk/monkey"
+	"github.com/mcastorina/poster/internal/cache"
+	"github.com/mcastorina/poster/internal/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientProfileMerge(t *testing.T) {
+	zero, three := 0, 3
+	insecure := true
+	environment := ClientProfile{Timeout: "30s", MaxRedirects: &three, Insecure: &insecure}
+	request := ClientProfile{Timeout: "5s", Proxy: "http://proxy:3128"}
+	override := ClientProfile{MaxRedirects: &zero}
+
+	profile := environment.merge(request).merge(override)
+	assert.Equal(t, "5s", profile.Timeout)
+	assert.Equal(t, 0, *profile.MaxRedirects)
+	assert.Equal(t, "http://proxy:3128", profile.Proxy)
+	assert.True(t, *profile.Insecure)
+	assert.Equal(t, "", profile.CACert)
+
+	assert.True(t, ClientProfile{}.IsEmpty())
+	assert.False(t, override.IsEmpty())
+	assert.Equal(t, profile, splitClientProfile(joinClientProfile(profile)))
+	assert.Equal(t, "", joinClientProfile(ClientProfile{}))
+}
+
+func TestClientProfileValidate(t *testing.T) {
+	negative := -1
+	assert.Nil(t, ClientProfile{Timeout: "1m30s"}.Validate())
+	assert.Equal(t, errorInvalidTimeout, ClientProfile{Timeout: "30"}.Validate())
+	assert.Equal(t, errorInvalidTimeout, ClientProfile{Timeout: "-1s"}.Validate())
+	assert.Equal(t, errorInvalidRedirects, ClientProfile{MaxRedirects: &negative}.Validate())
+}
+
+func TestClientRedirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/done" {
+			return
+		}
+		http.Redirect(w, r, "/done", http.StatusFound)
+	}))
+	defer server.Close()
+
+	env := Environment{Name: "global"}
+	zero, one := 0, 1
+	client, err := ClientProfile{MaxRedirects: &zero}.newClient(&env)
+	assert.Nil(t, err)
+	resp, err := client.Get(server.URL + "/start")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusFound, resp.StatusCode)
+
+	client, err = ClientProfile{MaxRedirects: &one}.newClient(&env)
+	assert.Nil(t, err)
+	resp, err = client.Get(server.URL + "/start")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestClientTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	env := Environment{Name: "global"}
+	client, err := ClientProfile{Timeout: "50ms"}.newClient(&env)
+	assert.Nil(t, err)
+	_, err = client.Get(server.URL)
+	assert.NotNil(t, err)
+}
+
+func TestClientTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	env := Environment{Name: "global"}
+
+	// The certificate of the test server is not trusted by default
+	client, err := ClientProfile{Timeout: "5s"}.newClient(&env)
+	assert.Nil(t, err)
+	_, err = client.Get(server.URL)
+	assert.NotNil(t, err)
+
+	insecure := true
+	client, err = ClientProfile{Insecure: &insecure}.newClient(&env)
+	assert.Nil(t, err)
+	_, err = client.Get(server.URL)
+	assert.Nil(t, err)
+
+	file, err := ioutil.TempFile("", "poster-ca")
+	assert.Nil(t, err)
+	defer os.Remove(file.Name())
+	pem.Encode(file, &pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	file.Close()
+
+	client, err = ClientProfile{CACert: file.Name()}.newClient(&env)
+	assert.Nil(t, err)
+	_, err = client.Get(server.URL)
+	assert.Nil(t, err)
+
+	_, err = ClientProfile{CACert: os.DevNull}.newClient(&env)
+	assert.Equal(t, errorInvalidCACert, err)
+}
+
+func TestEnvironmentClientProfile(t *testing.T) {
+	defer monkey.UnpatchAll()
+	monkey.Patch(cache.GetAllEnvironments, func() []store.Environment {
+		return []store.Environment{
+			{Name: "global", Client: `{"timeout":"30s","proxy":"http://proxy:3128"}`},
+			{Name: "prod", Client: `{"timeout":"10s"}`},
+			{Name: "prod-eu", Parent: "prod", Client: `{"insecure-skip-verify":false}`},
+		}
+	})
+
+	env := Environment{Name: "prod-eu"}
+	profile := env.clientProfile()
+	assert.Equal(t, "10s", profile.Timeout)
+	assert.Equal(t, "http://proxy:3128", profile.Proxy)
+	assert.False(t, *profile.Insecure)
+}
@@ -1,16 +1,18 @@
 package models
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/mcastorina/poster/internal/store"
 )
 
 func (r *Request) ToStore() *store.Request {
-	headerStrings := []string{}
-	for _, header := range r.Headers {
-		headerStrings = append(headerStrings, header.String())
+	assertionStrings := []string{}
+	for _, assertion := range r.Assertions {
+		assertionStrings = append(assertionStrings, assertion.String())
 	}
 
 	return &store.Request{
@@ -19,16 +21,19 @@ func (r *Request) ToStore() *store.Request {
 		URL:         r.URL,
 		Environment: r.Environment.Name,
 		Body:        []byte(r.Body),
-		Headers:     strings.Join(headerStrings, "\n"),
+		Headers:     joinHeaders(r.Headers),
+		Assertions:  strings.Join(assertionStrings, "\n"),
+		Client:      joinClientProfile(r.Client),
+		Auth:        joinAuth(r.Auth),
 	}
 }
 func convertToRequest(s store.Request) Request {
-	headers := []Header{}
-	if len(s.Headers) > 0 {
-		headerStrings := strings.Split(s.Headers, "\n")
-		for _, headerString := range headerStrings {
-			keyValue := strings.SplitN(headerString, ": ", 2)
-			headers = append(headers, Header{Key: keyValue[0], Value: keyValue[1]})
+	assertions := []Assertion{}
+	if len(s.Assertions) > 0 {
+		for _, assertionString := range strings.Split(s.Assertions, "\n") {
+			if assertion, err := ParseAssertion(assertionString); err == nil {
+				assertions = append(assertions, assertion)
+			}
 		}
 	}
 	return Request{
@@ -37,16 +42,90 @@ func convertToRequest(s store.Request) Request {
 		URL:         s.URL,
 		Environment: Environment{Name: s.Environment},
 		Body:        string(s.Body),
-		Headers:     headers,
+		Headers:     splitHeaders(s.Headers),
+		Assertions:  assertions,
+		Client:      splitClientProfile(s.Client),
+		Auth:        splitAuth(s.Auth),
+	}
+}
+
+func (r *Response) ToStore() *store.Response {
+	return &store.Response{
+		ID:              r.ID,
+		Request:         r.Request.Name,
+		Environment:     r.Request.Environment.Name,
+		Method:          r.Request.Method,
+		URL:             r.Request.URL,
+		RequestHeaders:  joinHeaders(r.Request.Headers),
+		RequestBody:     []byte(r.Request.Body),
+		Proto:           r.Proto,
+		Status:          r.Status,
+		StatusCode:      r.StatusCode,
+		ResponseHeaders: joinHeaders(r.Headers),
+		ResponseBody:    []byte(r.Body),
+		Elapsed:         int64(r.Elapsed),
+		Time:            r.Time,
+		Baseline:        r.Baseline,
+	}
+}
+func convertToResponse(s store.Response) Response {
+	return Response{
+		ID: s.ID,
+		Request: Request{
+			Name:        s.Request,
+			Method:      s.Method,
+			URL:         s.URL,
+			Environment: Environment{Name: s.Environment},
+			Body:        string(s.RequestBody),
+			Headers:     splitHeaders(s.RequestHeaders),
+		},
+		Proto:      s.Proto,
+		Status:     s.Status,
+		StatusCode: s.StatusCode,
+		Headers:    splitHeaders(s.ResponseHeaders),
+		Body:       string(s.ResponseBody),
+		Elapsed:    time.Duration(s.Elapsed),
+		Time:       s.Time,
+		Baseline:   s.Baseline,
+	}
+}
+
+func (s *Suite) ToStore() *store.Suite {
+	return &store.Suite{
+		Name:        s.Name,
+		Environment: s.Environment.Name,
+		Requests:    strings.Join(s.Requests, "\n"),
+	}
+}
+func convertToSuite(s store.Suite) Suite {
+	requests := []string{}
+	if len(s.Requests) > 0 {
+		requests = strings.Split(s.Requests, "\n")
+	}
+	return Suite{
+		Name:        s.Name,
+		Environment: Environment{Name: s.Environment},
+		Requests:    requests,
 	}
 }
 
 func (e *Environment) ToStore() *store.Environment {
-	sEnv := store.Environment(*e)
-	return &sEnv
+	return &store.Environment{
+		Name:        e.Name,
+		Parent:      e.Parent,
+		Client:      joinClientProfile(e.Client),
+		Certificate: joinClientCertificate(e.Certificate),
+		Auth:        joinAuth(e.Auth),
+	}
 }
 func convertToEnvironment(s store.Environment) Environment {
-	return Environment(s)
+	return Environment{
+		Name:        s.Name,
+		Parent:      s.Parent,
+		Client:      splitClientProfile(s.Client),
+		Certificate: splitClientCertificate(s.Certificate),
+		Auth:        splitAuth(s.Auth),
+	}
 }
 
 func (v *Variable) ToStore() *store.Variable {
@@ -55,6 +134,11 @@ func (v *Variable) ToStore() *store.Variable {
 		Value:       v.Value,
 		Environment: v.Environment.Name,
 		Type:        v.Type,
+		Secret:      v.Secret,
+	}
+	if v.isExternal() {
+		// Keep secrets out of the database
+		sVariable.Value = ""
 	}
 	if v.Generator != nil {
 		switch v.Type {
@@ -64,6 +148,14 @@ func (v *Variable) ToStore() *store.Variable {
 			sVariable.Generator = fmt.Sprintf("%s:%s:%s",
 				v.Generator.RequestName, v.Generator.RequestEnvironment,
 				v.Generator.RequestPath)
+		case EnvType:
+			sVariable.Generator = v.Generator.Key
+		case DotenvType:
+			sVariable.Generator = v.Generator.Key + ":" + v.Generator.File
+		case PromptType:
+			sVariable.Generator = promptGenerator(v.Generator)
+		case OAuth2Type:
+			sVariable.Generator = oauth2Generator(v.Generator)
 		}
 		sVariable.Timeout = v.Generator.Timeout
 		sVariable.Last = v.Generator.LastGenerated
@@ -76,6 +168,7 @@ func convertToVariable(s store.Variable) Variable {
 		Value:       s.Value,
 		Environment: Environment{Name: s.Environment},
 		Type:        s.Type,
+		Secret:      s.Secret,
 	}
 	generator := &VariableGenerator{}
 	switch variable.Type {
@@ -86,6 +179,18 @@ func convertToVariable(s store.Variable) Variable {
 		generator.RequestName = namePath[0]
 		generator.RequestEnvironment = namePath[1]
 		generator.RequestPath = namePath[2]
+	case EnvType:
+		generator.Key = s.Generator
+	case DotenvType:
+		keyFile := strings.SplitN(s.Generator, ":", 2)
+		generator.Key = keyFile[0]
+		if len(keyFile) == 2 {
+			generator.File = keyFile[1]
+		}
+	case PromptType:
+		parsePromptGenerator(s.Generator, generator)
+	case OAuth2Type:
+		parseOAuth2Generator(s.Generator, generator)
 	case ConstType:
 		generator = nil
 	}
@@ -96,3 +201,122 @@ func convertToVariable(s store.Variable) Variable {
 	variable.Generator = generator
 	return variable
 }
+
+// joinHeaders stores headers as newline separated "Key: Value" lines
+func joinHeaders(headers []Header) string {
+	headerStrings := []string{}
+	for _, header := range headers {
+		headerStrings = append(headerStrings, header.String())
+	}
+	return strings.Join(headerStrings, "\n")
+}
+func splitHeaders(s string) []Header {
+	headers := []Header{}
+	if len(s) > 0 {
+		for _, headerString := range strings.Split(s, "\n") {
+			keyValue := strings.SplitN(headerString, ": ", 2)
+			if len(keyValue) < 2 {
+				keyValue = append(keyValue, "")
+			}
+			headers = append(headers, Header{Key: keyValue[0], Value: keyValue[1]})
+		}
+	}
+	return headers
+}
+
+func (s *Session) ToStore() []store.Cookie {
+	cookies := []store.Cookie{}
+	for _, cookie := range s.Cookies {
+		sCookie := store.Cookie{
+			Session:     s.Name,
+			Environment: s.Environment.Name,
+			Name:        cookie.Name,
+			Value:       cookie.Value,
+			Domain:      cookie.Domain,
+			Path:        cookie.Path,
+			HostOnly:    cookie.HostOnly,
+			Secure:      cookie.Secure,
+			HTTPOnly:    cookie.HTTPOnly,
+		}
+		if !cookie.Expires.IsZero() {
+			sCookie.Expires = cookie.Expires.Unix()
+		}
+		cookies = append(cookies, sCookie)
+	}
+	return cookies
+}
+func convertToCookie(s store.Cookie) Cookie {
+	cookie := Cookie{
+		Name:     s.Name,
+		Value:    s.Value,
+		Domain:   s.Domain,
+		Path:     s.Path,
+		HostOnly: s.HostOnly,
+		Secure:   s.Secure,
+		HTTPOnly: s.HTTPOnly,
+	}
+	if s.Expires != 0 {
+		cookie.Expires = time.Unix(s.Expires, 0)
+	}
+	return cookie
+}
+
+// joinClientProfile and splitClientProfile convert a client profile to and
+// from its JSON column, which is empty if no field is set
+func joinClientProfile(p ClientProfile) string {
+	if p.IsEmpty() {
+		return ""
+	}
+	data, _ := json.Marshal(p)
+	return string(data)
+}
+func splitClientProfile(s string) ClientProfile {
+	profile := ClientProfile{}
+	if s == "" {
+		return profile
+	}
+	if err := json.Unmarshal([]byte(s), &profile); err != nil {
+		log.Warnf("invalid client profile %q: %+v\n", s, err)
+	}
+	return profile
+}
+
+// joinClientCertificate and splitClientCertificate convert a client
+// certificate to and from its JSON column, which is empty if none is set
+func joinClientCertificate(c ClientCertificate) string {
+	if c.IsEmpty() {
+		return ""
+	}
+	data, _ := json.Marshal(c)
+	return string(data)
+}
+func splitClientCertificate(s string) ClientCertificate {
+	certificate := ClientCertificate{}
+	if s == "" {
+		return certificate
+	}
+	if err := json.Unmarshal([]byte(s), &certificate); err != nil {
+		log.Warnf("invalid client certificate %q: %+v\n", s, err)
+	}
+	return certificate
+}
+
+// joinAuth and splitAuth convert an auth to and from its JSON column, which
+// is empty if none is set
+func joinAuth(a Auth) string {
+	if a.IsEmpty() {
+		return ""
+	}
+	data, _ := json.Marshal(a)
+	return string(data)
+}
+func splitAuth(s string) Auth {
+	auth := Auth{}
+	if s == "" {
+		return auth
+	}
+	if err := json.Unmarshal([]byte(s), &auth); err != nil {
+		log.Warnf("invalid auth %q: %+v\n", s, err)
+	}
+	return auth
+}
@@ -0,0 +1,224 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const missingValue = "<missing>"
+
+// JSON keys that can be written as .key in a path
+var identifierRegexp = regexp.MustCompile(`^[A-Za-z_][\w-]*$`)
+
+// Difference is a value that differs between two responses. Path is
+// "status", "header NAME", "body" or a JSON path into the body.
+type Difference struct {
+	Path  string
+	Left  string
+	Right string
+}
+
+// DiffOptions lists the parts of the responses that are not compared, such as
+// timestamps and request IDs that change on every run.
+type DiffOptions struct {
+	// JSON paths, e.g. $.timestamp or $.items[*].id
+	IgnorePaths   []string
+	IgnoreHeaders []string
+}
+
+// DiffResponses compares the status, headers and body of two responses.
+// Bodies that are both JSON are compared structurally. The secrets of this
+// run are masked in both, like they are in the recorded responses.
+func DiffResponses(left, right Response, options DiffOptions) []Difference {
+	maskResponse(&left)
+	maskResponse(&right)
+	diffs := []Difference{}
+	if left.StatusCode != right.StatusCode {
+		diffs = append(diffs, Difference{
+			Path:  "status",
+			Left:  strconv.Itoa(left.StatusCode),
+			Right: strconv.Itoa(right.StatusCode),
+		})
+	}
+
+	ignoredHeaders := map[string]bool{}
+	for _, header := range options.IgnoreHeaders {
+		ignoredHeaders[http.CanonicalHeaderKey(header)] = true
+	}
+	leftHeaders := headerValues(left.Headers)
+	rightHeaders := headerValues(right.Headers)
+	for _, key := range unionKeys(leftHeaders, rightHeaders) {
+		if ignoredHeaders[key] {
+			continue
+		}
+		leftValue, leftOk := leftHeaders[key]
+		rightValue, rightOk := rightHeaders[key]
+		if !leftOk {
+			leftValue = missingValue
+		}
+		if !rightOk {
+			rightValue = missingValue
+		}
+		if leftValue != rightValue {
+			diffs = append(diffs, Difference{Path: "header " + key, Left: leftValue, Right: rightValue})
+		}
+	}
+
+	var leftBody, rightBody interface{}
+	leftErr := json.Unmarshal([]byte(left.Body), &leftBody)
+	rightErr := json.Unmarshal([]byte(right.Body), &rightBody)
+	if leftErr != nil || rightErr != nil {
+		if left.Body != right.Body {
+			diffs = append(diffs, Difference{Path: "body", Left: left.Body, Right: right.Body})
+		}
+		return diffs
+	}
+	ignored := []*regexp.Regexp{}
+	for _, path := range options.IgnorePaths {
+		ignored = append(ignored, compileIgnorePath(path))
+	}
+	return append(diffs, diffJSON("$", leftBody, rightBody, ignored)...)
+}
+
+// DiffReport returns the differences in the same format as
+// AssertionError.Report.
+func DiffReport(leftName, rightName string, diffs []Difference) string {
+	report := fmt.Sprintf("--- %s\n+++ %s\n", leftName, rightName)
+	for _, diff := range diffs {
+		report += fmt.Sprintf("@@ %s @@\n", diff.Path)
+		report += prefixLines("- ", diff.Left)
+		report += prefixLines("+ ", diff.Right)
+	}
+	return report
+}
+
+func diffJSON(path string, left, right interface{}, ignored []*regexp.Regexp) []Difference {
+	for _, re := range ignored {
+		if re.MatchString(path) {
+			return []Difference{}
+		}
+	}
+
+	leftMap, leftIsMap := left.(map[string]interface{})
+	rightMap, rightIsMap := right.(map[string]interface{})
+	if leftIsMap && rightIsMap {
+		diffs := []Difference{}
+		keys := map[string]bool{}
+		for key := range leftMap {
+			keys[key] = true
+		}
+		for key := range rightMap {
+			keys[key] = true
+		}
+		sortedKeys := []string{}
+		for key := range keys {
+			sortedKeys = append(sortedKeys, key)
+		}
+		sort.Strings(sortedKeys)
+		for _, key := range sortedKeys {
+			childPath := path + "." + key
+			if !identifierRegexp.MatchString(key) {
+				childPath = fmt.Sprintf("%s[%q]", path, key)
+			}
+			leftValue, leftOk := leftMap[key]
+			rightValue, rightOk := rightMap[key]
+			if !leftOk || !rightOk {
+				diffs = append(diffs, missingDifference(childPath, leftValue, leftOk, rightValue, rightOk, ignored)...)
+				continue
+			}
+			diffs = append(diffs, diffJSON(childPath, leftValue, rightValue, ignored)...)
+		}
+		return diffs
+	}
+
+	leftList, leftIsList := left.([]interface{})
+	rightList, rightIsList := right.([]interface{})
+	if leftIsList && rightIsList {
+		diffs := []Difference{}
+		for i := 0; i < len(leftList) || i < len(rightList); i++ {
+			childPath := fmt.Sprintf("%s[%d]", path, i)
+			if i >= len(leftList) || i >= len(rightList) {
+				var leftValue, rightValue interface{}
+				if i < len(leftList) {
+					leftValue = leftList[i]
+				}
+				if i < len(rightList) {
+					rightValue = rightList[i]
+				}
+				diffs = append(diffs, missingDifference(childPath, leftValue, i < len(leftList),
+					rightValue, i < len(rightList), ignored)...)
+				continue
+			}
+			diffs = append(diffs, diffJSON(childPath, leftList[i], rightList[i], ignored)...)
+		}
+		return diffs
+	}
+
+	if reflect.DeepEqual(left, right) {
+		return []Difference{}
+	}
+	return []Difference{{Path: path, Left: encodeJSON(left), Right: encodeJSON(right)}}
+}
+func missingDifference(path string, left interface{}, leftOk bool, right interface{}, rightOk bool, ignored []*regexp.Regexp) []Difference {
+	for _, re := range ignored {
+		if re.MatchString(path) {
+			return []Difference{}
+		}
+	}
+	diff := Difference{Path: path, Left: missingValue, Right: missingValue}
+	if leftOk {
+		diff.Left = encodeJSON(left)
+	}
+	if rightOk {
+		diff.Right = encodeJSON(right)
+	}
+	return []Difference{diff}
+}
+
+// compileIgnorePath converts a JSON path into a regular expression matching
+// the paths of DiffResponses. [*] matches any index, .* matches any key and
+// children of the path are ignored too.
+func compileIgnorePath(path string) *regexp.Regexp {
+	pattern := regexp.QuoteMeta(path)
+	pattern = strings.Replace(pattern, `\[\*\]`, `\[\d+\]`, -1)
+	pattern = strings.Replace(pattern, `\.\*`, `(\.[^.\[]+|\[[^\]]+\])`, -1)
+	return regexp.MustCompile("^" + pattern + `($|[.\[])`)
+}
+func encodeJSON(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
+func headerValues(headers []Header) map[string]string {
+	values := map[string]string{}
+	for _, header := range headers {
+		key := http.CanonicalHeaderKey(header.Key)
+		if value, ok := values[key]; ok {
+			values[key] = value + ", " + header.Value
+		} else {
+			values[key] = header.Value
+		}
+	}
+	return values
+}
+func unionKeys(left, right map[string]string) []string {
+	keys := []string{}
+	for key := range left {
+		keys = append(keys, key)
+	}
+	for key := range right {
+		if _, ok := left[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
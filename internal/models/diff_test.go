@@ -0,0 +1,97 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffResponses(t *testing.T) {
+	left := Response{
+		StatusCode: 200,
+		Headers:    []Header{{Key: "Date", Value: "Mon"}, {Key: "X-Env", Value: "staging"}},
+		Body:       `{"timestamp": 1, "items": [{"id": 1, "name": "a"}], "a b": true}`,
+	}
+	right := Response{
+		StatusCode: 500,
+		Headers:    []Header{{Key: "Date", Value: "Tue"}, {Key: "x-env", Value: "production"}},
+		Body:       `{"timestamp": 2, "items": [{"id": 2, "name": "b"}, {"id": 3}], "extra": null}`,
+	}
+
+	expected := []Difference{
+		{Path: "status", Left: "200", Right: "500"},
+		{Path: "header X-Env", Left: "staging", Right: "production"},
+		{Path: `$["a b"]`, Left: "true", Right: missingValue},
+		{Path: "$.extra", Left: missingValue, Right: "null"},
+		{Path: "$.items[0].name", Left: `"a"`, Right: `"b"`},
+		{Path: "$.items[1]", Left: missingValue, Right: `{"id":3}`},
+	}
+	actual := DiffResponses(left, right, DiffOptions{
+		IgnorePaths:   []string{"$.timestamp", "$.items[*].id"},
+		IgnoreHeaders: []string{"date"},
+	})
+	assert.Equal(t, expected, actual)
+
+	assert.Equal(t, []Difference{}, DiffResponses(left, left, DiffOptions{}))
+	expected = []Difference{{Path: "status", Left: "200", Right: "500"}}
+	actual = DiffResponses(left, right, DiffOptions{
+		IgnorePaths:   []string{"$"},
+		IgnoreHeaders: []string{"Date", "X-Env"},
+	})
+	assert.Equal(t, expected, actual)
+}
+func TestDiffResponsesText(t *testing.T) {
+	left := Response{StatusCode: 200, Body: "hello"}
+	right := Response{StatusCode: 200, Body: `{"hello": true}`}
+	expected := []Difference{{Path: "body", Left: "hello", Right: `{"hello": true}`}}
+	assert.Equal(t, expected, DiffResponses(left, right, DiffOptions{}))
+
+	report := "--- left\n+++ right\n@@ body @@\n- hello\n+ {\"hello\": true}\n"
+	assert.Equal(t, report, DiffReport("left", "right", expected))
+}
+func TestCompileIgnorePath(t *testing.T) {
+	matches := map[string][]string{
+		"$.timestamp":    {"$.timestamp", "$.timestamp.seconds", "$.timestamp[0]"},
+		"$.items[*].id":  {"$.items[0].id", "$.items[12].id"},
+		"$.*.updated":    {"$.a.updated", `$["a b"].updated`},
+		`$["a b"]`:       {`$["a b"]`},
+		"$.items[1]":     {"$.items[1]", "$.items[1].id"},
+		"$.meta.version": {"$.meta.version"},
+	}
+	for path, inputs := range matches {
+		re := compileIgnorePath(path)
+		for _, input := range inputs {
+			assert.True(t, re.MatchString(input), path+" "+input)
+		}
+	}
+	nonMatches := map[string][]string{
+		"$.timestamp":   {"$.timestamps", "$.a.timestamp"},
+		"$.items[*].id": {"$.items.id", "$.items[0].ids"},
+		"$.items[1]":    {"$.items[10]"},
+	}
+	for path, inputs := range nonMatches {
+		re := compileIgnorePath(path)
+		for _, input := range inputs {
+			assert.False(t, re.MatchString(input), path+" "+input)
+		}
+	}
+}
+func TestDiffResponsesSecrets(t *testing.T) {
+	rememberSecret("s3cret")
+	defer delete(secretValues, "s3cret")
+
+	// The baseline was recorded with the secret masked
+	baseline := Response{
+		StatusCode: 200,
+		Headers:    []Header{{Key: "X-Token", Value: SecretMask}},
+		Body:       `{"token": "` + SecretMask + `", "id": 1}`,
+	}
+	response := Response{
+		StatusCode: 200,
+		Headers:    []Header{{Key: "X-Token", Value: "s3cret"}},
+		Body:       `{"token": "s3cret", "id": 2}`,
+	}
+	expected := []Difference{{Path: "$.id", Left: "1", Right: "2"}}
+	assert.Equal(t, expected, DiffResponses(baseline, response, DiffOptions{}))
+	assert.Equal(t, "s3cret", response.Headers[0].Value)
+}
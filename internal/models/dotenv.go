@@ -0,0 +1,83 @@
+package models
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// isExternal returns whether the value of the variable is read from outside
+// of the database every time it is used
+func (v *Variable) isExternal() bool {
+	return v.Type == EnvType || v.Type == DotenvType || v.Type == PromptType
+}
+
+// lookupValue reads the value of an env, dotenv or prompt variable
+func (v *Variable) lookupValue() (string, error) {
+	if v.Type == PromptType {
+		return v.promptValue()
+	}
+	if v.Generator == nil {
+		return "", errorInvalidVariable
+	}
+	switch v.Type {
+	case EnvType:
+		value, ok := os.LookupEnv(v.Generator.Key)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", v.Generator.Key)
+		}
+		return value, nil
+	case DotenvType:
+		values, err := readDotenv(v.Generator.File)
+		if err != nil {
+			return "", err
+		}
+		value, ok := values[v.Generator.Key]
+		if !ok {
+			return "", fmt.Errorf("%s is not set in %s", v.Generator.Key, v.Generator.File)
+		}
+		return value, nil
+	}
+	return v.Value, nil
+}
+
+// readDotenv parses the KEY=VALUE lines of a .env file. Blank lines,
+// comments and the export keyword are ignored, and values may be quoted.
+func readDotenv(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", path, lineNumber)
+		}
+		key := strings.TrimSpace(parts[0])
+		value := strings.TrimSpace(parts[1])
+		if len(value) >= 2 && value[0] == value[len(value)-1] && (value[0] == '"' || value[0] == '\'') {
+			quote := value[0]
+			value = value[1 : len(value)-1]
+			if quote == '"' {
+				value = strings.NewReplacer(`\n`, "\n", `\"`, `"`, `\\`, `\`).Replace(value)
+			}
+		} else if i := strings.Index(value, " #"); i != -1 {
+			value = strings.TrimSpace(value[:i])
+		}
+		values[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
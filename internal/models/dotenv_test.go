@@ -0,0 +1,75 @@
+package models
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadDotenv(t *testing.T) {
+	dir, err := ioutil.TempDir("", "poster")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, ".env")
+	data := `# comment
+TOKEN=abc123
+export HOST = localhost:8080
+QUOTED="a \"b\"\nc"
+SINGLE='x\ny'
+TRAILING=value # comment
+
+EMPTY=
+`
+	assert.Nil(t, ioutil.WriteFile(path, []byte(data), 0644))
+
+	values, err := readDotenv(path)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{
+		"TOKEN":    "abc123",
+		"HOST":     "localhost:8080",
+		"QUOTED":   "a \"b\"\nc",
+		"SINGLE":   `x\ny`,
+		"TRAILING": "value",
+		"EMPTY":    "",
+	}, values)
+
+	assert.Nil(t, ioutil.WriteFile(path, []byte("TOKEN\n"), 0644))
+	_, err = readDotenv(path)
+	assert.NotNil(t, err)
+}
+func TestExternalVariables(t *testing.T) {
+	os.Setenv("POSTER_TEST_TOKEN", "secret")
+	defer os.Unsetenv("POSTER_TEST_TOKEN")
+
+	variable := Variable{
+		Name:        "token",
+		Value:       "secret",
+		Type:        EnvType,
+		Environment: Environment{Name: "local"},
+		Generator:   &VariableGenerator{Key: "POSTER_TEST_TOKEN"},
+	}
+	value, err := variable.lookupValue()
+	assert.Nil(t, err)
+	assert.Equal(t, "secret", value)
+
+	sVariable := variable.ToStore()
+	assert.Equal(t, "", sVariable.Value)
+	assert.Equal(t, variable.Generator.Key, convertToVariable(*sVariable).Generator.Key)
+
+	variable.Generator.Key = "POSTER_TEST_UNSET"
+	assert.NotNil(t, variable.GenerateValue())
+
+	variable = Variable{
+		Name:      "token",
+		Type:      DotenvType,
+		Generator: &VariableGenerator{Key: "TOKEN", File: "/path/to/.env"},
+	}
+	converted := convertToVariable(*variable.ToStore())
+	assert.Equal(t, variable.Generator.Key, converted.Generator.Key)
+	assert.Equal(t, variable.Generator.File, converted.Generator.File)
+	variable.Generator.File = ""
+	assert.Equal(t, errorInvalidVariable, variable.Validate())
+}
@@ -6,12 +6,37 @@ var (
 	errorInvalidRequest     = errors.New("Request object contains invalid fields")
 	errorInvalidEnvironment = errors.New("Environment object contains invalid fields")
 	errorInvalidVariable    = errors.New("Variable object contains invalid fields")
+	errorInvalidSuite       = errors.New("Suite object must contain at least one request")
 	errorInvalidMethod      = errors.New("The provided method is invalid")
 	errorInvalidType        = errors.New("The provided type is invalid")
-	errorInvalidCharacters  = errors.New("The provided variable name contains invalid characters")
+	errorInvalidCharacters  = errors.New("The provided variable name must start with a letter or _ and contain only letters, digits, _ and -")
+	errorInvalidAssertion   = errors.New("The provided assertion is invalid")
+	errorGlobalParent       = errors.New("The global environment cannot have a parent")
+	errorParentNotFound     = errors.New("The parent environment does not exist")
+	errorParentCycle        = errors.New("The parent environment inherits from this environment")
+	errorEnvironmentExists  = errors.New("The environment already exists")
+	errorInvalidTimeout     = errors.New("The provided timeout is not a duration like 30s")
+	errorInvalidProxy       = errors.New("The provided proxy is not a valid URL")
+	errorInvalidCACert      = errors.New("The CA certificate file does not contain PEM certificates")
+	errorInvalidRedirects   = errors.New("The maximum number of redirects cannot be negative")
+	errorInvalidCertificate = errors.New("The client certificate needs a cert and key, or a PKCS#12 file")
+	errorInvalidAuthType    = errors.New("The auth type should be none, basic, bearer, digest or aws-sigv4")
+	errorInvalidAuth        = errors.New("The auth is missing credentials required by its type")
+	errorInvalidOAuth2      = errors.New("The oauth2 settings are missing a token URL or credentials required by the grant")
+	errorInvalidGrantType   = errors.New("The grant type should be client_credentials, password or refresh_token")
 
 	errorCreateRequestFailed    = errors.New("Could not create a HTTP request")
 	errorRequestFailed          = errors.New("Request failed")
 	errorInvalidURL             = errors.New("Request URL is invalid")
 	errorGenerateVariableFailed = errors.New("Failed to generate variable")
+	errorSuiteStepFailed        = errors.New("Suite step failed")
+	errorSuiteBodyOverride      = errors.New("The body of a suite cannot be overridden")
+	errorResourceNotFound       = errors.New("No request or suite found with that name")
+	errorEnvironmentHasChildren = errors.New("The environment is the parent of another environment")
+	errorMissingSecretKey       = errors.New("Secret variables need a key, set POSTER_KEY_FILE or POSTER_PASSPHRASE")
+	errorInvalidSecret          = errors.New("The secret value is not encrypted")
+	errorDecryptSecretFailed    = errors.New("Could not decrypt secret, check POSTER_KEY_FILE or POSTER_PASSPHRASE")
+	errorNoAnswer               = errors.New("No answer was given to the prompt")
+	errorLoadCertificateFailed  = errors.New("Could not load the client certificate")
+	errorUnsupportedDigest      = errors.New("The digest algorithm of the server is not supported")
 )
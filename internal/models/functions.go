@@ -0,0 +1,234 @@
+package models
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// builtinFunction computes the value of :$name(args...). Arguments have
+// their variables and function calls replaced before the call.
+type builtinFunction func(args []string) (string, error)
+
+var builtinFunctions = map[string]builtinFunction{
+	"uuid":       uuidFunction,
+	"timestamp":  timestampFunction,
+	"isotime":    isotimeFunction,
+	"randint":    randintFunction,
+	"randstring": randstringFunction,
+	"base64":     base64Function,
+	"urlencode":  urlencodeFunction,
+	"sha256":     sha256Function,
+	"hmac":       hmacFunction,
+}
+
+// splitFunctionArgs splits the arguments of the parenthesis at input[open]
+// on commas, and returns the index after the closing parenthesis. Nested
+// parentheses and characters escaped with a backslash do not end or split
+// the arguments.
+func splitFunctionArgs(input string, open int) (int, []string, bool) {
+	args := []string{}
+	depth := 0
+	argStart := open + 1
+	for i := open + 1; i < len(input); i++ {
+		switch input[i] {
+		case '\\':
+			i++
+		case '(':
+			depth++
+		case ')':
+			if depth > 0 {
+				depth--
+				continue
+			}
+			if arg := input[argStart:i]; arg != "" || len(args) > 0 {
+				args = append(args, arg)
+			}
+			return i + 1, args, true
+		case ',':
+			if depth == 0 {
+				args = append(args, input[argStart:i])
+				argStart = i + 1
+			}
+		}
+	}
+	return 0, nil, false
+}
+
+// unescapeFunctionArg removes the backslashes of escaped commas, parentheses
+// and backslashes outside of nested parentheses, which belong to nested
+// calls. Escaped colons are kept for tokenize.
+func unescapeFunctionArg(arg string) string {
+	output := []byte{}
+	depth := 0
+	for i := 0; i < len(arg); i++ {
+		switch c := arg[i]; {
+		case c == '\\' && i+1 < len(arg) && strings.IndexByte(",()\\", arg[i+1]) != -1:
+			if depth > 0 {
+				output = append(output, c)
+			}
+			i++
+			output = append(output, arg[i])
+			continue
+		case c == '(':
+			depth++
+		case c == ')' && depth > 0:
+			depth--
+		}
+		output = append(output, arg[i])
+	}
+	return string(output)
+}
+
+// evaluate computes the value of a function token, replacing the variables
+// and function calls of its arguments in environment e
+func (t *token) evaluate(e *Environment) (string, error) {
+	args := []string{}
+	for _, arg := range t.rawArgs {
+		args = append(args, e.ReplaceVariables(unescapeFunctionArg(arg)))
+	}
+	value, err := t.function(args)
+	if err != nil {
+		return "", fmt.Errorf("$%s: %+v", t.name, err)
+	}
+	return value, nil
+}
+func checkArgCount(args []string, min, max int) error {
+	if len(args) < min || len(args) > max {
+		if min == max {
+			return fmt.Errorf("expected %d arguments, got %d", min, len(args))
+		}
+		return fmt.Errorf("expected %d to %d arguments, got %d", min, max, len(args))
+	}
+	return nil
+}
+
+func uuidFunction(args []string) (string, error) {
+	if err := checkArgCount(args, 0, 0); err != nil {
+		return "", err
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	// Version 4, variant 10
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+func timestampFunction(args []string) (string, error) {
+	if err := checkArgCount(args, 0, 0); err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(time.Now().Unix(), 10), nil
+}
+func isotimeFunction(args []string) (string, error) {
+	if err := checkArgCount(args, 0, 0); err != nil {
+		return "", err
+	}
+	return time.Now().UTC().Format(time.RFC3339), nil
+}
+
+// randintFunction returns a random integer between the arguments, inclusive,
+// or between 0 and 1000 without arguments
+func randintFunction(args []string) (string, error) {
+	if err := checkArgCount(args, 0, 2); err != nil {
+		return "", err
+	}
+	if len(args) == 1 {
+		return "", fmt.Errorf("expected 0 or 2 arguments, got 1")
+	}
+	min, max := int64(0), int64(1000)
+	if len(args) == 2 {
+		var err error
+		if min, err = strconv.ParseInt(args[0], 10, 64); err != nil {
+			return "", err
+		}
+		if max, err = strconv.ParseInt(args[1], 10, 64); err != nil {
+			return "", err
+		}
+		if min > max {
+			return "", fmt.Errorf("%d is greater than %d", min, max)
+		}
+	}
+	// The range may not fit in an int64, e.g. for the full int64 range
+	size := new(big.Int).Sub(big.NewInt(max), big.NewInt(min))
+	size.Add(size, big.NewInt(1))
+	n, err := rand.Int(rand.Reader, size)
+	if err != nil {
+		return "", err
+	}
+	return n.Add(n, big.NewInt(min)).String(), nil
+}
+
+// maxRandstringLength is the longest string randstringFunction returns
+const maxRandstringLength = 4096
+
+// randstringFunction returns random letters and digits, 16 without an
+// argument
+func randstringFunction(args []string) (string, error) {
+	if err := checkArgCount(args, 0, 1); err != nil {
+		return "", err
+	}
+	length := 16
+	if len(args) == 1 {
+		var err error
+		if length, err = strconv.Atoi(args[0]); err != nil {
+			return "", err
+		}
+		if length < 0 {
+			return "", fmt.Errorf("negative length %d", length)
+		}
+		if length > maxRandstringLength {
+			return "", fmt.Errorf("length %d is greater than %d", length, maxRandstringLength)
+		}
+	}
+	const chars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	output := make([]byte, length)
+	for i := range output {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(chars))))
+		if err != nil {
+			return "", err
+		}
+		output[i] = chars[n.Int64()]
+	}
+	return string(output), nil
+}
+func base64Function(args []string) (string, error) {
+	if err := checkArgCount(args, 1, 1); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString([]byte(args[0])), nil
+}
+func urlencodeFunction(args []string) (string, error) {
+	if err := checkArgCount(args, 1, 1); err != nil {
+		return "", err
+	}
+	return url.QueryEscape(args[0]), nil
+}
+func sha256Function(args []string) (string, error) {
+	if err := checkArgCount(args, 1, 1); err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(args[0]))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// hmacFunction returns the hex encoded HMAC-SHA256 of the message, the
+// second argument, with the key, the first argument
+func hmacFunction(args []string) (string, error) {
+	if err := checkArgCount(args, 2, 2); err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, []byte(args[0]))
+	mac.Write([]byte(args[1]))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
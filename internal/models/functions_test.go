@@ -0,0 +1,83 @@
+package models
+
+import (
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/bouk/monkey"
+	"github.com/mcastorina/poster/internal/cache"
+	"github.com/mcastorina/poster/internal/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplaceFunctions(t *testing.T) {
+	defer monkey.UnpatchAll()
+	monkey.Patch(cache.GetVariablesByEnvironment, func(environment string) []store.Variable {
+		return []store.Variable{
+			{Name: "user", Value: "alice", Environment: "local"},
+			{Name: "pass", Value: "s3cret", Environment: "local"},
+		}
+	})
+	env := Environment{Name: "local"}
+
+	tests := map[string]string{
+		"Basic :$base64(:user::pass)":    "Basic YWxpY2U6czNjcmV0",
+		":$urlencode(a b&c)":             "a+b%26c",
+		":$base64(a\\,b\\)) :user":       "YSxiKQ== alice",
+		":$sha256()x":                    ":$sha256()x",
+		":$sha256(abc)":                  "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+		":$base64(:$urlencode(a\\,b c))": "YSUyQ2IrYw==",
+		":$hmac(key,The quick brown fox jumps over the lazy dog)": "f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8",
+		":$unknown(:user) :$base64(open":                          ":$unknown(alice) :$base64(open",
+		"localhost:8080/:$randstring(0)end":                       "localhost:8080/end",
+	}
+	for input, expected := range tests {
+		assert.Equal(t, expected, env.ReplaceVariables(input), input)
+	}
+
+	uuid := env.ReplaceVariables(":$uuid")
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), uuid)
+	assert.NotEqual(t, uuid, env.ReplaceVariables(":$uuid"))
+
+	for i := 0; i < 20; i++ {
+		n, err := strconv.Atoi(env.ReplaceVariables(":$randint(-2,2)"))
+		assert.Nil(t, err)
+		assert.True(t, -2 <= n && n <= 2)
+	}
+	assert.Equal(t, 12, len(env.ReplaceVariables(":$randstring(12)")))
+	assert.Regexp(t, regexp.MustCompile(`^\d{4}-\d\d-\d\dT\d\d:\d\d:\d\dZ$`), env.ReplaceVariables(":$isotime"))
+}
+func TestFunctionArgs(t *testing.T) {
+	tokens := tokenize(`x:$hmac(a\,b,:$base64(c\,d),(e,f))y`)
+	assert.Equal(t, 3, len(tokens))
+	call := tokens[1]
+	assert.Equal(t, functionToken, call.kind)
+	assert.Equal(t, []string{`a\,b`, `:$base64(c\,d)`, `(e,f)`}, call.rawArgs)
+	assert.Equal(t, "a,b", unescapeFunctionArg(call.rawArgs[0]))
+	assert.Equal(t, `:$base64(c\,d)`, unescapeFunctionArg(call.rawArgs[1]))
+	assert.Equal(t, `a\:b`, unescapeFunctionArg(`a\:b`))
+
+	_, err := randintFunction([]string{"5", "1"})
+	assert.NotNil(t, err)
+	// Ranges wider than an int64 do not overflow
+	for _, args := range [][]string{
+		{"0", "9223372036854775807"},
+		{"-9223372036854775808", "9223372036854775807"},
+		{"9223372036854775807", "9223372036854775807"},
+	} {
+		value, err := randintFunction(args)
+		assert.Nil(t, err)
+		n, err := strconv.ParseInt(value, 10, 64)
+		assert.Nil(t, err)
+		min, _ := strconv.ParseInt(args[0], 10, 64)
+		assert.True(t, n >= min)
+	}
+	value, err := randstringFunction([]string{"4096"})
+	assert.Nil(t, err)
+	assert.Equal(t, 4096, len(value))
+	_, err = randstringFunction([]string{"9999999999"})
+	assert.NotNil(t, err)
+	_, err = uuidFunction([]string{"x"})
+	assert.NotNil(t, err)
+}
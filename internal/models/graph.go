@@ -0,0 +1,149 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mcastorina/poster/internal/cache"
+)
+
+// DependencyNode is a request and the variables it uses in an environment.
+// Missing is set when the request or environment does not exist.
+type DependencyNode struct {
+	Request     string
+	Environment string
+	Missing     bool
+	Variables   []VariableDependency
+}
+
+// VariableDependency is a variable used by a request. Request is the node of
+// the generator request of request variables, and nil for other types.
+type VariableDependency struct {
+	Variable Variable
+	Request  *DependencyNode
+}
+
+// DependencyCycleError is returned when generating the variables of a request
+// would run the request again. Chain lists the requests and variables that
+// lead back to the first request.
+type DependencyCycleError struct {
+	Chain []string
+}
+
+func (e *DependencyCycleError) Error() string {
+	return "dependency cycle: " + strings.Join(e.Chain, " -> ")
+}
+
+// DependencyGraph returns the variables the request depends on in
+// environment e, following request variables to their generator requests.
+func (r *Request) DependencyGraph(e Environment) (*DependencyNode, error) {
+	return r.dependencyGraph(e, []string{}, map[string]bool{})
+}
+func (r *Request) dependencyGraph(e Environment, chain []string, visiting map[string]bool) (*DependencyNode, error) {
+	key := r.Name + "@" + e.Name
+	chain = append(chain, key)
+	if visiting[key] {
+		return nil, &DependencyCycleError{Chain: chain}
+	}
+	visiting[key] = true
+	defer delete(visiting, key)
+
+	node := &DependencyNode{Request: r.Name, Environment: e.Name}
+	seen := map[string]bool{}
+	for _, variable := range e.GetVariablesInRequest(r) {
+		if seen[variable.Name] {
+			continue
+		}
+		seen[variable.Name] = true
+		dependency := VariableDependency{Variable: variable}
+		if variable.Type == RequestType && variable.Generator != nil {
+			child, err := generatorDependencyGraph(variable, append(chain, ":"+variable.Name), visiting)
+			if err != nil {
+				return nil, err
+			}
+			dependency.Request = child
+		}
+		node.Variables = append(node.Variables, dependency)
+	}
+	return node, nil
+}
+func generatorDependencyGraph(v Variable, chain []string, visiting map[string]bool) (*DependencyNode, error) {
+	node := &DependencyNode{
+		Request:     v.Generator.RequestName,
+		Environment: v.Generator.RequestEnvironment,
+		Missing:     true,
+	}
+	sRequest, err := cache.GetRequestByName(v.Generator.RequestName)
+	if err != nil {
+		return node, nil
+	}
+	sEnv, err := cache.GetEnvironmentByName(v.Generator.RequestEnvironment)
+	if err != nil {
+		return node, nil
+	}
+	request := convertToRequest(sRequest)
+	return request.dependencyGraph(convertToEnvironment(sEnv), chain, visiting)
+}
+
+// Text returns the graph as an indented tree
+func (n *DependencyNode) Text() string {
+	return n.String() + "\n" + n.text("")
+}
+func (n *DependencyNode) text(indent string) string {
+	output := ""
+	for i, dependency := range n.Variables {
+		branch, childIndent := "├── ", "│   "
+		if i == len(n.Variables)-1 {
+			branch, childIndent = "└── ", "    "
+		}
+		v := dependency.Variable
+		output += fmt.Sprintf("%s%s:%s (%s) [%s]\n", indent, branch, v.Name, v.Environment.Name, v.Type)
+		if dependency.Request != nil {
+			output += indent + childIndent + "└── " + dependency.Request.String() + "\n"
+			output += dependency.Request.text(indent + childIndent + "    ")
+		}
+	}
+	return output
+}
+func (n *DependencyNode) String() string {
+	if n.Missing {
+		return fmt.Sprintf("%s (%s) <not found>", n.Request, n.Environment)
+	}
+	return fmt.Sprintf("%s (%s)", n.Request, n.Environment)
+}
+
+// DOT returns the graph in the Graphviz DOT language. Requests are drawn as
+// boxes and variables as ellipses.
+func (n *DependencyNode) DOT() string {
+	lines := []string{}
+	seen := map[string]bool{}
+	add := func(line string) {
+		if !seen[line] {
+			seen[line] = true
+			lines = append(lines, "  "+line)
+		}
+	}
+	var walk func(node *DependencyNode)
+	walk = func(node *DependencyNode) {
+		requestID := fmt.Sprintf("%q", node.Request+"@"+node.Environment)
+		style := "shape=box"
+		if node.Missing {
+			style += ", style=dashed"
+		}
+		add(fmt.Sprintf("%s [%s];", requestID, style))
+		for _, dependency := range node.Variables {
+			v := dependency.Variable
+			variableID := fmt.Sprintf("%q", ":"+v.Name+"@"+v.Environment.Name)
+			add(fmt.Sprintf("%s [shape=ellipse, label=%q];", variableID,
+				fmt.Sprintf(":%s (%s)\n%s", v.Name, v.Environment.Name, v.Type)))
+			add(fmt.Sprintf("%s -> %s;", requestID, variableID))
+			if dependency.Request != nil {
+				walk(dependency.Request)
+				add(fmt.Sprintf("%s -> %q;", variableID,
+					dependency.Request.Request+"@"+dependency.Request.Environment))
+			}
+		}
+	}
+	walk(n)
+	return fmt.Sprintf("digraph %q {\n%s\n}\n", n.Request, strings.Join(lines, "\n"))
+}
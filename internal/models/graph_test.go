@@ -0,0 +1,75 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/bouk/monkey"
+	"github.com/mcastorina/poster/internal/cache"
+	"github.com/mcastorina/poster/internal/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func patchDependencies(requests map[string]string, variables []store.Variable) {
+	monkey.Patch(cache.GetVariablesByEnvironment, func(environment string) []store.Variable {
+		result := []store.Variable{}
+		for _, variable := range variables {
+			if variable.Environment == environment {
+				result = append(result, variable)
+			}
+		}
+		return result
+	})
+	monkey.Patch(cache.GetRequestByName, func(name string) (store.Request, error) {
+		url, ok := requests[name]
+		if !ok {
+			return store.Request{}, store.ErrorRequestNotFound
+		}
+		return store.Request{Name: name, Method: "GET", URL: url, Environment: "local"}, nil
+	})
+	monkey.Patch(cache.GetEnvironmentByName, func(name string) (store.Environment, error) {
+		return store.Environment{Name: name}, nil
+	})
+}
+
+func TestDependencyGraph(t *testing.T) {
+	defer monkey.UnpatchAll()
+	patchDependencies(map[string]string{
+		"get-token": ":host/token",
+	}, []store.Variable{
+		{Name: "host", Value: "localhost", Environment: "local", Type: ConstType},
+		{Name: "token", Environment: "local", Type: RequestType, Generator: "get-token:local:$.token"},
+		{Name: "missing", Environment: "local", Type: RequestType, Generator: "nope:local:"},
+	})
+
+	request := Request{Name: "check", URL: ":host/check?t=:token&m=:missing&t2=:token"}
+	node, err := request.DependencyGraph(Environment{Name: "local"})
+	assert.Nil(t, err)
+	expected := `check (local)
+├── :host (local) [const]
+├── :token (local) [request]
+│   └── get-token (local)
+│       └── :host (local) [const]
+└── :missing (local) [request]
+    └── nope (local) <not found>
+`
+	assert.Equal(t, expected, node.Text())
+}
+func TestDependencyGraphCycle(t *testing.T) {
+	defer monkey.UnpatchAll()
+	patchDependencies(map[string]string{
+		"a": "localhost/:b-token",
+		"b": "localhost/:a-token",
+	}, []store.Variable{
+		{Name: "a-token", Environment: "local", Type: RequestType, Generator: "a:local:"},
+		{Name: "b-token", Environment: "local", Type: RequestType, Generator: "b:local:"},
+	})
+
+	request := Request{Name: "a", URL: "localhost/:b-token"}
+	_, err := request.DependencyGraph(Environment{Name: "local"})
+	assert.Equal(t, &DependencyCycleError{
+		Chain: []string{"a@local", ":b-token", "b@local", ":a-token", "a@local"},
+	}, err)
+
+	_, err = request.RunEnv(Environment{Name: "local"})
+	assert.IsType(t, &DependencyCycleError{}, err)
+}
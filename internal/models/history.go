@@ -0,0 +1,148 @@
+package models
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/mcastorina/poster/internal/cache"
+)
+
+// Response is a recorded run of a request. Request holds the request as it
+// was sent, with every variable resolved.
+type Response struct {
+	ID         int64
+	Request    Request
+	Proto      string
+	Status     string
+	StatusCode int
+	Headers    []Header
+	Body       string
+	Elapsed    time.Duration
+	Time       time.Time
+	Baseline   bool
+}
+
+var historyDisabled bool
+
+// DisableHistory stops runs from being recorded
+func DisableHistory() {
+	historyDisabled = true
+}
+
+var historyMaxAge time.Duration
+var historyMaxEntries int
+
+// SetHistoryRetention makes every recorded run prune the history with
+// PruneHistory(maxAge, maxEntries)
+func SetHistoryRetention(maxAge time.Duration, maxEntries int) {
+	historyMaxAge = maxAge
+	historyMaxEntries = maxEntries
+}
+
+// ReadResponse converts resp into a Response. The body of resp is read and
+// replaced so it can still be used by the caller.
+func ReadResponse(resp *http.Response) (Response, error) {
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		log.Errorf("%+v\n", err)
+		return Response{}, errorRequestFailed
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	headers := []Header{}
+	keys := []string{}
+	for key := range resp.Header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, value := range resp.Header[key] {
+			headers = append(headers, Header{Key: key, Value: value})
+		}
+	}
+	return Response{
+		Proto:      resp.Proto,
+		Status:     resp.Status,
+		StatusCode: resp.StatusCode,
+		Headers:    headers,
+		Body:       string(body),
+	}, nil
+}
+
+// recordResponse saves the response of the resolved request r to the
+// history and returns its body.
+func recordResponse(name string, r Request, resp *http.Response, elapsed time.Duration) ([]byte, error) {
+	response, err := ReadResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+	if historyDisabled {
+		return []byte(response.Body), nil
+	}
+
+	r.Name = name
+	response.Request = r
+	response.Elapsed = elapsed
+	response.Time = time.Now()
+	maskResponse(&response)
+	if err := response.Save(); err != nil {
+		// Losing history should not fail the run
+		log.Warnf("could not record response: %+v\n", err)
+	}
+	// Prune before the caller can exit, which it does on failed runs
+	if err := PruneHistory(historyMaxAge, historyMaxEntries); err != nil {
+		log.Warnf("could not prune history: %+v\n", err)
+	}
+	return []byte(response.Body), nil
+}
+
+func (r *Response) Save() error {
+	return cache.SaveResponse(r.ToStore())
+}
+
+// MarkBaseline makes the response the one compared against by
+// run --compare-baseline for its request and environment.
+func (r *Response) MarkBaseline() error {
+	if err := cache.MarkBaseline(r.ToStore()); err != nil {
+		return err
+	}
+	r.Baseline = true
+	return nil
+}
+
+// PruneHistory deletes responses older than maxAge and all but the latest
+// maxEntries responses of each request. Zero values disable the respective
+// limit.
+func PruneHistory(maxAge time.Duration, maxEntries int) error {
+	if maxAge > 0 {
+		if err := cache.DeleteResponsesBefore(time.Now().Add(-maxAge)); err != nil {
+			return err
+		}
+	}
+	if maxEntries > 0 {
+		if err := cache.DeleteResponsesExceptLatest(maxEntries); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// maskResponse removes the secret values of this run from the response
+func maskResponse(r *Response) {
+	r.Request.URL = maskSecrets(r.Request.URL)
+	r.Request.Body = maskSecrets(r.Request.Body)
+	r.Request.Headers = maskHeaders(r.Request.Headers)
+	r.Headers = maskHeaders(r.Headers)
+	r.Body = maskSecrets(r.Body)
+}
+func maskHeaders(headers []Header) []Header {
+	masked := []Header{}
+	for _, header := range headers {
+		masked = append(masked, Header{Key: header.Key, Value: maskSecrets(header.Value)})
+	}
+	return masked
+}
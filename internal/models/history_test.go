@@ -0,0 +1,47 @@
+package models
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistoryRetention(t *testing.T) {
+	SetHistoryRetention(0, 2)
+	defer SetHistoryRetention(0, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	env := Environment{Name: "history-retention"}
+	assert.Nil(t, env.Save())
+	assertion, _ := ParseAssertion("status 2xx")
+	request := Request{Name: "history-failing", Method: "GET", URL: server.URL, Environment: env,
+		Assertions: []Assertion{assertion}}
+	assert.Nil(t, request.Save())
+
+	// Failed runs are pruned too, before the caller exits
+	ids := []int64{}
+	for i := 0; i < 4; i++ {
+		_, err := request.Run()
+		assert.IsType(t, &AssertionError{}, err)
+		ids = append(ids, GetResponsesByRequest(request.Name)[0].ID)
+	}
+	responses := GetResponsesByRequest(request.Name)
+	assert.Equal(t, 2, len(responses))
+	assert.Equal(t, ids[3], responses[0].ID)
+	assert.Equal(t, ids[2], responses[1].ID)
+
+	// Baselines are kept and not counted
+	assert.Nil(t, responses[1].MarkBaseline())
+	for i := 0; i < 2; i++ {
+		request.Run()
+	}
+	responses = GetResponsesByRequest(request.Name)
+	assert.Equal(t, 3, len(responses))
+	assert.Equal(t, ids[2], responses[2].ID)
+	assert.True(t, responses[2].Baseline)
+}
@@ -0,0 +1,111 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mcastorina/poster/internal/cache"
+)
+
+// LintWarning is a placeholder in a part of a request, like the URL or a
+// header, that is probably not replaced as intended
+type LintWarning struct {
+	Request string
+	Field   string
+	Message string
+}
+
+func (w LintWarning) String() string {
+	return fmt.Sprintf("request/%s: %s: %s", w.Request, w.Field, w.Message)
+}
+
+// Lint checks the placeholders of the request against the variables of all
+// environments
+func (r *Request) Lint() []LintWarning {
+	defined := make(map[string]bool)
+	for _, variable := range cache.GetAllVariables() {
+		defined[variable.Name] = true
+	}
+
+	warnings := []LintWarning{}
+	for _, field := range r.fields() {
+		for _, message := range lintPlaceholders(field[1], defined) {
+			warnings = append(warnings, LintWarning{Request: r.Name, Field: field[0], Message: message})
+		}
+	}
+	return warnings
+}
+
+// fields returns the parts of the request that may contain placeholders,
+// as pairs of a description and the value
+func (r *Request) fields() [][2]string {
+	fields := [][2]string{
+		{"method", r.Method},
+		{"url", r.URL},
+	}
+	for _, header := range r.Headers {
+		fields = append(fields, [2]string{"header " + header.Key, header.Key + ": " + header.Value})
+	}
+	fields = append(fields, [2]string{"body", r.Body})
+	for _, assertion := range r.Assertions {
+		fields = append(fields, [2]string{"assertion", assertion.Value})
+	}
+	for _, value := range r.Auth.values() {
+		fields = append(fields, [2]string{"auth", value})
+	}
+	return fields
+}
+
+// lintPlaceholders returns a message for every variable that is not defined,
+// and every unknown function
+func lintPlaceholders(input string, defined map[string]bool) []string {
+	messages := []string{}
+	walkTokens(input, func(t token) {
+		switch t.kind {
+		case variableToken:
+			if defined[t.name] {
+				return
+			}
+			if prefix := definedPrefix(t.name, defined); prefix != "" && !t.braced {
+				messages = append(messages, fmt.Sprintf(
+					"%s is not a variable, but :%s is (write :{%s}%s)",
+					t.text, prefix, prefix, strings.TrimPrefix(t.name, prefix)))
+				return
+			}
+			messages = append(messages, fmt.Sprintf(
+				"%s is not a variable in any environment (write \\%s if it is not a placeholder)",
+				t.text, t.text))
+		case textToken:
+			for _, name := range unknownFunctions(t.text) {
+				messages = append(messages, fmt.Sprintf(":$%s is not a built-in function", name))
+			}
+		}
+	})
+	return messages
+}
+
+// definedPrefix returns the longest defined variable that name starts with,
+// followed by a hyphen. Before placeholders were tokenized, :port-name was
+// replaced by the value of port.
+func definedPrefix(name string, defined map[string]bool) string {
+	for i := strings.LastIndex(name, "-"); i > 0; i = strings.LastIndex(name[:i], "-") {
+		if defined[name[:i]] {
+			return name[:i]
+		}
+	}
+	return ""
+}
+
+// unknownFunctions returns the names following :$ in text, which tokenize
+// only reads as a call if the function exists
+func unknownFunctions(text string) []string {
+	names := []string{}
+	for i := strings.Index(text, ":$"); i != -1; i = strings.Index(text, ":$") {
+		text = text[i+2:]
+		end := scanName(text, 0)
+		if end > 0 {
+			names = append(names, text[:end])
+		}
+	}
+	return names
+}
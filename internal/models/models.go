@@ -8,7 +8,6 @@ import (
 	"net/http"
 	"net/url"
 	"os/exec"
-	"regexp"
 	"sort"
 	"strings"
 	"time"
@@ -21,8 +20,10 @@ const (
 	ConstType   = "const"
 	RequestType = "request"
 	ScriptType  = "script"
-
-	variableRegexp = `:([\w-]*)\b`
+	EnvType     = "env"
+	DotenvType  = "dotenv"
+	PromptType  = "prompt"
+	OAuth2Type  = "oauth2"
 )
 
 type Resource interface {
@@ -36,6 +37,7 @@ type Runnable interface {
 	UpdateHeaders(headers []Header) error
 	UpdateBody(body string) error
 	UpdateVariables(variables []Variable) error
+	UpdateAssertions(assertions []Assertion) error
 }
 
 // Header
@@ -50,36 +52,143 @@ func (h *Header) String() string {
 
 // Request
 type Request struct {
-	Name        string      `yaml:"name"`
-	Method      string      `yaml:"method"`
-	URL         string      `yaml:"url"`
-	Environment Environment `yaml:"environment"`
-	Body        string      `yaml:"body"`
-	Headers     []Header    `yaml:"headers"`
+	Name        string        `yaml:"name"`
+	Method      string        `yaml:"method"`
+	URL         string        `yaml:"url"`
+	Environment Environment   `yaml:"environment"`
+	Body        string        `yaml:"body"`
+	Headers     []Header      `yaml:"headers"`
+	Assertions  []Assertion   `yaml:"assertions,omitempty"`
+	Client      ClientProfile `yaml:"client,omitempty"`
+	Auth        Auth          `yaml:"auth,omitempty"`
 }
 
 var overrideVariables []Variable
 
 func (r *Request) Run() (*http.Response, error) {
-	// TODO: Check for dependency cycles
 	return r.RunEnv(r.Environment)
 }
 func (r *Request) RunEnv(e Environment) (*http.Response, error) {
+	// Check for dependency cycles before generating any variables
+	if _, err := r.DependencyGraph(e); err != nil {
+		return nil, err
+	}
+	if strictMode {
+		if undefined := r.UndefinedVariables(e); len(undefined) > 0 {
+			return nil, &UndefinedVariablesError{Request: r.Name, Environment: e.Name, Variables: undefined}
+		}
+	}
+	resolved, err := r.Resolve(e)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create request
+	req, err := resolved.newHTTPRequest()
+	if err != nil {
+		return nil, err
+	}
+	resolved.Auth.apply(req, resolved.Body)
+
+	// Send request and get response
+	client, err := e.clientProfile().merge(resolved.Client).merge(clientOverride).newClient(&e)
+	if err != nil {
+		return nil, err
+	}
+	session := currentSession(e)
+	if session != nil {
+		client = withJar(client, session)
+	}
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		log.Errorf("%+v\n", err)
+		return nil, errorRequestFailed
+	}
+	if challenge := digestChallenge(resp); challenge != nil && resolved.Auth.Type == AuthDigest {
+		// Send the request again with the answer to the challenge
+		resp.Body.Close()
+		if req, err = resolved.newHTTPRequest(); err != nil {
+			return nil, err
+		}
+		if err := resolved.Auth.applyDigest(req, resolved.Body, challenge); err != nil {
+			log.Errorf("%+v\n", err)
+			return nil, err
+		}
+		if resp, err = client.Do(req); err != nil {
+			log.Errorf("%+v\n", err)
+			return nil, errorRequestFailed
+		}
+	}
+	elapsed := time.Since(start)
+	if session != nil {
+		if err := session.Save(); err != nil {
+			return nil, err
+		}
+	}
+	// Log sent data
+	{
+		logMessage := fmt.Sprintf("Sending request:\n> %s %s %s\n", req.Method, req.URL, req.Proto)
+		for key, value := range req.Header {
+			if key == "Authorization" && resolved.Auth.Type != "" && resolved.Auth.Type != AuthNone {
+				// Hide the credentials added by the auth
+				value = []string{strings.SplitN(value[0], " ", 2)[0] + " " + SecretMask}
+			}
+			logMessage += "> " + key + ": " + strings.Join(value, ", ") + "\n"
+		}
+		logMessage += "\n"
+		log.Debugf(maskSecrets(logMessage))
+	}
+
+	body, err := recordResponse(r.Name, resolved, resp, elapsed)
+	if err != nil {
+		return nil, err
+	}
+	if len(resolved.Assertions) > 0 {
+		if err := checkAssertions(r.Name, resolved.Assertions, resp, body, elapsed); err != nil {
+			return resp, err
+		}
+	}
+	return resp, nil
+}
+
+// newHTTPRequest creates the HTTP request of the resolved request
+func (r *Request) newHTTPRequest() (*http.Request, error) {
+	req, err := http.NewRequest(r.Method, r.URL, strings.NewReader(r.Body))
+	if err != nil {
+		log.Errorf("%+v\n", err)
+		return nil, errorCreateRequestFailed
+	}
+	for _, header := range r.Headers {
+		req.Header.Add(header.Key, header.Value)
+	}
+	return req, nil
+}
+
+// Resolve generates the variables used by the request and returns a copy
+// with every variable replaced by its value in environment e.
+func (r *Request) Resolve(e Environment) (Request, error) {
 	// Generate variables
 	for _, variable := range e.GetVariablesInRequest(r) {
 		if err := variable.GenerateValue(); err != nil {
 			log.Errorf("%+v\n", err)
-			return nil, errorGenerateVariableFailed
+			return Request{}, errorGenerateVariableFailed
 		}
 		// TODO: This is a hack to prevent saving override variables
-		if variable.Type != ConstType {
+		if variable.Type != ConstType && !variable.isExternal() {
 			variable.Save()
 		}
+		if variable.Secret {
+			// Check the value can be decrypted, it is decrypted again
+			// when replaced
+			if _, err := variable.plainValue(); err != nil {
+				log.Errorf("%+v\n", err)
+				return Request{}, errorGenerateVariableFailed
+			}
+		}
 	}
 
-	methodStr := e.ReplaceVariables(r.Method)
 	urlStr := e.ReplaceVariables(r.URL)
-	bodyStr := e.ReplaceVariables(r.Body)
 
 	// Check url is valid
 	if !strings.Contains(urlStr, "//") {
@@ -88,42 +197,37 @@ func (r *Request) RunEnv(e Environment) (*http.Response, error) {
 	urlObj, err := url.Parse(urlStr)
 	if err != nil {
 		log.Errorf("%+v\n", err)
-		return nil, errorInvalidURL
+		return Request{}, errorInvalidURL
 	}
 	if urlObj.Scheme == "" {
 		urlObj.Scheme = "http"
 	}
-	urlStr = urlObj.String()
 
-	// Create request
-	req, err := http.NewRequest(methodStr, urlStr, strings.NewReader(bodyStr))
-	if err != nil {
-		log.Errorf("%+v\n", err)
-		return nil, errorCreateRequestFailed
-	}
-	// Add headers
+	headers := []Header{}
 	for _, header := range r.Headers {
-		key := e.ReplaceVariables(header.Key)
-		value := e.ReplaceVariables(header.Value)
-		req.Header.Add(key, value)
+		headers = append(headers, Header{
+			Key:   e.ReplaceVariables(header.Key),
+			Value: e.ReplaceVariables(header.Value),
+		})
 	}
 
-	// Send request and get response
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		log.Errorf("%+v\n", err)
-		return nil, errorRequestFailed
-	}
-	// Log sent data
-	{
-		logMessage := fmt.Sprintf("Sending request:\n> %s %s %s\n", req.Method, req.URL, req.Proto)
-		for key, value := range req.Header {
-			logMessage += "> " + key + ": " + strings.Join(value, ", ") + "\n"
-		}
-		logMessage += "\n"
-		log.Debugf(logMessage)
+	assertions := []Assertion{}
+	for _, assertion := range r.Assertions {
+		assertion.Value = e.ReplaceVariables(assertion.Value)
+		assertions = append(assertions, assertion)
 	}
-	return resp, nil
+
+	return Request{
+		Name:        r.Name,
+		Method:      e.ReplaceVariables(r.Method),
+		URL:         urlObj.String(),
+		Environment: e,
+		Body:        e.ReplaceVariables(r.Body),
+		Headers:     headers,
+		Assertions:  assertions,
+		Client:      r.Client,
+		Auth:        r.authFor(e).replaceVariables(&e),
+	}, nil
 }
 func (r *Request) Save() error {
 	if err := r.Validate(); err != nil {
@@ -141,6 +245,7 @@ func (r *Request) Validate() error {
 		"HEAD":    true,
 		"POST":    true,
 		"PUT":     true,
+		"PATCH":   true,
 		"DELETE":  true,
 		"CONNECT": true,
 		"OPTIONS": true,
@@ -150,7 +255,10 @@ func (r *Request) Validate() error {
 		return errorInvalidMethod
 	}
 	r.Method = strings.ToUpper(r.Method)
-	return nil
+	if err := r.Auth.Validate(); err != nil {
+		return err
+	}
+	return r.Client.Validate()
 }
 func (r *Request) UpdateHeaders(headers []Header) error {
 	headerMap := make(map[string]*Header)
@@ -175,24 +283,224 @@ func (r *Request) UpdateVariables(variables []Variable) error {
 	overrideVariables = variables
 	return nil
 }
+func (r *Request) UpdateAssertions(assertions []Assertion) error {
+	r.Assertions = append(r.Assertions, assertions...)
+	return nil
+}
+
+// Suite
+type Suite struct {
+	Name        string      `yaml:"name"`
+	Environment Environment `yaml:"environment"`
+	Requests    []string    `yaml:"requests"`
+
+	headers    []Header
+	assertions []Assertion
+}
+
+func (s *Suite) Run() (*http.Response, error) {
+	return s.RunEnv(s.Environment)
+}
+func (s *Suite) RunEnv(e Environment) (*http.Response, error) {
+	// Every step sends the cookies of the previous ones
+	if suiteSession == nil || suiteSession.Environment.Name != e.Name {
+		session := Session{Environment: e}
+		if sessionName != "" {
+			session = GetSession(sessionName, e)
+		}
+		previous := suiteSession
+		suiteSession = &session
+		defer func() { suiteSession = previous }()
+	}
+	requests := []Request{}
+	for _, name := range s.Requests {
+		request, err := GetRequestByName(name)
+		if err != nil {
+			return nil, err
+		}
+		request.UpdateHeaders(s.headers)
+		request.UpdateAssertions(s.assertions)
+		requests = append(requests, request)
+	}
+	if strictMode {
+		// Report the undefined variables of every step before sending any
+		undefined := []UndefinedVariable{}
+		for _, request := range requests {
+			for _, variable := range request.UndefinedVariables(e) {
+				variable.Field = request.Name + ": " + variable.Field
+				undefined = append(undefined, variable)
+			}
+		}
+		if len(undefined) > 0 {
+			return nil, &UndefinedVariablesError{Request: s.Name, Environment: e.Name, Variables: undefined}
+		}
+	}
+
+	var resp *http.Response
+	var err error
+	for i, request := range requests {
+		name := request.Name
+		// Only the last response is returned to the caller
+		if resp != nil {
+			resp.Body.Close()
+		}
+		resp, err = request.RunEnv(e)
+		if _, ok := err.(*AssertionError); ok {
+			log.Infof("Step %d/%d (%s): %s", i+1, len(s.Requests), name, resp.Status)
+			return resp, err
+		}
+		if err != nil {
+			log.Errorf("Step %d/%d (%s) failed: %+v\n", i+1, len(s.Requests), name, err)
+			return nil, errorSuiteStepFailed
+		}
+		log.Infof("Step %d/%d (%s): %s", i+1, len(s.Requests), name, resp.Status)
+	}
+	return resp, nil
+}
+func (s *Suite) Save() error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	return cache.SaveSuite(s.ToStore())
+}
+func (s *Suite) Delete() error {
+	return s.ToStore().Delete()
+}
+func (s *Suite) Validate() error {
+	if len(s.Requests) == 0 {
+		return errorInvalidSuite
+	}
+	for _, name := range s.Requests {
+		if _, err := cache.GetRequestByName(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func (s *Suite) UpdateHeaders(headers []Header) error {
+	s.headers = append(s.headers, headers...)
+	return nil
+}
+func (s *Suite) UpdateBody(body string) error {
+	return errorSuiteBodyOverride
+}
+func (s *Suite) UpdateVariables(variables []Variable) error {
+	overrideVariables = variables
+	return nil
+}
+func (s *Suite) UpdateAssertions(assertions []Assertion) error {
+	s.assertions = append(s.assertions, assertions...)
+	return nil
+}
 
 // Environment
 type Environment struct {
-	Name string `yaml:"name"`
+	Name        string            `yaml:"name"`
+	Parent      string            `yaml:"parent,omitempty"`
+	Client      ClientProfile     `yaml:"client,omitempty"`
+	Certificate ClientCertificate `yaml:"certificate,omitempty"`
+	Auth        Auth              `yaml:"auth,omitempty"`
 }
 
 func (e *Environment) Save() error {
 	if err := e.Validate(); err != nil {
-		return errorInvalidEnvironment
+		return err
 	}
 	return cache.SaveEnvironment(e.ToStore())
 }
 func (e *Environment) Delete() error {
+	for _, env := range cache.GetAllEnvironments() {
+		if env.Parent == e.Name {
+			return errorEnvironmentHasChildren
+		}
+	}
 	return e.ToStore().Delete()
 }
 func (e *Environment) Validate() error {
+	if err := e.Client.Validate(); err != nil {
+		return err
+	}
+	if err := e.Certificate.Validate(); err != nil {
+		return err
+	}
+	if err := e.Auth.Validate(); err != nil {
+		return err
+	}
+	if e.Parent == "" {
+		return nil
+	}
+	if e.Name == globalEnvironment.Name {
+		return errorGlobalParent
+	}
+	if _, err := cache.GetEnvironmentByName(e.Parent); err != nil {
+		return errorParentNotFound
+	}
+	parent := Environment{Name: e.Parent}
+	for _, ancestor := range parent.Chain() {
+		if ancestor.Name == e.Name {
+			return errorParentCycle
+		}
+	}
+	return nil
+}
+
+// Clone saves the environment, which must not exist yet, with a copy of the
+// variables of source. Generators that run their request in source run it in
+// the new environment, and are generated again.
+func (e *Environment) Clone(source Environment) error {
+	for _, env := range cache.GetAllEnvironments() {
+		if env.Name == e.Name {
+			return errorEnvironmentExists
+		}
+	}
+	if err := e.Save(); err != nil {
+		return err
+	}
+	for _, variable := range source.GetVariables() {
+		variable.Environment = Environment{Name: e.Name}
+		if variable.Generator != nil {
+			generator := *variable.Generator
+			generator.Choices = append([]string{}, generator.Choices...)
+			if generator.RequestEnvironment == source.Name {
+				generator.RequestEnvironment = e.Name
+				generator.LastGenerated = time.Time{}
+				variable.Value = ""
+			}
+			variable.Generator = &generator
+		}
+		if err := variable.Save(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
+
+// Chain returns the environment followed by its ancestors, ending with the
+// global environment. Parents are looked up by name, since the environment
+// of a request or variable only holds its name.
+func (e *Environment) Chain() []Environment {
+	environments := make(map[string]Environment)
+	for _, env := range cache.GetAllEnvironments() {
+		environments[env.Name] = convertToEnvironment(env)
+	}
+	chain := []Environment{}
+	seen := make(map[string]bool)
+	for name := e.Name; !seen[name]; {
+		seen[name] = true
+		env, ok := environments[name]
+		if !ok {
+			env = Environment{Name: name}
+		}
+		chain = append(chain, env)
+		if name == globalEnvironment.Name {
+			break
+		}
+		if name = env.Parent; name == "" {
+			name = globalEnvironment.Name
+		}
+	}
+	return chain
+}
 func (e *Environment) GetVariables() []Variable {
 	validVariables := []Variable{}
 	for _, variable := range cache.GetVariablesByEnvironment(e.Name) {
@@ -208,71 +516,94 @@ func (e *Environment) GetVariableNames() []string {
 	sort.Strings(varNames)
 	return varNames
 }
+
+// Inheritance returns the variables that the environment inherits from its
+// ancestors, and the variables of its ancestors that it overrides
+func (e *Environment) Inheritance() ([]Variable, []Variable) {
+	defined := make(map[string]bool)
+	for _, name := range e.GetVariableNames() {
+		defined[name] = true
+	}
+	inherited := []Variable{}
+	overridden := []Variable{}
+	seen := make(map[string]bool)
+	// The nearest ancestor comes first and hides the ones after it
+	for _, ancestor := range e.Chain()[1:] {
+		for _, variable := range ancestor.GetVariables() {
+			if seen[variable.Name] {
+				continue
+			}
+			seen[variable.Name] = true
+			if defined[variable.Name] {
+				overridden = append(overridden, variable)
+			} else {
+				inherited = append(inherited, variable)
+			}
+		}
+	}
+	sort.Slice(inherited, func(i, j int) bool { return inherited[i].Name < inherited[j].Name })
+	sort.Slice(overridden, func(i, j int) bool { return overridden[i].Name < overridden[j].Name })
+	return inherited, overridden
+}
 func (e *Environment) ReplaceVariables(input string) string {
 	if strings.Index(input, ":") == -1 {
 		return input
 	}
 
-	type locType struct {
-		startIndex int
-		endIndex   int
-		value      string
-	}
-	varLocs := []locType{}
-
-	// Iterate over all variables and create a slice of their locations
-	// in the input string.
-	// TODO: Optimize this to use only required variables (found in generate step).
+	variables := make(map[string]Variable)
 	for _, variable := range e.GetVariablesWithGlobal() {
-		re := regexp.MustCompile(`:` + variable.Name + `\b`)
-		for _, loc := range re.FindAllStringIndex(input, -1) {
-			varLocs = append(varLocs, locType{
-				startIndex: loc[0],
-				endIndex:   loc[1],
-				value:      variable.Value,
-			})
-		}
+		variables[variable.Name] = variable
 	}
 
-	// Reverse sort the list to iterate from largest startIndex to smallest
-	sort.Slice(varLocs, func(i, j int) bool {
-		return varLocs[i].startIndex > varLocs[j].startIndex
-	})
-
-	output := input
-	for _, loc := range varLocs {
-		// Replace [startIndex:endIndex] with value
-		output = output[:loc.startIndex] + loc.value + output[loc.endIndex:]
+	// Placeholders that cannot be replaced are kept as written
+	output := strings.Builder{}
+	for _, t := range tokenize(input) {
+		switch t.kind {
+		case textToken:
+			output.WriteString(t.text)
+		case variableToken:
+			variable, ok := variables[t.name]
+			if !ok {
+				output.WriteString(t.text)
+				continue
+			}
+			value, _ := variable.plainValue()
+			output.WriteString(value)
+		case functionToken:
+			value, err := t.evaluate(e)
+			if err != nil {
+				log.Warnf("%+v\n", err)
+				output.WriteString(t.text)
+				continue
+			}
+			output.WriteString(value)
+		}
 	}
-
-	return output
+	return output.String()
 }
 func (e *Environment) GetVariablesInRequest(r *Request) []Variable {
 	// Map of valid variable names
-	validVariables := make(map[string]Variable)
-	// Global must be first so it gets overwritten on collision
-	for _, variable := range globalEnvironment.GetVariables() {
-		validVariables[variable.Name] = variable
-	}
-	for _, variable := range e.GetVariables() {
-		validVariables[variable.Name] = variable
-	}
-	for _, variable := range overrideVariables {
-		validVariables[variable.Name] = variable
-	}
+	validVariables := e.resolveVariables()
 	// Build search string as a combination of all parts
 	// of the request that can be replaced
 	searchString := r.Method + "\n" + r.URL + "\n" + r.Body
 	for _, header := range r.Headers {
 		searchString = searchString + "\n" + header.Key + "\n" + header.Value
 	}
+	for _, assertion := range r.Assertions {
+		searchString = searchString + "\n" + assertion.Value
+	}
+	for _, value := range r.authFor(*e).values() {
+		searchString = searchString + "\n" + value
+	}
+	for _, field := range r.clientFields(*e) {
+		searchString = searchString + "\n" + field[1]
+	}
 
 	// Search for variables in the string and add to slice
 	// if it is a valid variable name
 	variables := []Variable{}
-	re := regexp.MustCompile(variableRegexp)
-	for _, varGroup := range re.FindAllStringSubmatch(searchString, -1) {
-		varName := varGroup[1]
+	for _, varName := range placeholderNames(searchString) {
 		if variable, ok := validVariables[varName]; ok {
 			variables = append(variables, variable)
 		}
@@ -280,26 +611,32 @@ func (e *Environment) GetVariablesInRequest(r *Request) []Variable {
 	return variables
 }
 func (e *Environment) GetVariablesWithGlobal() []Variable {
-	// Map of valid variable names
-	validVariables := make(map[string]Variable)
-	// Global must be first so it gets overwritten on collision
-	for _, variable := range globalEnvironment.GetVariables() {
-		validVariables[variable.Name] = variable
-	}
-	for _, variable := range e.GetVariables() {
-		validVariables[variable.Name] = variable
-	}
-	for _, variable := range overrideVariables {
-		validVariables[variable.Name] = variable
-	}
 	// Build return array
 	variables := []Variable{}
-	for _, variable := range validVariables {
+	for _, variable := range e.resolveVariables() {
 		variables = append(variables, variable)
 	}
 	return variables
 }
 
+// resolveVariables returns the variables of the environment and its
+// ancestors by name, with overrides applied
+func (e *Environment) resolveVariables() map[string]Variable {
+	validVariables := make(map[string]Variable)
+	// Ancestors are first so they get overwritten on collision,
+	// starting with global
+	chain := e.Chain()
+	for i := len(chain) - 1; i >= 0; i-- {
+		for _, variable := range chain[i].GetVariables() {
+			validVariables[variable.Name] = variable
+		}
+	}
+	for _, variable := range overrideVariables {
+		validVariables[variable.Name] = variable
+	}
+	return validVariables
+}
+
 // Variable
 type Variable struct {
 	Name        string             `yaml:"name"`
@@ -307,13 +644,20 @@ type Variable struct {
 	Environment Environment        `yaml:"environment"`
 	Type        string             `yaml:"type"`
 	Generator   *VariableGenerator `yaml:"generator,omitempty"`
+	Secret      bool               `yaml:"secret,omitempty"`
 }
 type VariableGenerator struct {
-	RequestName        string `yaml:"request-name,omitempty"`
-	RequestPath        string `yaml:"request-path,omitempty"`
-	RequestEnvironment string `yaml:"request-environment,omitempty"`
-	Script             string `yaml:"script,omitempty"`
-	Timeout            int64  `yaml:"timeout"`
+	RequestName        string          `yaml:"request-name,omitempty"`
+	RequestPath        string          `yaml:"request-path,omitempty"`
+	RequestEnvironment string          `yaml:"request-environment,omitempty"`
+	Script             string          `yaml:"script,omitempty"`
+	Key                string          `yaml:"key,omitempty"`
+	File               string          `yaml:"file,omitempty"`
+	Prompt             string          `yaml:"prompt,omitempty"`
+	Default            string          `yaml:"default,omitempty"`
+	Choices            []string        `yaml:"choices,omitempty"`
+	OAuth2             *OAuth2Settings `yaml:"oauth2,omitempty"`
+	Timeout            int64           `yaml:"timeout"`
 	LastGenerated      time.Time
 }
 
@@ -321,8 +665,33 @@ func (v *Variable) Save() error {
 	if err := v.Validate(); err != nil {
 		return err
 	}
+	if v.Secret && !v.isExternal() && v.Value != "" && !isEncrypted(v.Value) {
+		value, err := encryptSecret(v.Value)
+		if err != nil {
+			return err
+		}
+		v.Value = value
+	}
+	if v.Secret && v.Type == OAuth2Type {
+		if err := v.encryptOAuth2(); err != nil {
+			return err
+		}
+	}
 	return cache.SaveVariable(v.ToStore())
 }
+
+// plainValue returns the value of the variable, reading env and dotenv
+// variables and decrypting secrets
+func (v *Variable) plainValue() (string, error) {
+	if v.isExternal() {
+		// Env, dotenv and prompt values are never stored
+		return v.lookupValue()
+	}
+	if v.Secret && isEncrypted(v.Value) {
+		return decryptSecret(v.Value)
+	}
+	return v.Value, nil
+}
 func (v *Variable) Delete() error {
 	return v.ToStore().Delete()
 }
@@ -332,16 +701,35 @@ func (v *Variable) Validate() error {
 		ConstType:   true,
 		RequestType: true,
 		ScriptType:  true,
+		EnvType:     true,
+		DotenvType:  true,
+		PromptType:  true,
+		OAuth2Type:  true,
 	}
 	if _, ok := validTypes[strings.ToLower(v.Type)]; !ok {
 		return errorInvalidType
 	}
 	v.Type = strings.ToLower(v.Type)
 	// Check that the regexp matches
-	re := regexp.MustCompile("^" + variableRegexp + "$")
-	if !re.MatchString(":" + v.Name) {
+	if !isVariableName(v.Name) {
 		return errorInvalidCharacters
 	}
+	if v.Type == EnvType || v.Type == DotenvType {
+		if v.Generator == nil || v.Generator.Key == "" {
+			return errorInvalidVariable
+		}
+		if v.Type == DotenvType && v.Generator.File == "" {
+			return errorInvalidVariable
+		}
+	}
+	if v.Type == OAuth2Type {
+		if v.Generator == nil {
+			return errorInvalidOAuth2
+		}
+		if err := v.Generator.OAuth2.Validate(); err != nil {
+			return err
+		}
+	}
 	// TODO: Verify generator
 	return nil
 }
@@ -349,6 +737,15 @@ func (v *Variable) GenerateValue() error {
 	if v.Generator == nil {
 		return nil
 	}
+	if v.isExternal() {
+		// Check the value can be read, it is read again when replaced
+		_, err := v.lookupValue()
+		return err
+	}
+	if v.Type == OAuth2Type {
+		// Tokens are stale when they expire rather than after the timeout
+		return v.generateToken()
+	}
 	timeout := time.Duration(v.Generator.Timeout) * time.Minute
 	if time.Since(v.Generator.LastGenerated) < timeout {
 		return nil
@@ -364,6 +761,9 @@ func (v *Variable) GenerateValue() error {
 			return err
 		}
 		v.Value = string(bytes.Trim(out, "\n"))
+		if v.Secret {
+			rememberSecret(v.Value)
+		}
 		v.Generator.LastGenerated = time.Now()
 		return nil
 	case RequestType:
@@ -375,6 +775,11 @@ func (v *Variable) GenerateValue() error {
 		if err != nil {
 			return err
 		}
+		if v.Secret && !historyDisabled {
+			// The response contains the secret, so do not record it
+			historyDisabled = true
+			defer func() { historyDisabled = false }()
+		}
 		resp, err := req.RunEnv(env)
 		if err != nil {
 			return err
@@ -404,7 +809,10 @@ func (v *Variable) GenerateValue() error {
 				v.Value = value
 			}
 		}
-		log.Debugf("Variable %s updated to: %s\n", v.Name, v.Value)
+		if v.Secret {
+			rememberSecret(v.Value)
+		}
+		log.Debugf("Variable %s updated to: %s\n", v.Name, maskSecrets(v.Value))
 		v.Generator.LastGenerated = time.Now()
 		return nil
 	}
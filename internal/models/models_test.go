@@ -1,6 +1,9 @@
 package models
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/bouk/monkey"
@@ -13,6 +16,17 @@ func TestMain(m *testing.M) {
 	InitLogger()
 	cache.InitLogger()
 	store.InitLogger()
+	// Environments are read from the store to resolve their parents
+	dir, err := ioutil.TempDir("", "poster")
+	if err != nil {
+		panic(err)
+	}
+	if err := store.Open(filepath.Join(dir, "poster.db")); err != nil {
+		panic(err)
+	}
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
 }
 
 func TestReplaceVariablesOne(t *testing.T) {
@@ -27,7 +41,7 @@ func TestReplaceVariablesOne(t *testing.T) {
 		}
 		return []store.Variable{host}
 	}
-	monkey.Patch(store.GetVariablesByEnvironment, patchGetVariablesByEnvironment)
+	monkey.Patch(cache.GetVariablesByEnvironment, patchGetVariablesByEnvironment)
 
 	input := "https://:host?param=true"
 	actual := env.ReplaceVariables(input)
@@ -52,7 +66,7 @@ func TestReplaceVariablesTwo(t *testing.T) {
 		}
 		return []store.Variable{host, port}
 	}
-	monkey.Patch(store.GetVariablesByEnvironment, patchGetVariablesByEnvironment)
+	monkey.Patch(cache.GetVariablesByEnvironment, patchGetVariablesByEnvironment)
 
 	input := "https://:host::port"
 	actual := env.ReplaceVariables(input)
@@ -77,7 +91,7 @@ func TestReplaceVariablesOverlap(t *testing.T) {
 		}
 		return []store.Variable{host, port}
 	}
-	monkey.Patch(store.GetVariablesByEnvironment, patchGetVariablesByEnvironment)
+	monkey.Patch(cache.GetVariablesByEnvironment, patchGetVariablesByEnvironment)
 
 	{
 		input := "https://:host"
@@ -110,7 +124,7 @@ func BenchmarkReplaceVariables(b *testing.B) {
 		}
 		return []store.Variable{host, port}
 	}
-	monkey.Patch(store.GetVariablesByEnvironment, patchGetVariablesByEnvironment)
+	monkey.Patch(cache.GetVariablesByEnvironment, patchGetVariablesByEnvironment)
 
 	input := "https://:host::port"
 	for i := 0; i < b.N; i++ {
@@ -134,7 +148,7 @@ func BenchmarkReplaceVariablesNone(b *testing.B) {
 		}
 		return []store.Variable{host, port}
 	}
-	monkey.Patch(store.GetVariablesByEnvironment, patchGetVariablesByEnvironment)
+	monkey.Patch(cache.GetVariablesByEnvironment, patchGetVariablesByEnvironment)
 
 	input := "GET"
 	for i := 0; i < b.N; i++ {
@@ -158,10 +172,147 @@ func BenchmarkReplaceVariablesContainsColon(b *testing.B) {
 		}
 		return []store.Variable{host, port}
 	}
-	monkey.Patch(store.GetVariablesByEnvironment, patchGetVariablesByEnvironment)
+	monkey.Patch(cache.GetVariablesByEnvironment, patchGetVariablesByEnvironment)
 
 	input := "http://localhost:8080"
 	for i := 0; i < b.N; i++ {
 		env.ReplaceVariables(input)
 	}
 }
+
+func patchEnvironments(parents map[string]string) {
+	monkey.Patch(cache.GetAllEnvironments, func() []store.Environment {
+		environments := []store.Environment{}
+		for name, parent := range parents {
+			environments = append(environments, store.Environment{Name: name, Parent: parent})
+		}
+		return environments
+	})
+	monkey.Patch(cache.GetEnvironmentByName, func(name string) (store.Environment, error) {
+		if parent, ok := parents[name]; ok {
+			return store.Environment{Name: name, Parent: parent}, nil
+		}
+		return store.Environment{}, store.ErrorEnvironmentNotFound
+	})
+}
+
+func TestEnvironmentChain(t *testing.T) {
+	defer monkey.UnpatchAll()
+	patchEnvironments(map[string]string{
+		"global":  "",
+		"prod":    "",
+		"prod-eu": "prod",
+		"a":       "b",
+		"b":       "a",
+	})
+
+	names := func(chain []Environment) []string {
+		names := []string{}
+		for _, env := range chain {
+			names = append(names, env.Name)
+		}
+		return names
+	}
+	env := Environment{Name: "prod-eu"}
+	assert.Equal(t, []string{"prod-eu", "prod", "global"}, names(env.Chain()))
+	env = Environment{Name: "global"}
+	assert.Equal(t, []string{"global"}, names(env.Chain()))
+	env = Environment{Name: "a"}
+	assert.Equal(t, []string{"a", "b"}, names(env.Chain()))
+
+	assert.Nil(t, (&Environment{Name: "staging", Parent: "prod-eu"}).Validate())
+	assert.Equal(t, errorParentNotFound, (&Environment{Name: "staging", Parent: "dev"}).Validate())
+	assert.Equal(t, errorParentCycle, (&Environment{Name: "prod", Parent: "prod-eu"}).Validate())
+	assert.Equal(t, errorParentCycle, (&Environment{Name: "prod", Parent: "prod"}).Validate())
+	assert.Equal(t, errorGlobalParent, (&Environment{Name: "global", Parent: "prod"}).Validate())
+}
+
+func TestEnvironmentInheritance(t *testing.T) {
+	defer monkey.UnpatchAll()
+	patchEnvironments(map[string]string{
+		"global":  "",
+		"prod":    "",
+		"prod-eu": "prod",
+	})
+	monkey.Patch(cache.GetVariablesByEnvironment, func(environment string) []store.Variable {
+		values := map[string]map[string]string{
+			"global":  {"scheme": "https", "region": "none", "user": "admin"},
+			"prod":    {"host": "example.com", "region": "us"},
+			"prod-eu": {"region": "eu", "user": "eu-admin"},
+		}
+		variables := []store.Variable{}
+		for name, value := range values[environment] {
+			variables = append(variables, store.Variable{
+				Name: name, Value: value, Environment: environment, Type: ConstType,
+			})
+		}
+		return variables
+	})
+
+	env := Environment{Name: "prod-eu"}
+	assert.Equal(t, "https://example.com/eu/eu-admin", env.ReplaceVariables(":scheme://:host/:region/:user"))
+	env = Environment{Name: "prod"}
+	assert.Equal(t, "https://example.com/us/admin", env.ReplaceVariables(":scheme://:host/:region/:user"))
+
+	sources := func(variables []Variable) []string {
+		sources := []string{}
+		for _, variable := range variables {
+			sources = append(sources, variable.Name+"@"+variable.Environment.Name)
+		}
+		return sources
+	}
+	env = Environment{Name: "prod-eu"}
+	inherited, overridden := env.Inheritance()
+	assert.Equal(t, []string{"host@prod", "scheme@global"}, sources(inherited))
+	assert.Equal(t, []string{"region@prod", "user@global"}, sources(overridden))
+}
+
+func TestEnvironmentClone(t *testing.T) {
+	source := Environment{Name: "clone-source"}
+	assert.Nil(t, source.Save())
+	variables := []Variable{
+		{Name: "host", Value: "localhost", Type: ConstType, Environment: source},
+		{
+			Name: "token", Value: "abc", Type: RequestType, Environment: source,
+			Generator: &VariableGenerator{
+				RequestName: "get-token", RequestPath: "$.token",
+				RequestEnvironment: source.Name, Timeout: 10,
+			},
+		},
+		{
+			Name: "admin-token", Value: "def", Type: RequestType, Environment: source,
+			Generator: &VariableGenerator{
+				RequestName: "get-token", RequestPath: "$.token",
+				RequestEnvironment: "global",
+			},
+		},
+	}
+	for _, variable := range variables {
+		assert.Nil(t, variable.Save())
+	}
+
+	clone := Environment{Name: "clone-target"}
+	assert.Nil(t, clone.Clone(source))
+	assert.Equal(t, errorEnvironmentExists, clone.Clone(source))
+
+	cloned := map[string]Variable{}
+	for _, variable := range clone.GetVariables() {
+		cloned[variable.Name] = variable
+	}
+	assert.Equal(t, 3, len(cloned))
+	assert.Equal(t, "localhost", cloned["host"].Value)
+	assert.Equal(t, "", cloned["token"].Value)
+	assert.Equal(t, "clone-target", cloned["token"].Generator.RequestEnvironment)
+	assert.Equal(t, int64(10), cloned["token"].Generator.Timeout)
+	assert.Equal(t, "def", cloned["admin-token"].Value)
+	assert.Equal(t, "global", cloned["admin-token"].Generator.RequestEnvironment)
+
+	// The source is unchanged
+	for _, variable := range source.GetVariables() {
+		assert.Equal(t, source.Name, variable.Environment.Name)
+		if variable.Name == "token" {
+			assert.Equal(t, "abc", variable.Value)
+			assert.Equal(t, source.Name, variable.Generator.RequestEnvironment)
+		}
+	}
+}
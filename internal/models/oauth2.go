@@ -0,0 +1,278 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	GrantClientCredentials = "client_credentials"
+	GrantPassword          = "password"
+	GrantRefreshToken      = "refresh_token"
+)
+
+// oauth2ExpirySkew is how long before it expires a token is refreshed, so
+// it does not expire while the request is sent
+const oauth2ExpirySkew = 30 * time.Second
+
+// OAuth2Settings is how an oauth2 variable gets its access token from the
+// token endpoint of an authorization server, as described by RFC 6749. The
+// token is kept until it expires, then refreshed with the refresh token if
+// the server issued one, or requested again with the grant. Every field
+// except the grant type may contain variables.
+type OAuth2Settings struct {
+	TokenURL     string `yaml:"token-url" json:"token-url"`
+	GrantType    string `yaml:"grant-type" json:"grant-type"`
+	ClientID     string `yaml:"client-id,omitempty" json:"client-id,omitempty"`
+	ClientSecret string `yaml:"client-secret,omitempty" json:"client-secret,omitempty"`
+	Username     string `yaml:"username,omitempty" json:"username,omitempty"`
+	Password     string `yaml:"password,omitempty" json:"password,omitempty"`
+	Scope        string `yaml:"scope,omitempty" json:"scope,omitempty"`
+	// RefreshToken is the latest refresh token, which is also the first
+	// one for the refresh_token grant
+	RefreshToken string `yaml:"refresh-token,omitempty" json:"refresh-token,omitempty"`
+	// ExpiresAt is zero if the server did not say when the token expires
+	ExpiresAt time.Time `yaml:"expires-at,omitempty" json:"expires-at,omitempty"`
+}
+
+func (s *OAuth2Settings) Validate() error {
+	if s == nil || s.TokenURL == "" {
+		return errorInvalidOAuth2
+	}
+	switch s.GrantType {
+	case GrantClientCredentials:
+	case GrantPassword:
+		if s.Username == "" {
+			return errorInvalidOAuth2
+		}
+	case GrantRefreshToken:
+		if s.RefreshToken == "" {
+			return errorInvalidOAuth2
+		}
+	default:
+		return errorInvalidGrantType
+	}
+	return nil
+}
+
+// Config returns the settings without the token state, which is how they
+// are written by the user
+func (s OAuth2Settings) Config() OAuth2Settings {
+	s.ExpiresAt = time.Time{}
+	if s.GrantType != GrantRefreshToken {
+		s.RefreshToken = ""
+	}
+	return s
+}
+
+// Masked returns the settings with the client secret, password and refresh
+// token replaced by SecretMask, for printing
+func (s OAuth2Settings) Masked() OAuth2Settings {
+	for _, field := range []*string{&s.ClientSecret, &s.Password, &s.RefreshToken} {
+		*field = maskValue(*field)
+	}
+	return s
+}
+
+// fresh reports whether the access token of the variable can still be used
+// at time now. Tokens without an expiry are kept for the timeout of the
+// generator.
+func (v *Variable) fresh(now time.Time) bool {
+	if v.Value == "" {
+		return false
+	}
+	if expiresAt := v.Generator.OAuth2.ExpiresAt; !expiresAt.IsZero() {
+		return now.Before(expiresAt.Add(-oauth2ExpirySkew))
+	}
+	timeout := time.Duration(v.Generator.Timeout) * time.Minute
+	return now.Sub(v.Generator.LastGenerated) < timeout
+}
+
+// generateToken gets a new access token for an oauth2 variable, unless the
+// current one is fresh
+func (v *Variable) generateToken() error {
+	settings := v.Generator.OAuth2
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+	if v.fresh(time.Now()) {
+		return nil
+	}
+	log.Infof("Variable %s is stale, generating new value..", v.Name)
+
+	env := v.Environment
+	client, err := env.clientProfile().merge(clientOverride).newClient(&env)
+	if err != nil {
+		return err
+	}
+	plain, err := settings.decrypt()
+	if err != nil {
+		return err
+	}
+
+	var token oauth2Token
+	refreshToken := plain.RefreshToken
+	if refreshToken != "" {
+		token, err = plain.requestToken(client, &env, GrantRefreshToken, refreshToken)
+		if err != nil && settings.GrantType != GrantRefreshToken {
+			// The refresh token may have expired, start over
+			log.Warnf("Could not refresh variable %s: %+v\n", v.Name, err)
+			refreshToken = ""
+		}
+	}
+	if refreshToken == "" {
+		token, err = plain.requestToken(client, &env, settings.GrantType, "")
+	}
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	v.Value = token.AccessToken
+	settings.ExpiresAt = time.Time{}
+	if token.ExpiresIn > 0 {
+		settings.ExpiresAt = now.Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+	if token.RefreshToken != "" {
+		// Servers may issue a new refresh token with every access token
+		settings.RefreshToken = token.RefreshToken
+	}
+	if v.Secret {
+		rememberSecret(v.Value)
+		rememberSecret(token.RefreshToken)
+	}
+	log.Debugf("Variable %s updated to: %s\n", v.Name, maskSecrets(v.Value))
+	v.Generator.LastGenerated = now
+	return nil
+}
+
+// oauth2Token is the successful response of a token endpoint
+type oauth2Token struct {
+	AccessToken  string
+	RefreshToken string
+	// ExpiresIn is the lifetime of the access token in seconds
+	ExpiresIn int64
+}
+
+// requestToken asks the token endpoint for an access token with grant,
+// which uses refreshToken if it is the refresh_token grant. The client is
+// authenticated with HTTP Basic auth if it has a secret.
+func (s *OAuth2Settings) requestToken(client *http.Client, e *Environment, grant, refreshToken string) (oauth2Token, error) {
+	form := url.Values{"grant_type": {grant}}
+	switch grant {
+	case GrantRefreshToken:
+		form.Set("refresh_token", refreshToken)
+	case GrantPassword:
+		form.Set("username", e.ReplaceVariables(s.Username))
+		form.Set("password", e.ReplaceVariables(s.Password))
+	}
+	if scope := e.ReplaceVariables(s.Scope); scope != "" && grant != GrantRefreshToken {
+		form.Set("scope", scope)
+	}
+	clientID := e.ReplaceVariables(s.ClientID)
+	clientSecret := e.ReplaceVariables(s.ClientSecret)
+	if clientSecret == "" && clientID != "" {
+		form.Set("client_id", clientID)
+	}
+
+	req, err := http.NewRequest("POST", e.ReplaceVariables(s.TokenURL), strings.NewReader(form.Encode()))
+	if err != nil {
+		log.Errorf("%+v\n", err)
+		return oauth2Token{}, errorCreateRequestFailed
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if clientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(clientID), url.QueryEscape(clientSecret))
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return oauth2Token{}, err
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return oauth2Token{}, err
+	}
+
+	response := struct {
+		AccessToken      string          `json:"access_token"`
+		RefreshToken     string          `json:"refresh_token"`
+		ExpiresIn        json.RawMessage `json:"expires_in"`
+		Error            string          `json:"error"`
+		ErrorDescription string          `json:"error_description"`
+	}{}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return oauth2Token{}, fmt.Errorf("the token endpoint returned %s: %s", resp.Status, body)
+	}
+	if response.Error != "" {
+		return oauth2Token{}, fmt.Errorf("the token endpoint returned %s: %s %s",
+			resp.Status, response.Error, response.ErrorDescription)
+	}
+	if resp.StatusCode/100 != 2 || response.AccessToken == "" {
+		return oauth2Token{}, fmt.Errorf("the token endpoint returned %s without an access token", resp.Status)
+	}
+	// Some servers send the lifetime as a string
+	expiresIn, _ := strconv.ParseInt(strings.Trim(string(response.ExpiresIn), `"`), 10, 64)
+	return oauth2Token{
+		AccessToken:  response.AccessToken,
+		RefreshToken: response.RefreshToken,
+		ExpiresIn:    expiresIn,
+	}, nil
+}
+
+// encryptOAuth2 encrypts the credentials of a secret oauth2 variable
+func (v *Variable) encryptOAuth2() error {
+	settings := v.Generator.OAuth2
+	for _, field := range []*string{&settings.ClientSecret, &settings.Password, &settings.RefreshToken} {
+		if *field == "" || isEncrypted(*field) {
+			continue
+		}
+		value, err := encryptSecret(*field)
+		if err != nil {
+			return err
+		}
+		*field = value
+	}
+	return nil
+}
+
+// decrypt returns the settings with the credentials encrypted by
+// encryptOAuth2 decrypted
+func (s OAuth2Settings) decrypt() (OAuth2Settings, error) {
+	for _, field := range []*string{&s.ClientSecret, &s.Password, &s.RefreshToken} {
+		if !isEncrypted(*field) {
+			continue
+		}
+		value, err := decryptSecret(*field)
+		if err != nil {
+			return OAuth2Settings{}, err
+		}
+		*field = value
+	}
+	return s, nil
+}
+
+// oauth2Generator and parseOAuth2Generator convert the settings to and
+// from the generator column
+func oauth2Generator(g *VariableGenerator) string {
+	if g.OAuth2 == nil {
+		return ""
+	}
+	data, _ := json.Marshal(g.OAuth2)
+	return string(data)
+}
+func parseOAuth2Generator(s string, g *VariableGenerator) {
+	settings := &OAuth2Settings{}
+	if err := json.Unmarshal([]byte(s), settings); err != nil {
+		log.Warnf("invalid oauth2 settings %q: %+v\n", s, err)
+		return
+	}
+	g.OAuth2 = settings
+}
@@ -0,0 +1,213 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// tokenServer is a stand-in token endpoint that counts the requests of each
+// grant and issues tokens with the next lifetime
+type tokenServer struct {
+	*httptest.Server
+	grants    map[string]int
+	expiresIn interface{}
+	issued    int
+}
+
+func newTokenServer(t *testing.T) *tokenServer {
+	server := &tokenServer{grants: make(map[string]int), expiresIn: 3600}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Nil(t, r.ParseForm())
+		grant := r.PostForm.Get("grant_type")
+		server.grants[grant]++
+		w.Header().Set("Content-Type", "application/json")
+		fail := func(code string) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error":             code,
+				"error_description": "rejected " + grant,
+			})
+		}
+		switch grant {
+		case GrantClientCredentials:
+			if id, secret, ok := r.BasicAuth(); !ok || id != "poster" || secret != "s3cret" {
+				fail("invalid_client")
+				return
+			}
+		case GrantPassword:
+			if r.PostForm.Get("client_id") != "poster" || r.PostForm.Get("username") != "alice" ||
+				r.PostForm.Get("password") != "hunter2" {
+				fail("invalid_grant")
+				return
+			}
+		case GrantRefreshToken:
+			if r.PostForm.Get("refresh_token") != fmt.Sprintf("refresh-%d", server.issued) {
+				fail("invalid_grant")
+				return
+			}
+		default:
+			fail("unsupported_grant_type")
+			return
+		}
+		server.issued++
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":  fmt.Sprintf("access-%d", server.issued),
+			"token_type":    "Bearer",
+			"expires_in":    server.expiresIn,
+			"refresh_token": fmt.Sprintf("refresh-%d", server.issued),
+			"scope":         r.PostForm.Get("scope"),
+		})
+	}))
+	return server
+}
+
+func TestOAuth2Validate(t *testing.T) {
+	var settings *OAuth2Settings
+	assert.Equal(t, errorInvalidOAuth2, settings.Validate())
+	assert.Nil(t, (&OAuth2Settings{TokenURL: ":url", GrantType: GrantClientCredentials}).Validate())
+	assert.Nil(t, (&OAuth2Settings{TokenURL: ":url", GrantType: GrantPassword, Username: "alice"}).Validate())
+	assert.Nil(t, (&OAuth2Settings{TokenURL: ":url", GrantType: GrantRefreshToken, RefreshToken: "abc"}).Validate())
+	assert.Equal(t, errorInvalidOAuth2, (&OAuth2Settings{GrantType: GrantClientCredentials}).Validate())
+	assert.Equal(t, errorInvalidOAuth2, (&OAuth2Settings{TokenURL: ":url", GrantType: GrantPassword}).Validate())
+	assert.Equal(t, errorInvalidOAuth2, (&OAuth2Settings{TokenURL: ":url", GrantType: GrantRefreshToken}).Validate())
+	assert.Equal(t, errorInvalidGrantType, (&OAuth2Settings{TokenURL: ":url", GrantType: "implicit"}).Validate())
+
+	variable := Variable{Name: "token", Type: OAuth2Type, Environment: Environment{Name: "global"}}
+	assert.Equal(t, errorInvalidOAuth2, variable.Validate())
+}
+
+func TestOAuth2ClientCredentials(t *testing.T) {
+	server := newTokenServer(t)
+	defer server.Close()
+
+	env := Environment{Name: "oauth2-client"}
+	assert.Nil(t, env.Save())
+	for name, value := range map[string]string{"token-url": server.URL, "client-secret": "s3cret"} {
+		variable := Variable{Name: name, Value: value, Type: ConstType, Environment: env}
+		assert.Nil(t, variable.Save())
+	}
+	variable := Variable{
+		Name: "token", Type: OAuth2Type, Environment: env,
+		Generator: &VariableGenerator{OAuth2: &OAuth2Settings{
+			TokenURL:     ":token-url",
+			GrantType:    GrantClientCredentials,
+			ClientID:     "poster",
+			ClientSecret: ":client-secret",
+			Scope:        "read",
+		}},
+	}
+	assert.Nil(t, variable.Save())
+
+	// The token is kept until it expires
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, variable.GenerateValue())
+	}
+	assert.Equal(t, "access-1", variable.Value)
+	assert.Equal(t, "refresh-1", variable.Generator.OAuth2.RefreshToken)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), variable.Generator.OAuth2.ExpiresAt, time.Minute)
+	assert.Equal(t, map[string]int{GrantClientCredentials: 1}, server.grants)
+
+	// Expired tokens are refreshed with the refresh token
+	variable.Generator.OAuth2.ExpiresAt = time.Now().Add(oauth2ExpirySkew / 2)
+	assert.Nil(t, variable.GenerateValue())
+	assert.Equal(t, "access-2", variable.Value)
+	assert.Equal(t, "refresh-2", variable.Generator.OAuth2.RefreshToken)
+	assert.Equal(t, 1, server.grants[GrantRefreshToken])
+
+	// The grant is used again when the refresh token is rejected
+	variable.Generator.OAuth2.RefreshToken = "revoked"
+	variable.Generator.OAuth2.ExpiresAt = time.Now()
+	assert.Nil(t, variable.GenerateValue())
+	assert.Equal(t, "access-3", variable.Value)
+	assert.Equal(t, 2, server.grants[GrantClientCredentials])
+
+	// The token state is saved with the variable
+	assert.Nil(t, variable.Save())
+	saved := convertToVariable(*variable.ToStore())
+	expected := *variable.Generator.OAuth2
+	assert.True(t, expected.ExpiresAt.Equal(saved.Generator.OAuth2.ExpiresAt))
+	expected.ExpiresAt = saved.Generator.OAuth2.ExpiresAt
+	assert.Equal(t, expected, *saved.Generator.OAuth2)
+	assert.Equal(t, "access-3", saved.Value)
+}
+
+func TestOAuth2Grants(t *testing.T) {
+	server := newTokenServer(t)
+	defer server.Close()
+	env := Environment{Name: "global"}
+
+	// Lifetimes sent as strings are understood
+	server.expiresIn = "60"
+	variable := Variable{
+		Name: "token", Type: OAuth2Type, Environment: env,
+		Generator: &VariableGenerator{OAuth2: &OAuth2Settings{
+			TokenURL:  server.URL,
+			GrantType: GrantPassword,
+			ClientID:  "poster",
+			Username:  "alice",
+			Password:  "hunter2",
+		}},
+	}
+	assert.Nil(t, variable.GenerateValue())
+	assert.Equal(t, "access-1", variable.Value)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), variable.Generator.OAuth2.ExpiresAt, 10*time.Second)
+
+	// Tokens without a lifetime are kept for the timeout
+	server.expiresIn = nil
+	variable = Variable{
+		Name: "token", Type: OAuth2Type, Environment: env,
+		Generator: &VariableGenerator{Timeout: 5, OAuth2: &OAuth2Settings{
+			TokenURL:     server.URL,
+			GrantType:    GrantRefreshToken,
+			RefreshToken: "refresh-1",
+		}},
+	}
+	assert.Nil(t, variable.GenerateValue())
+	assert.Nil(t, variable.GenerateValue())
+	assert.Equal(t, "access-2", variable.Value)
+	assert.True(t, variable.Generator.OAuth2.ExpiresAt.IsZero())
+	assert.Equal(t, 1, server.grants[GrantRefreshToken])
+
+	// The refresh token grant has nothing to fall back to
+	variable.Generator.LastGenerated = time.Time{}
+	variable.Generator.OAuth2.RefreshToken = "revoked"
+	err := variable.GenerateValue()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "invalid_grant rejected refresh_token")
+	assert.Equal(t, "access-2", variable.Value)
+}
+
+func TestOAuth2Secret(t *testing.T) {
+	os.Setenv(PassphraseEnv, "hunter2")
+	defer os.Unsetenv(PassphraseEnv)
+	server := newTokenServer(t)
+	defer server.Close()
+
+	variable := Variable{
+		Name: "secret-token", Type: OAuth2Type, Environment: Environment{Name: "global"}, Secret: true,
+		Generator: &VariableGenerator{OAuth2: &OAuth2Settings{
+			TokenURL:     server.URL,
+			GrantType:    GrantClientCredentials,
+			ClientID:     "poster",
+			ClientSecret: "s3cret",
+		}},
+	}
+	assert.Nil(t, variable.Save())
+	assert.True(t, isEncrypted(variable.Generator.OAuth2.ClientSecret))
+
+	assert.Nil(t, variable.GenerateValue())
+	assert.Nil(t, variable.Save())
+	assert.True(t, isEncrypted(variable.Value))
+	assert.True(t, isEncrypted(variable.Generator.OAuth2.RefreshToken))
+	value, err := variable.plainValue()
+	assert.Nil(t, err)
+	assert.Equal(t, "access-1", value)
+	assert.Equal(t, "Bearer "+SecretMask, maskSecrets("Bearer access-1"))
+}
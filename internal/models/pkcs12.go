@@ -0,0 +1,401 @@
+package models
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"hash"
+	"unicode/utf16"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// decodePKCS12 reads the private key and certificates of a PKCS#12 file
+// (RFC 7292), as written by openssl pkcs12 -export. Both the current
+// encryption (PBES2 with AES) and the legacy one (3DES and RC2) are read,
+// unlike golang.org/x/crypto/pkcs12, which only reads the legacy one and a
+// single certificate.
+func decodePKCS12(data []byte, password string) (crypto.PrivateKey, []*x509.Certificate, error) {
+	pfx := pkcs12PFX{}
+	if err := unmarshalDER(data, &pfx); err != nil {
+		return nil, nil, err
+	}
+	if !pfx.AuthSafe.ContentType.Equal(oidPKCS7Data) {
+		return nil, nil, errors.New("only password integrity mode is supported")
+	}
+	authSafe := []byte{}
+	if err := unmarshalDER(pfx.AuthSafe.Content.Bytes, &authSafe); err != nil {
+		return nil, nil, err
+	}
+	if len(pfx.MacData.Mac.Digest) > 0 {
+		if err := pfx.MacData.verify(authSafe, password); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	contents := []pkcs12ContentInfo{}
+	if err := unmarshalDER(authSafe, &contents); err != nil {
+		return nil, nil, err
+	}
+	var key crypto.PrivateKey
+	certs := []*x509.Certificate{}
+	for _, content := range contents {
+		bagData := []byte{}
+		switch {
+		case content.ContentType.Equal(oidPKCS7Data):
+			if err := unmarshalDER(content.Content.Bytes, &bagData); err != nil {
+				return nil, nil, err
+			}
+		case content.ContentType.Equal(oidPKCS7EncryptedData):
+			encrypted := pkcs12EncryptedData{}
+			if err := unmarshalDER(content.Content.Bytes, &encrypted); err != nil {
+				return nil, nil, err
+			}
+			info := encrypted.EncryptedContentInfo
+			var err error
+			bagData, err = pbeDecrypt(info.ContentEncryptionAlgorithm, password, info.EncryptedContent)
+			if err != nil {
+				return nil, nil, err
+			}
+		default:
+			return nil, nil, fmt.Errorf("unsupported content type %v", content.ContentType)
+		}
+
+		bags := []pkcs12SafeBag{}
+		if err := unmarshalDER(bagData, &bags); err != nil {
+			return nil, nil, err
+		}
+		for _, bag := range bags {
+			switch {
+			case bag.ID.Equal(oidCertBag):
+				certBag := pkcs12CertBag{}
+				if err := unmarshalDER(bag.Value.Bytes, &certBag); err != nil {
+					return nil, nil, err
+				}
+				if !certBag.ID.Equal(oidX509Certificate) {
+					continue
+				}
+				cert, err := x509.ParseCertificate(certBag.Data)
+				if err != nil {
+					return nil, nil, err
+				}
+				certs = append(certs, cert)
+			case bag.ID.Equal(oidKeyBag):
+				parsed, err := x509.ParsePKCS8PrivateKey(bag.Value.Bytes)
+				if err != nil {
+					return nil, nil, err
+				}
+				key = parsed
+			case bag.ID.Equal(oidPKCS8ShroudedKeyBag):
+				shrouded := pkcs12EncryptedPrivateKeyInfo{}
+				if err := unmarshalDER(bag.Value.Bytes, &shrouded); err != nil {
+					return nil, nil, err
+				}
+				plain, err := pbeDecrypt(shrouded.Algorithm, password, shrouded.EncryptedData)
+				if err != nil {
+					return nil, nil, err
+				}
+				parsed, err := x509.ParsePKCS8PrivateKey(plain)
+				if err != nil {
+					return nil, nil, err
+				}
+				key = parsed
+			}
+		}
+	}
+	if key == nil {
+		return nil, nil, errors.New("no private key found")
+	}
+	if len(certs) == 0 {
+		return nil, nil, errors.New("no certificate found")
+	}
+	return key, certs, nil
+}
+
+var (
+	oidPKCS7Data           = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidPKCS7EncryptedData  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 6}
+	oidKeyBag              = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 1}
+	oidPKCS8ShroudedKeyBag = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 2}
+	oidCertBag             = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 3}
+	oidX509Certificate     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 22, 1}
+
+	oidSHA1   = asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}
+	oidSHA256 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+
+	oidPBEWithSHAAnd3DES   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 1, 3}
+	oidPBEWithSHAAnd40RC2  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 1, 6}
+	oidPBES2               = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2              = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidHMACWithSHA1        = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACWithSHA256      = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidAES128CBC           = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC           = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC           = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+	oidDESEDE3CBC          = asn1.ObjectIdentifier{1, 2, 840, 113549, 3, 7}
+	errorPKCS12BadPassword = errors.New("wrong password or corrupted file")
+)
+
+type pkcs12PFX struct {
+	Version  int
+	AuthSafe pkcs12ContentInfo
+	MacData  pkcs12MacData `asn1:"optional"`
+}
+type pkcs12ContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"tag:0,explicit,optional"`
+}
+type pkcs12MacData struct {
+	Mac struct {
+		Algorithm pkix.AlgorithmIdentifier
+		Digest    []byte
+	}
+	MacSalt    []byte
+	Iterations int `asn1:"optional,default:1"`
+}
+type pkcs12EncryptedData struct {
+	Version              int
+	EncryptedContentInfo struct {
+		ContentType                asn1.ObjectIdentifier
+		ContentEncryptionAlgorithm pkix.AlgorithmIdentifier
+		EncryptedContent           []byte `asn1:"tag:0,optional"`
+	}
+}
+type pkcs12SafeBag struct {
+	ID         asn1.ObjectIdentifier
+	Value      asn1.RawValue `asn1:"tag:0,explicit"`
+	Attributes asn1.RawValue `asn1:"optional"`
+}
+type pkcs12CertBag struct {
+	ID   asn1.ObjectIdentifier
+	Data []byte `asn1:"tag:0,explicit"`
+}
+type pkcs12EncryptedPrivateKeyInfo struct {
+	Algorithm     pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+type pkcs12PBEParams struct {
+	Salt       []byte
+	Iterations int
+}
+type pkcs12PBES2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+type pkcs12PBKDF2Params struct {
+	Salt       []byte
+	Iterations int
+	KeyLength  int                      `asn1:"optional"`
+	PRF        pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+// maxPKCS12Iterations bounds the iterations of the key derivation, so a
+// malformed file cannot keep the decoder busy for hours. OpenSSL writes 2048.
+const maxPKCS12Iterations = 1000000
+
+func checkIterations(iterations int) error {
+	if iterations < 1 || iterations > maxPKCS12Iterations {
+		return fmt.Errorf("unsupported number of iterations %d", iterations)
+	}
+	return nil
+}
+
+// unmarshalDER is asn1.Unmarshal without trailing data
+func unmarshalDER(data []byte, value interface{}) error {
+	rest, err := asn1.Unmarshal(data, value)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return errors.New("trailing data after ASN.1 value")
+	}
+	return nil
+}
+
+// verify checks the MAC of the authenticated safe, which is how a wrong
+// password is detected
+func (m *pkcs12MacData) verify(message []byte, password string) error {
+	var h func() hash.Hash
+	switch algorithm := m.Mac.Algorithm.Algorithm; {
+	case algorithm.Equal(oidSHA1):
+		h = sha1.New
+	case algorithm.Equal(oidSHA256):
+		h = sha256.New
+	default:
+		return fmt.Errorf("unsupported MAC algorithm %v", algorithm)
+	}
+	if err := checkIterations(m.Iterations); err != nil {
+		return err
+	}
+	key := pkcs12KDF(h, m.MacSalt, bmpPassword(password), m.Iterations, 3, h().Size())
+	mac := hmac.New(h, key)
+	mac.Write(message)
+	if !hmac.Equal(mac.Sum(nil), m.Mac.Digest) {
+		return errorPKCS12BadPassword
+	}
+	return nil
+}
+
+// pbeDecrypt decrypts data with the password based encryption algorithm
+func pbeDecrypt(algorithm pkix.AlgorithmIdentifier, password string, data []byte) ([]byte, error) {
+	var block cipher.Block
+	var iv []byte
+	switch {
+	case algorithm.Algorithm.Equal(oidPBEWithSHAAnd3DES), algorithm.Algorithm.Equal(oidPBEWithSHAAnd40RC2):
+		params := pkcs12PBEParams{}
+		if err := unmarshalDER(algorithm.Parameters.FullBytes, &params); err != nil {
+			return nil, err
+		}
+		if err := checkIterations(params.Iterations); err != nil {
+			return nil, err
+		}
+		passwordBytes := bmpPassword(password)
+		iv = pkcs12KDF(sha1.New, params.Salt, passwordBytes, params.Iterations, 2, 8)
+		if algorithm.Algorithm.Equal(oidPBEWithSHAAnd3DES) {
+			key := pkcs12KDF(sha1.New, params.Salt, passwordBytes, params.Iterations, 1, 24)
+			var err error
+			if block, err = des.NewTripleDESCipher(key); err != nil {
+				return nil, err
+			}
+		} else {
+			key := pkcs12KDF(sha1.New, params.Salt, passwordBytes, params.Iterations, 1, 5)
+			block = newRC2Cipher(key, 40)
+		}
+	case algorithm.Algorithm.Equal(oidPBES2):
+		var err error
+		if block, iv, err = pbes2Cipher(algorithm, password); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported encryption algorithm %v", algorithm.Algorithm)
+	}
+
+	if len(data) == 0 || len(data)%block.BlockSize() != 0 || len(iv) != block.BlockSize() {
+		return nil, errorPKCS12BadPassword
+	}
+	plain := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plain, data)
+	// Remove the PKCS#7 padding
+	padding := int(plain[len(plain)-1])
+	if padding == 0 || padding > block.BlockSize() ||
+		!bytes.Equal(plain[len(plain)-padding:], bytes.Repeat([]byte{byte(padding)}, padding)) {
+		return nil, errorPKCS12BadPassword
+	}
+	return plain[:len(plain)-padding], nil
+}
+
+// pbes2Cipher returns the cipher and IV of PBES2 (RFC 8018) with PBKDF2,
+// which uses the password as UTF-8 like OpenSSL
+func pbes2Cipher(algorithm pkix.AlgorithmIdentifier, password string) (cipher.Block, []byte, error) {
+	params := pkcs12PBES2Params{}
+	if err := unmarshalDER(algorithm.Parameters.FullBytes, &params); err != nil {
+		return nil, nil, err
+	}
+	if !params.KeyDerivationFunc.Algorithm.Equal(oidPBKDF2) {
+		return nil, nil, fmt.Errorf("unsupported key derivation function %v", params.KeyDerivationFunc.Algorithm)
+	}
+	kdfParams := pkcs12PBKDF2Params{}
+	if err := unmarshalDER(params.KeyDerivationFunc.Parameters.FullBytes, &kdfParams); err != nil {
+		return nil, nil, err
+	}
+	if err := checkIterations(kdfParams.Iterations); err != nil {
+		return nil, nil, err
+	}
+	h := sha1.New
+	if prf := kdfParams.PRF.Algorithm; len(prf) > 0 && !prf.Equal(oidHMACWithSHA1) {
+		if !prf.Equal(oidHMACWithSHA256) {
+			return nil, nil, fmt.Errorf("unsupported pseudorandom function %v", prf)
+		}
+		h = sha256.New
+	}
+
+	var keyLength int
+	var newCipher func(key []byte) (cipher.Block, error)
+	switch scheme := params.EncryptionScheme.Algorithm; {
+	case scheme.Equal(oidAES128CBC):
+		keyLength, newCipher = 16, aes.NewCipher
+	case scheme.Equal(oidAES192CBC):
+		keyLength, newCipher = 24, aes.NewCipher
+	case scheme.Equal(oidAES256CBC):
+		keyLength, newCipher = 32, aes.NewCipher
+	case scheme.Equal(oidDESEDE3CBC):
+		keyLength, newCipher = 24, des.NewTripleDESCipher
+	default:
+		return nil, nil, fmt.Errorf("unsupported encryption scheme %v", scheme)
+	}
+	iv := []byte{}
+	if err := unmarshalDER(params.EncryptionScheme.Parameters.FullBytes, &iv); err != nil {
+		return nil, nil, err
+	}
+	key := pbkdf2.Key([]byte(password), kdfParams.Salt, kdfParams.Iterations, keyLength, h)
+	block, err := newCipher(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return block, iv, nil
+}
+
+// pkcs12KDF derives size bytes of key material with the key derivation
+// function of RFC 7292, appendix B. The id is 1 for keys, 2 for IVs and 3
+// for MAC keys.
+func pkcs12KDF(h func() hash.Hash, salt, password []byte, iterations int, id byte, size int) []byte {
+	const v = 64 // block size of SHA-1 and SHA-256
+	fill := func(data []byte) []byte {
+		if len(data) == 0 {
+			return nil
+		}
+		output := make([]byte, v*((len(data)+v-1)/v))
+		for i := range output {
+			output[i] = data[i%len(data)]
+		}
+		return output
+	}
+	d := bytes.Repeat([]byte{id}, v)
+	i := append(fill(salt), fill(password)...)
+
+	output := []byte{}
+	for len(output) < size {
+		digest := h()
+		digest.Write(d)
+		digest.Write(i)
+		a := digest.Sum(nil)
+		for n := 1; n < iterations; n++ {
+			digest.Reset()
+			digest.Write(a)
+			a = digest.Sum(a[:0])
+		}
+		output = append(output, a...)
+
+		// Add B + 1 to every block of I, as v byte integers
+		b := fill(a)[:v]
+		for j := 0; j < len(i); j += v {
+			carry := 1
+			for k := v - 1; k >= 0; k-- {
+				sum := int(i[j+k]) + int(b[k]) + carry
+				i[j+k] = byte(sum)
+				carry = sum >> 8
+			}
+		}
+	}
+	return output[:size]
+}
+
+// bmpPassword encodes the password as a null terminated big endian UTF-16
+// string
+func bmpPassword(password string) []byte {
+	output := []byte{}
+	for _, r := range utf16.Encode([]rune(password)) {
+		output = append(output, byte(r>>8), byte(r))
+	}
+	return append(output, 0, 0)
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodePKCS12(t *testing.T) {
+	// client.p12 uses PBES2 with AES-256 and client-legacy.p12 uses 3DES
+	// and RC2, as written by openssl with and without -legacy
+	for _, file := range []string{"testdata/client.p12", "testdata/client-legacy.p12"} {
+		data, err := ioutil.ReadFile(file)
+		assert.Nil(t, err)
+		key, certs, err := decodePKCS12(data, "secret")
+		assert.Nil(t, err, file)
+		assert.IsType(t, &rsa.PrivateKey{}, key)
+		assert.Equal(t, 1, len(certs))
+		assert.Equal(t, "poster-client", certs[0].Subject.CommonName)
+
+		_, _, err = decodePKCS12(data, "wrong")
+		assert.Equal(t, errorPKCS12BadPassword, err)
+	}
+}
+
+func TestDecodePKCS12Chain(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/client-chain.p12")
+	assert.Nil(t, err)
+	key, certs, err := decodePKCS12(data, "")
+	assert.Nil(t, err)
+	assert.IsType(t, &ecdsa.PrivateKey{}, key)
+	assert.Equal(t, 2, len(certs))
+
+	leaf, chain := splitCertificateChain(key, certs)
+	assert.Equal(t, "poster-leaf", leaf.Subject.CommonName)
+	assert.Equal(t, 1, len(chain))
+	assert.Equal(t, "poster-ca", chain[0].Subject.CommonName)
+}
+
+// FuzzDecodePKCS12 checks malformed files return an error instead of
+// panicking. Run it with go test -fuzz FuzzDecodePKCS12.
+func FuzzDecodePKCS12(f *testing.F) {
+	for _, file := range []string{"testdata/client.p12", "testdata/client-legacy.p12", "testdata/client-chain.p12"} {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data, "secret")
+		f.Add(data[:len(data)/2], "")
+		f.Add(data[:len(data)-1], "secret")
+	}
+	f.Fuzz(func(t *testing.T, data []byte, password string) {
+		key, certs, err := decodePKCS12(data, password)
+		if err == nil && key == nil && len(certs) == 0 {
+			t.Errorf("decoded %d bytes without a key or certificate", len(data))
+		}
+	})
+}
@@ -0,0 +1,156 @@
+package models
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+var (
+	// promptAnswers caches the answers of this run by name@environment, so
+	// each prompt variable is only asked once
+	promptAnswers = make(map[string]string)
+
+	promptIsTerminal           = func() bool { return isTerminal(os.Stdin) }
+	promptReader               = bufio.NewReader(os.Stdin)
+	promptWriter     io.Writer = os.Stderr
+)
+
+// promptValue asks the user for the value of a prompt variable on the
+// terminal, or returns the answer given earlier in this run
+func (v *Variable) promptValue() (string, error) {
+	key := v.Name + "@" + v.Environment.Name
+	if answer, ok := promptAnswers[key]; ok {
+		return answer, nil
+	}
+	if !promptIsTerminal() {
+		return "", fmt.Errorf("variable %s prompts for its value, but stdin is not a terminal "+
+			"(use --variable %s=VALUE instead)", v.Name, v.Name)
+	}
+
+	generator := v.Generator
+	if generator == nil {
+		generator = &VariableGenerator{}
+	}
+	message := generator.Prompt
+	if message == "" {
+		message = fmt.Sprintf("Enter %s (%s)", v.Name, v.Environment.Name)
+	}
+	for i, choice := range generator.Choices {
+		if i == 0 {
+			fmt.Fprintln(promptWriter, message)
+			message = "Choose"
+		}
+		fmt.Fprintf(promptWriter, "  %d) %s\n", i+1, choice)
+	}
+	if generator.Default != "" {
+		message += fmt.Sprintf(" [%s]", generator.Default)
+	}
+
+	for {
+		fmt.Fprintf(promptWriter, "%s: ", message)
+		answer, err := readAnswer(v.Secret)
+		if err != nil {
+			return "", err
+		}
+		if answer == "" {
+			answer = generator.Default
+		}
+		if len(generator.Choices) > 0 {
+			answer = matchChoice(answer, generator.Choices)
+			if answer == "" {
+				fmt.Fprintln(promptWriter, "Please enter one of the choices")
+				continue
+			}
+		}
+		if v.Secret {
+			rememberSecret(answer)
+		}
+		promptAnswers[key] = answer
+		return answer, nil
+	}
+}
+
+// readAnswer reads a line from the terminal, without echoing it if hidden
+func readAnswer(hidden bool) (string, error) {
+	if hidden {
+		if err := setEcho(false); err == nil {
+			defer func() {
+				setEcho(true)
+				fmt.Fprintln(promptWriter)
+			}()
+		}
+	}
+	line, err := promptReader.ReadString('\n')
+	if err == io.EOF && line == "" {
+		return "", errorNoAnswer
+	} else if err != nil && err != io.EOF {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// matchChoice returns the choice selected by answer, which is either the
+// choice or its number, or an empty string if there is no match
+func matchChoice(answer string, choices []string) string {
+	for _, choice := range choices {
+		if answer == choice {
+			return choice
+		}
+	}
+	if i, err := strconv.Atoi(answer); err == nil && i >= 1 && i <= len(choices) {
+		return choices[i-1]
+	}
+	return ""
+}
+
+// isTerminal returns whether f is a terminal. Character devices like
+// /dev/null are not.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	cmd := exec.Command("stty", "-g")
+	cmd.Stdin = f
+	return cmd.Run() == nil
+}
+func setEcho(on bool) error {
+	arg := "-echo"
+	if on {
+		arg = "echo"
+	}
+	cmd := exec.Command("stty", arg)
+	cmd.Stdin = os.Stdin
+	return cmd.Run()
+}
+
+// promptGenerator and parsePromptGenerator convert the prompt settings to
+// and from the generator column
+func promptGenerator(g *VariableGenerator) string {
+	data, _ := json.Marshal(struct {
+		Prompt  string   `json:"prompt,omitempty"`
+		Default string   `json:"default,omitempty"`
+		Choices []string `json:"choices,omitempty"`
+	}{g.Prompt, g.Default, g.Choices})
+	return string(data)
+}
+func parsePromptGenerator(s string, g *VariableGenerator) {
+	settings := struct {
+		Prompt  string   `json:"prompt"`
+		Default string   `json:"default"`
+		Choices []string `json:"choices"`
+	}{}
+	if err := json.Unmarshal([]byte(s), &settings); err != nil {
+		log.Warnf("invalid prompt settings %q: %+v\n", s, err)
+		return
+	}
+	g.Prompt = settings.Prompt
+	g.Default = settings.Default
+	g.Choices = settings.Choices
+}
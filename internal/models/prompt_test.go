@@ -0,0 +1,87 @@
+package models
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func patchPrompt(input string, terminal bool) *bytes.Buffer {
+	output := &bytes.Buffer{}
+	promptAnswers = make(map[string]string)
+	promptIsTerminal = func() bool { return terminal }
+	promptReader = bufio.NewReader(strings.NewReader(input))
+	promptWriter = output
+	return output
+}
+
+func TestPromptValue(t *testing.T) {
+	output := patchPrompt("123456\n", true)
+	variable := Variable{
+		Name:        "otp",
+		Type:        PromptType,
+		Environment: Environment{Name: "local"},
+		Generator:   &VariableGenerator{},
+	}
+	value, err := variable.lookupValue()
+	assert.Nil(t, err)
+	assert.Equal(t, "123456", value)
+	assert.Equal(t, "Enter otp (local): ", output.String())
+
+	// The answer is cached for the run
+	value, err = variable.lookupValue()
+	assert.Nil(t, err)
+	assert.Equal(t, "123456", value)
+}
+func TestPromptValueChoices(t *testing.T) {
+	output := patchPrompt("nope\n2\n", true)
+	variable := Variable{
+		Name:        "region",
+		Type:        PromptType,
+		Environment: Environment{Name: "local"},
+		Generator: &VariableGenerator{
+			Prompt:  "Region",
+			Default: "eu",
+			Choices: []string{"eu", "us"},
+		},
+	}
+	value, err := variable.promptValue()
+	assert.Nil(t, err)
+	assert.Equal(t, "us", value)
+	expected := `Region
+  1) eu
+  2) us
+Choose [eu]: Please enter one of the choices
+Choose [eu]: `
+	assert.Equal(t, expected, output.String())
+
+	patchPrompt("\n", true)
+	value, err = variable.promptValue()
+	assert.Nil(t, err)
+	assert.Equal(t, "eu", value)
+}
+func TestPromptValueNotTerminal(t *testing.T) {
+	patchPrompt("", false)
+	variable := Variable{Name: "otp", Type: PromptType, Environment: Environment{Name: "local"}}
+	_, err := variable.promptValue()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "not a terminal")
+}
+func TestPromptGenerator(t *testing.T) {
+	variable := Variable{
+		Name: "region",
+		Type: PromptType,
+		Generator: &VariableGenerator{
+			Prompt:  "Region",
+			Default: "eu",
+			Choices: []string{"eu", "us"},
+		},
+	}
+	converted := convertToVariable(*variable.ToStore())
+	assert.Equal(t, variable.Generator.Prompt, converted.Generator.Prompt)
+	assert.Equal(t, variable.Generator.Default, converted.Generator.Default)
+	assert.Equal(t, variable.Generator.Choices, converted.Generator.Choices)
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test vectors of RFC 2268
+func TestRC2(t *testing.T) {
+	tests := []struct {
+		key, plain, cipher string
+		bits               int
+	}{
+		{"0000000000000000", "0000000000000000", "ebb773f993278eff", 63},
+		{"ffffffffffffffff", "ffffffffffffffff", "278b27e42e2f0d49", 64},
+		{"3000000000000000", "1000000000000001", "30649edf9be7d2c2", 64},
+		{"88", "0000000000000000", "61a8a244adacccf0", 64},
+		{"88bca90e90875a", "0000000000000000", "6ccf4308974c267f", 64},
+	}
+	for _, test := range tests {
+		key, _ := hex.DecodeString(test.key)
+		plain, _ := hex.DecodeString(test.plain)
+		block := newRC2Cipher(key, test.bits)
+		output := make([]byte, 8)
+		block.Encrypt(output, plain)
+		assert.Equal(t, test.cipher, hex.EncodeToString(output))
+		block.Decrypt(output, output)
+		assert.Equal(t, test.plain, hex.EncodeToString(output))
+	}
+}
@@ -0,0 +1,148 @@
+package models
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	// KeyFileEnv and PassphraseEnv name the environment variables the key
+	// of secret variables is derived from, in order of precedence
+	KeyFileEnv    = "POSTER_KEY_FILE"
+	PassphraseEnv = "POSTER_PASSPHRASE"
+	// SecretMask is shown instead of the value of a secret variable
+	SecretMask = "********"
+
+	secretPrefix     = "encrypted:v1:"
+	secretSaltSize   = 16
+	secretIterations = 100000
+)
+
+var (
+	// secretKeys caches the keys derived for each salt
+	secretKeys = make(map[string][]byte)
+	// secretValues are the decrypted values of this run, which are masked in
+	// logs and history
+	secretValues = make(map[string]bool)
+)
+
+// isEncrypted returns whether value was returned by encryptSecret
+func isEncrypted(value string) bool {
+	return strings.HasPrefix(value, secretPrefix)
+}
+
+// encryptSecret encrypts value with AES-GCM using a key derived from the
+// passphrase and a random salt
+func encryptSecret(value string) (string, error) {
+	salt := make([]byte, secretSaltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return "", err
+	}
+	gcm, err := secretCipher(salt)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	data := append(salt, nonce...)
+	data = gcm.Seal(data, nonce, []byte(value), nil)
+	return secretPrefix + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// decryptSecret reverses encryptSecret and remembers the value so it can be
+// masked
+func decryptSecret(value string) (string, error) {
+	if !isEncrypted(value) {
+		return "", errorInvalidSecret
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, secretPrefix))
+	if err != nil || len(data) < secretSaltSize {
+		return "", errorInvalidSecret
+	}
+	salt, data := data[:secretSaltSize], data[secretSaltSize:]
+	gcm, err := secretCipher(salt)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", errorInvalidSecret
+	}
+	nonce, data := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plain, err := gcm.Open(nil, nonce, data, nil)
+	if err != nil {
+		// Most likely the wrong passphrase
+		return "", errorDecryptSecretFailed
+	}
+	rememberSecret(string(plain))
+	return string(plain), nil
+}
+
+func secretCipher(salt []byte) (cipher.AEAD, error) {
+	key, ok := secretKeys[string(salt)]
+	if !ok {
+		passphrase, err := secretPassphrase()
+		if err != nil {
+			return nil, err
+		}
+		key = pbkdf2.Key(passphrase, salt, secretIterations, 32, sha256.New)
+		secretKeys[string(salt)] = key
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// secretPassphrase reads the contents of the key file, or the passphrase
+func secretPassphrase() ([]byte, error) {
+	if path := os.Getenv(KeyFileEnv); path != "" {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		data = []byte(strings.TrimSpace(string(data)))
+		if len(data) == 0 {
+			return nil, errorMissingSecretKey
+		}
+		return data, nil
+	}
+	if passphrase := os.Getenv(PassphraseEnv); passphrase != "" {
+		return []byte(passphrase), nil
+	}
+	return nil, errorMissingSecretKey
+}
+
+func rememberSecret(value string) {
+	if value != "" {
+		secretValues[value] = true
+	}
+}
+
+// maskSecrets replaces the secret values of this run in s
+func maskSecrets(s string) string {
+	for value := range secretValues {
+		s = strings.Replace(s, value, SecretMask, -1)
+	}
+	return s
+}
+
+// maskValue returns SecretMask instead of a value that is set, like the value
+// of a secret variable is printed
+func maskValue(value string) string {
+	if value == "" {
+		return ""
+	}
+	return SecretMask
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptSecret(t *testing.T) {
+	os.Setenv(PassphraseEnv, "hunter2")
+	defer os.Unsetenv(PassphraseEnv)
+
+	encrypted, err := encryptSecret("token")
+	assert.Nil(t, err)
+	assert.True(t, isEncrypted(encrypted))
+	assert.NotContains(t, encrypted, "token")
+
+	again, err := encryptSecret("token")
+	assert.Nil(t, err)
+	assert.NotEqual(t, encrypted, again)
+
+	value, err := decryptSecret(encrypted)
+	assert.Nil(t, err)
+	assert.Equal(t, "token", value)
+	assert.Equal(t, "Bearer "+SecretMask, maskSecrets("Bearer token"))
+
+	// Derived keys are cached by salt
+	secretKeys = make(map[string][]byte)
+	os.Setenv(PassphraseEnv, "wrong")
+	_, err = decryptSecret(encrypted)
+	assert.Equal(t, errorDecryptSecretFailed, err)
+
+	os.Unsetenv(PassphraseEnv)
+	_, err = encryptSecret("token")
+	assert.Equal(t, errorMissingSecretKey, err)
+	_, err = decryptSecret("token")
+	assert.Equal(t, errorInvalidSecret, err)
+}
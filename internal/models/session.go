@@ -0,0 +1,237 @@
+package models
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mcastorina/poster/internal/cache"
+)
+
+// Session is a named cookie jar of an environment. Requests run with a
+// session send its cookies and save the cookies they receive, so a login
+// is kept across runs. A session without a name is not saved, it only
+// shares cookies between the steps of a suite.
+type Session struct {
+	Name        string
+	Environment Environment
+	Cookies     []Cookie
+}
+
+// Cookie is a cookie of a session. Expires is zero for cookies without an
+// expiry, which are kept until the session is cleared.
+type Cookie struct {
+	Name     string
+	Value    string
+	Domain   string
+	Path     string
+	HostOnly bool
+	Secure   bool
+	HTTPOnly bool
+	Expires  time.Time
+}
+
+var sessionName string
+
+// suiteSession is the session shared by the steps of the suite being run
+var suiteSession *Session
+
+// UseSession makes every request run with the named session of its
+// environment
+func UseSession(name string) {
+	sessionName = name
+}
+
+// GetSession returns the named session of environment e, which has no
+// cookies if it was never used
+func GetSession(name string, e Environment) Session {
+	session := Session{Name: name, Environment: e, Cookies: []Cookie{}}
+	for _, cookie := range cache.GetCookiesBySession(name, e.Name) {
+		session.Cookies = append(session.Cookies, convertToCookie(cookie))
+	}
+	return session
+}
+
+// GetAllSessions returns every session that has cookies
+func GetAllSessions() []Session {
+	sessions := []Session{}
+	for _, cookie := range cache.GetAllCookies() {
+		last := len(sessions) - 1
+		if last < 0 || sessions[last].Name != cookie.Session ||
+			sessions[last].Environment.Name != cookie.Environment {
+			sessions = append(sessions, Session{
+				Name:        cookie.Session,
+				Environment: Environment{Name: cookie.Environment},
+				Cookies:     []Cookie{},
+			})
+			last++
+		}
+		sessions[last].Cookies = append(sessions[last].Cookies, convertToCookie(cookie))
+	}
+	return sessions
+}
+
+// Save replaces the stored cookies of the session, except the expired ones
+func (s *Session) Save() error {
+	if s.Name == "" {
+		return nil
+	}
+	s.removeExpired(time.Now())
+	return cache.StoreSession(s.Name, s.Environment.Name, s.ToStore())
+}
+
+// Clear removes every cookie of the session
+func (s *Session) Clear() error {
+	s.Cookies = []Cookie{}
+	return cache.DeleteSession(s.Name, s.Environment.Name)
+}
+
+// currentSession returns the session of requests run in environment e, or
+// nil if cookies are not kept
+func currentSession(e Environment) *Session {
+	if suiteSession != nil && suiteSession.Environment.Name == e.Name {
+		return suiteSession
+	}
+	if sessionName == "" {
+		return nil
+	}
+	session := GetSession(sessionName, e)
+	return &session
+}
+
+// withJar returns a copy of client that keeps its cookies in session s
+func withJar(client *http.Client, s *Session) *http.Client {
+	jarClient := *client
+	jarClient.Jar = sessionJar{s}
+	return &jarClient
+}
+
+func (s *Session) removeExpired(now time.Time) {
+	cookies := []Cookie{}
+	for _, cookie := range s.Cookies {
+		if !cookie.expired(now) {
+			cookies = append(cookies, cookie)
+		}
+	}
+	s.Cookies = cookies
+}
+func (c Cookie) expired(now time.Time) bool {
+	return !c.Expires.IsZero() && !c.Expires.After(now)
+}
+
+// sessionJar implements http.CookieJar with the cookies of a session, as
+// described by RFC 6265. Unlike net/http/cookiejar, there is no public
+// suffix list, so a cookie may be set for a domain like co.uk.
+type sessionJar struct {
+	session *Session
+}
+
+func (j sessionJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	host := strings.ToLower(u.Hostname())
+	now := time.Now()
+	for _, c := range cookies {
+		cookie := Cookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   host,
+			Path:     c.Path,
+			HostOnly: true,
+			Secure:   c.Secure,
+			HTTPOnly: c.HttpOnly,
+		}
+		if c.Domain != "" {
+			domain := strings.ToLower(strings.TrimPrefix(c.Domain, "."))
+			if !domainMatch(host, domain) {
+				// Servers cannot set cookies of other domains
+				continue
+			}
+			cookie.Domain, cookie.HostOnly = domain, false
+		}
+		if !strings.HasPrefix(cookie.Path, "/") {
+			cookie.Path = defaultCookiePath(u.Path)
+		}
+		switch {
+		case c.MaxAge < 0:
+			cookie.Expires = now
+		case c.MaxAge > 0:
+			cookie.Expires = now.Add(time.Duration(c.MaxAge) * time.Second)
+		case !c.Expires.IsZero():
+			cookie.Expires = c.Expires
+		}
+		j.set(cookie, now)
+	}
+}
+func (j sessionJar) Cookies(u *url.URL) []*http.Cookie {
+	host := strings.ToLower(u.Hostname())
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+	now := time.Now()
+	matches := []Cookie{}
+	for _, cookie := range j.session.Cookies {
+		if cookie.expired(now) || (cookie.Secure && u.Scheme != "https") {
+			continue
+		}
+		if (cookie.HostOnly && cookie.Domain != host) || !domainMatch(host, cookie.Domain) {
+			continue
+		}
+		if !pathMatch(path, cookie.Path) {
+			continue
+		}
+		matches = append(matches, cookie)
+	}
+	// Cookies with longer paths are sent first
+	sort.SliceStable(matches, func(i, k int) bool {
+		return len(matches[i].Path) > len(matches[k].Path)
+	})
+	cookies := []*http.Cookie{}
+	for _, cookie := range matches {
+		cookies = append(cookies, &http.Cookie{Name: cookie.Name, Value: cookie.Value})
+	}
+	return cookies
+}
+
+// set replaces the cookie with the same name, domain and path, or removes
+// it if the new cookie is expired
+func (j sessionJar) set(cookie Cookie, now time.Time) {
+	cookies := []Cookie{}
+	for _, c := range j.session.Cookies {
+		if c.Name != cookie.Name || c.Domain != cookie.Domain || c.Path != cookie.Path {
+			cookies = append(cookies, c)
+		}
+	}
+	if !cookie.expired(now) {
+		cookies = append(cookies, cookie)
+	}
+	j.session.Cookies = cookies
+}
+
+// domainMatch reports whether host is domain or one of its subdomains
+func domainMatch(host, domain string) bool {
+	if host == domain {
+		return true
+	}
+	return net.ParseIP(host) == nil && strings.HasSuffix(host, "."+domain)
+}
+
+// pathMatch reports whether the cookie path is path or one of its parents
+func pathMatch(path, cookiePath string) bool {
+	if !strings.HasPrefix(path, cookiePath) {
+		return false
+	}
+	return len(path) == len(cookiePath) || strings.HasSuffix(cookiePath, "/") ||
+		path[len(cookiePath)] == '/'
+}
+
+// defaultCookiePath returns the directory of the request path
+func defaultCookiePath(path string) string {
+	i := strings.LastIndex(path, "/")
+	if i <= 0 {
+		return "/"
+	}
+	return path[:i]
+}
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionJar(t *testing.T) {
+	session := &Session{}
+	jar := sessionJar{session}
+	login, _ := url.Parse("http://api.example.com/auth/login")
+	jar.SetCookies(login, []*http.Cookie{
+		{Name: "sid", Value: "1"},
+		{Name: "theme", Value: "dark", Path: "/", Domain: ".example.com"},
+		{Name: "secure", Value: "1", Path: "/", Secure: true},
+		{Name: "old", Value: "1", Path: "/", MaxAge: 60},
+		{Name: "other", Value: "1", Domain: "other.com"},
+	})
+	assert.Equal(t, 4, len(session.Cookies))
+
+	cookieNames := func(rawurl string) []string {
+		u, _ := url.Parse(rawurl)
+		names := []string{}
+		for _, cookie := range jar.Cookies(u) {
+			names = append(names, cookie.Name)
+		}
+		return names
+	}
+	// sid has the default path /auth and is only sent to api.example.com
+	assert.Equal(t, []string{"sid", "theme", "old"}, cookieNames("http://api.example.com/auth/me"))
+	assert.Equal(t, []string{"theme", "old"}, cookieNames("http://api.example.com/authors"))
+	assert.Equal(t, []string{"theme"}, cookieNames("http://www.example.com/"))
+	assert.Equal(t, []string{"theme", "secure", "old"}, cookieNames("https://api.example.com/"))
+
+	// Expired cookies remove the stored one
+	jar.SetCookies(login, []*http.Cookie{{Name: "old", Path: "/", MaxAge: -1}})
+	assert.Equal(t, []string{"theme"}, cookieNames("http://api.example.com/"))
+	jar.SetCookies(login, []*http.Cookie{{Name: "sid", Value: "2"}})
+	assert.Equal(t, []string{"sid", "theme"}, cookieNames("http://api.example.com/auth/me"))
+	assert.Equal(t, 3, len(session.Cookies))
+}
+
+func TestSessionSave(t *testing.T) {
+	env := Environment{Name: "session-save"}
+	assert.Nil(t, env.Save())
+
+	expires := time.Now().Add(time.Hour).Truncate(time.Second)
+	session := GetSession("alice", env)
+	assert.Equal(t, 0, len(session.Cookies))
+	session.Cookies = []Cookie{
+		{Name: "sid", Value: "1", Domain: "localhost", Path: "/", HostOnly: true, HTTPOnly: true},
+		{Name: "remember", Value: "1", Domain: "localhost", Path: "/", Expires: expires},
+		{Name: "expired", Value: "1", Domain: "localhost", Path: "/", Expires: time.Now().Add(-time.Hour)},
+	}
+	assert.Nil(t, session.Save())
+
+	saved := GetSession("alice", env)
+	assert.Equal(t, 2, len(saved.Cookies))
+	assert.Equal(t, "remember", saved.Cookies[0].Name)
+	assert.True(t, expires.Equal(saved.Cookies[0].Expires))
+	assert.Equal(t, session.Cookies[0], saved.Cookies[1])
+	assert.Equal(t, 0, len(GetSession("bob", env).Cookies))
+
+	found := false
+	for _, s := range GetAllSessions() {
+		if s.Name == "alice" && s.Environment.Name == env.Name {
+			found = true
+			assert.Equal(t, 2, len(s.Cookies))
+		}
+	}
+	assert.True(t, found)
+
+	assert.Nil(t, saved.Clear())
+	assert.Equal(t, 0, len(GetSession("alice", env).Cookies))
+}
+
+func TestSessionRun(t *testing.T) {
+	defer UseSession("")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			http.SetCookie(w, &http.Cookie{Name: "sid", Value: "alice", Path: "/"})
+			return
+		}
+		if cookie, err := r.Cookie("sid"); err != nil || cookie.Value != "alice" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	env := Environment{Name: "session-run"}
+	assert.Nil(t, env.Save())
+	login := Request{Name: "session-login", Method: "GET", URL: server.URL + "/login", Environment: env}
+	me := Request{Name: "session-me", Method: "GET", URL: server.URL + "/me", Environment: env}
+	assert.Nil(t, login.Save())
+	assert.Nil(t, me.Save())
+
+	// Without a session, cookies are not kept between runs
+	_, err := login.Run()
+	assert.Nil(t, err)
+	resp, err := me.Run()
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	// The steps of a suite share their cookies
+	suite := Suite{Name: "session-suite", Environment: env, Requests: []string{"session-login", "session-me"}}
+	resp, err = suite.Run()
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 0, len(GetSession("alice", env).Cookies))
+
+	// A named session is kept across runs
+	UseSession("alice")
+	_, err = login.Run()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(GetSession("alice", env).Cookies))
+	resp, err = me.Run()
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+var strictMode bool
+
+// EnableStrict makes runs fail before sending a request that uses variables
+// which are not defined in its environment or global
+func EnableStrict() {
+	strictMode = true
+}
+
+// UndefinedVariable is a placeholder in a part of a request, like the URL or
+// a header, without a variable
+type UndefinedVariable struct {
+	Name  string
+	Field string
+}
+
+// UndefinedVariablesError is returned in strict mode when a request uses
+// variables that are not defined
+type UndefinedVariablesError struct {
+	Request     string
+	Environment string
+	Variables   []UndefinedVariable
+}
+
+func (e *UndefinedVariablesError) Error() string {
+	variables := []string{}
+	for _, variable := range e.Variables {
+		variables = append(variables, fmt.Sprintf(":%s (%s)", variable.Name, variable.Field))
+	}
+	return fmt.Sprintf("undefined variables in %s@%s: %s", e.Request, e.Environment,
+		strings.Join(variables, ", "))
+}
+
+// UndefinedVariables returns the placeholders of the request that have no
+// variable in environment e, global or the overrides of the run
+func (r *Request) UndefinedVariables(e Environment) []UndefinedVariable {
+	defined := make(map[string]bool)
+	for _, variable := range e.GetVariablesInRequest(r) {
+		defined[variable.Name] = true
+	}
+	undefined := []UndefinedVariable{}
+	seen := make(map[UndefinedVariable]bool)
+	// The auth inherited from the environment is checked too
+	request := *r
+	request.Auth = r.authFor(e)
+	// So are the certificate and client options, which are replaced when
+	// the client is created
+	for _, field := range append(request.fields(), r.clientFields(e)...) {
+		for _, name := range placeholderNames(field[1]) {
+			variable := UndefinedVariable{Name: name, Field: field[0]}
+			if defined[name] || seen[variable] {
+				continue
+			}
+			seen[variable] = true
+			undefined = append(undefined, variable)
+		}
+	}
+	return undefined
+}
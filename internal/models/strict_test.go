@@ -0,0 +1,107 @@
+package models
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bouk/monkey"
+	"github.com/mcastorina/poster/internal/cache"
+	"github.com/mcastorina/poster/internal/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUndefinedVariables(t *testing.T) {
+	defer monkey.UnpatchAll()
+	monkey.Patch(cache.GetVariablesByEnvironment, func(environment string) []store.Variable {
+		if environment != "local" {
+			return []store.Variable{}
+		}
+		return []store.Variable{{Name: "host", Value: "localhost", Environment: "local", Type: ConstType}}
+	})
+
+	request := Request{
+		Name:    "create",
+		Method:  "POST",
+		URL:     ":host:8080/tenants/:tenant-id/:tenant-id",
+		Body:    `{"time":"12:30","user":":$base64(:user)"}`,
+		Headers: []Header{{Key: "Authorization", Value: "Bearer :token"}},
+	}
+	undefined := request.UndefinedVariables(Environment{Name: "local"})
+	assert.Equal(t, []UndefinedVariable{
+		{Name: "tenant-id", Field: "url"},
+		{Name: "token", Field: "header Authorization"},
+		{Name: "user", Field: "body"},
+	}, undefined)
+
+	err := &UndefinedVariablesError{Request: "create", Environment: "local", Variables: undefined}
+	assert.Equal(t, "undefined variables in create@local: :tenant-id (url), "+
+		":token (header Authorization), :user (body)", err.Error())
+
+	overrideVariables = []Variable{{Name: "tenant-id"}, {Name: "token"}, {Name: "user"}}
+	defer func() { overrideVariables = nil }()
+	assert.Equal(t, []UndefinedVariable{}, request.UndefinedVariables(Environment{Name: "local"}))
+}
+
+func TestUndefinedClientVariables(t *testing.T) {
+	defer monkey.UnpatchAll()
+	env := Environment{
+		Name:        "mtls",
+		Client:      ClientProfile{CACert: ":ca-file"},
+		Certificate: ClientCertificate{PKCS12: ":p12-file", Password: ":p12-password"},
+	}
+	monkey.Patch(cache.GetAllEnvironments, func() []store.Environment {
+		return []store.Environment{*env.ToStore()}
+	})
+	monkey.Patch(cache.GetVariablesByEnvironment, func(environment string) []store.Variable {
+		if environment != "mtls" {
+			return []store.Variable{}
+		}
+		return []store.Variable{{Name: "p12-file", Value: "client.p12", Environment: "mtls", Type: ConstType}}
+	})
+
+	request := Request{Name: "ledger", Method: "GET", URL: "localhost", Client: ClientProfile{Proxy: ":proxy"}}
+	assert.Equal(t, []UndefinedVariable{
+		{Name: "p12-password", Field: "pkcs12 password"},
+		{Name: "proxy", Field: "proxy"},
+		{Name: "ca-file", Field: "ca-cert"},
+	}, request.UndefinedVariables(env))
+
+	// The variables are generated before the client is created
+	variables := env.GetVariablesInRequest(&request)
+	assert.Equal(t, 1, len(variables))
+	assert.Equal(t, "p12-file", variables[0].Name)
+}
+
+func TestStrictSuite(t *testing.T) {
+	strictMode = true
+	defer func() { strictMode = false }()
+	sent := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent++
+	}))
+	defer server.Close()
+
+	env := Environment{Name: "strict-suite"}
+	assert.Nil(t, env.Save())
+	login := Request{Name: "strict-login", Method: "POST", URL: server.URL + "/login", Body: ":user", Environment: env}
+	me := Request{Name: "strict-me", Method: "GET", URL: server.URL + "/me",
+		Headers: []Header{{Key: "Authorization", Value: "Bearer :token"}}, Environment: env}
+	assert.Nil(t, login.Save())
+	assert.Nil(t, me.Save())
+
+	// Every step is checked before the first one is sent
+	suite := Suite{Name: "strict", Environment: env, Requests: []string{"strict-login", "strict-me"}}
+	_, err := suite.Run()
+	assert.Equal(t, &UndefinedVariablesError{Request: "strict", Environment: "strict-suite", Variables: []UndefinedVariable{
+		{Name: "user", Field: "strict-login: body"},
+		{Name: "token", Field: "strict-me: header Authorization"},
+	}}, err)
+	assert.Equal(t, 0, sent)
+
+	overrideVariables = []Variable{{Name: "user"}, {Name: "token"}}
+	defer func() { overrideVariables = nil }()
+	_, err = suite.Run()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, sent)
+}
@@ -0,0 +1,187 @@
+package models
+
+import "strings"
+
+type tokenKind int
+
+const (
+	textToken tokenKind = iota
+	variableToken
+	functionToken
+)
+
+// token is a part of a string that may contain placeholders:
+//
+//	\:                 an escaped colon, which is never a placeholder
+//	:name              a variable, the name starts with a letter or _
+//	:{name}            a variable with explicit boundaries
+//	:$name(args...)    a call of a built-in function
+//
+// Text tokens hold the text with escapes removed. Placeholder tokens hold
+// the placeholder as written in text, which is used when it is not replaced.
+type token struct {
+	kind     tokenKind
+	text     string
+	name     string
+	braced   bool
+	rawArgs  []string
+	function builtinFunction
+}
+
+// tokenize splits input into text and placeholders. Anything that is not a
+// valid placeholder, like the colon of a port or a time, is text.
+func tokenize(input string) []token {
+	tokens := []token{}
+	text := strings.Builder{}
+	flush := func() {
+		if text.Len() > 0 {
+			tokens = append(tokens, token{kind: textToken, text: text.String()})
+			text.Reset()
+		}
+	}
+	for i := 0; i < len(input); i++ {
+		if input[i] == '\\' && i+1 < len(input) && input[i+1] == ':' {
+			// A token of its own, so :$ is never found across an escape
+			flush()
+			tokens = append(tokens, token{kind: textToken, text: ":"})
+			i++
+			continue
+		}
+		if input[i] != ':' {
+			text.WriteByte(input[i])
+			continue
+		}
+		t, end := scanPlaceholder(input, i)
+		if end == i {
+			text.WriteByte(':')
+			continue
+		}
+		flush()
+		t.text = input[i:end]
+		tokens = append(tokens, t)
+		i = end - 1
+	}
+	flush()
+	return tokens
+}
+
+// scanPlaceholder reads the placeholder at the colon input[start], and returns
+// the index after it, or start if there is none
+func scanPlaceholder(input string, start int) (token, int) {
+	i := start + 1
+	if i >= len(input) {
+		return token{}, start
+	}
+	switch {
+	case input[i] == '$':
+		nameEnd := scanName(input, i+1)
+		name := input[i+1 : nameEnd]
+		function, ok := builtinFunctions[name]
+		if !ok {
+			return token{}, start
+		}
+		t := token{kind: functionToken, name: name, rawArgs: []string{}, function: function}
+		if nameEnd < len(input) && input[nameEnd] == '(' {
+			end, args, ok := splitFunctionArgs(input, nameEnd)
+			if !ok {
+				return token{}, start
+			}
+			t.rawArgs = args
+			return t, end
+		}
+		return t, nameEnd
+	case input[i] == '{':
+		end := strings.IndexByte(input[i:], '}')
+		if end == -1 {
+			return token{}, start
+		}
+		name := input[i+1 : i+end]
+		if !isVariableName(name) {
+			return token{}, start
+		}
+		return token{kind: variableToken, name: name, braced: true}, i + end + 1
+	case isNameStart(input[i]):
+		end := scanName(input, i)
+		// Like a word boundary, a name does not end with a hyphen
+		for input[end-1] == '-' {
+			end--
+		}
+		return token{kind: variableToken, name: input[i:end]}, end
+	}
+	return token{}, start
+}
+
+// scanName returns the index after the name characters starting at input[i]
+func scanName(input string, i int) int {
+	for i < len(input) && IsNameChar(input[i]) {
+		i++
+	}
+	return i
+}
+func isNameStart(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+// IsNameChar reports whether c can be part of a variable name, so a variable
+// followed by c has to be written as :{name}
+func IsNameChar(c byte) bool {
+	return c == '-' || isNameStart(c) || ('0' <= c && c <= '9')
+}
+
+// isVariableName reports whether name can be written as :name, which is
+// required of every variable so it never has to be braced on its own
+func isVariableName(name string) bool {
+	if name == "" || !isNameStart(name[0]) || name[len(name)-1] == '-' {
+		return false
+	}
+	return scanName(name, 0) == len(name)
+}
+
+// EscapeText escapes the colons of text that would be read as the start of a
+// placeholder, or as an escape, so the text is sent as it is
+func EscapeText(text string) string {
+	output := strings.Builder{}
+	for i := 0; i < len(text); i++ {
+		if text[i] == ':' {
+			if _, end := scanPlaceholder(text, i); end != i || (i > 0 && text[i-1] == '\\') {
+				output.WriteByte('\\')
+			}
+		}
+		output.WriteByte(text[i])
+	}
+	return output.String()
+}
+
+// VariableReference returns the placeholder of variable name when it is
+// followed by next, which is :{name} if next starts with a name character
+func VariableReference(name, next string) string {
+	if next != "" && IsNameChar(next[0]) {
+		return ":{" + name + "}"
+	}
+	return ":" + name
+}
+
+// walkTokens calls f for every token of input, including the tokens in the
+// arguments of function calls, which are visited before the call
+func walkTokens(input string, f func(t token)) {
+	for _, t := range tokenize(input) {
+		if t.kind == functionToken {
+			for _, arg := range t.rawArgs {
+				walkTokens(unescapeFunctionArg(arg), f)
+			}
+		}
+		f(t)
+	}
+}
+
+// placeholderNames returns the names of the variables used in input, in
+// order of appearance
+func placeholderNames(input string) []string {
+	names := []string{}
+	walkTokens(input, func(t token) {
+		if t.kind == variableToken {
+			names = append(names, t.name)
+		}
+	})
+	return names
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/bouk/monkey"
+	"github.com/mcastorina/poster/internal/cache"
+	"github.com/mcastorina/poster/internal/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlaceholderNames(t *testing.T) {
+	tests := map[string][]string{
+		"http://localhost:8080/items":        {},
+		`{"a":1,"b":"12:30:45"}`:             {},
+		":host/:port-name/:id-":              {"host", "port-name", "id"},
+		":{port}-name:{id}x":                 {"port", "id"},
+		`\:host \\:{a} ::b :-c :{d e}`:       {"b"},
+		":$base64(:user::pass) :$nope(:x)":   {"user", "pass", "x"},
+		":$hmac(:$sha256(:body),\\:literal)": {"body"},
+	}
+	for input, expected := range tests {
+		assert.Equal(t, expected, placeholderNames(input), input)
+	}
+}
+func TestVariableNames(t *testing.T) {
+	for _, name := range []string{"host", "_id", "port-name", "a1_b-2"} {
+		variable := Variable{Name: name, Type: ConstType, Environment: Environment{Name: "global"}}
+		assert.Nil(t, variable.Validate(), name)
+		assert.Equal(t, []string{name}, placeholderNames(":"+name), name)
+	}
+	// Names the tokenizer would not read as a placeholder are rejected
+	for _, name := range []string{"", "1st", "-x", "x-", "a.b", "a b"} {
+		variable := Variable{Name: name, Type: ConstType, Environment: Environment{Name: "global"}}
+		assert.Equal(t, errorInvalidCharacters, variable.Validate(), name)
+		assert.Equal(t, []string{}, placeholderNames(":{"+name+"}"), name)
+	}
+}
+func TestEscapeText(t *testing.T) {
+	tests := map[string]string{
+		"http://localhost:8080/items":    "http://localhost:8080/items",
+		`{"isbn":"urn:isbn:0451450523"}`: `{"isbn":"urn\:isbn:0451450523"}`,
+		`a:b :{c} :$uuid \:d \:8`:        `a\:b \:{c} \:$uuid \\:d \\:8`,
+	}
+	for input, expected := range tests {
+		escaped := EscapeText(input)
+		assert.Equal(t, expected, escaped, input)
+		// The escaped text has no placeholders and reads as the input
+		text := ""
+		for _, token := range tokenize(escaped) {
+			assert.Equal(t, textToken, token.kind, input)
+			text += token.text
+		}
+		assert.Equal(t, input, text)
+	}
+
+	assert.Equal(t, ":id", VariableReference("id", ".json"))
+	assert.Equal(t, ":id", VariableReference("id", ""))
+	assert.Equal(t, ":{id}", VariableReference("id", "-x"))
+	assert.Equal(t, ":{id}", VariableReference("id", "abc"))
+}
+func TestReplaceVariablesTokens(t *testing.T) {
+	defer monkey.UnpatchAll()
+	monkey.Patch(cache.GetVariablesByEnvironment, func(environment string) []store.Variable {
+		return []store.Variable{
+			{Name: "port", Value: "8080", Environment: "local"},
+			{Name: "true", Value: "false", Environment: "local"},
+		}
+	})
+	env := Environment{Name: "local"}
+
+	tests := map[string]string{
+		"localhost::port/:port-name":  "localhost:8080/:port-name",
+		"localhost::{port}-name":      "localhost:8080-name",
+		`{"a":1,"b"\:true,"c"::true}`: `{"a":1,"b":true,"c":false}`,
+		`\:port \x :{missing}`:        `:port \x :{missing}`,
+	}
+	for input, expected := range tests {
+		assert.Equal(t, expected, env.ReplaceVariables(input), input)
+	}
+}
+func TestLintPlaceholders(t *testing.T) {
+	defined := map[string]bool{"port": true, "host": true}
+	assert.Equal(t, []string{
+		":port-name is not a variable, but :port is (write :{port}-name)",
+		`:tenant is not a variable in any environment (write \:tenant if it is not a placeholder)`,
+		":$nope is not a built-in function",
+	}, lintPlaceholders(`:host::port-name/:tenant/:{port}-x/\:escaped/:$nope()/\:$escaped`, defined))
+}
@@ -11,8 +11,15 @@ var (
 )
 
 func GetRunnableResourceByName(name string) (Runnable, error) {
-	resource, err := GetRequestByName(name)
-	return &resource, err
+	if sRequest, err := cache.GetRequestByName(name); err == nil {
+		request := convertToRequest(sRequest)
+		return &request, nil
+	}
+	if sSuite, err := cache.GetSuiteByName(name); err == nil {
+		suite := convertToSuite(sSuite)
+		return &suite, nil
+	}
+	return nil, errorResourceNotFound
 }
 
 func GetAllRequests() []Request {
@@ -52,6 +59,59 @@ func GetRequestsByEnvironmentAndMethod(env, method string) []Request {
 	return requests
 }
 
+func GetAllSuites() []Suite {
+	suites := []Suite{}
+	for _, sSuite := range cache.GetAllSuites() {
+		suites = append(suites, convertToSuite(sSuite))
+	}
+	return suites
+}
+func GetSuiteByName(name string) (Suite, error) {
+	sSuite, err := cache.GetSuiteByName(name)
+	if err != nil {
+		log.Errorf("%+v\n", err)
+		return Suite{}, err
+	}
+	return convertToSuite(sSuite), nil
+}
+func GetSuitesByEnvironment(env string) []Suite {
+	suites := []Suite{}
+	for _, sSuite := range cache.GetSuitesByEnvironment(env) {
+		suites = append(suites, convertToSuite(sSuite))
+	}
+	return suites
+}
+
+func GetAllResponses() []Response {
+	responses := []Response{}
+	for _, sResponse := range cache.GetAllResponses() {
+		responses = append(responses, convertToResponse(sResponse))
+	}
+	return responses
+}
+func GetResponseByID(id int64) (Response, error) {
+	sResponse, err := cache.GetResponseByID(id)
+	if err != nil {
+		log.Errorf("%+v\n", err)
+		return Response{}, err
+	}
+	return convertToResponse(sResponse), nil
+}
+func GetBaseline(request, environment string) (Response, error) {
+	sResponse, err := cache.GetBaselineByRequestAndEnvironment(request, environment)
+	if err != nil {
+		return Response{}, err
+	}
+	return convertToResponse(sResponse), nil
+}
+func GetResponsesByRequest(name string) []Response {
+	responses := []Response{}
+	for _, sResponse := range cache.GetResponsesByRequest(name) {
+		responses = append(responses, convertToResponse(sResponse))
+	}
+	return responses
+}
+
 func GetAllEnvironments() []Environment {
 	envs := []Environment{}
 	for _, sEnvironment := range cache.GetAllEnvironments() {
@@ -0,0 +1,95 @@
+package store
+
+// Cookie is a cookie of a session, which is a named cookie jar of an
+// environment
+type Cookie struct {
+	Session     string
+	Environment string
+	Name        string
+	Value       string
+	Domain      string
+	Path        string
+	HostOnly    bool `db:"host_only"`
+	Secure      bool
+	HTTPOnly    bool  `db:"http_only"`
+	Expires     int64 // unix time in seconds, 0 for cookies without an expiry
+}
+
+// StoreSession replaces the cookies of a session
+func StoreSession(session, environment string, cookies []Cookie) error {
+	tx := globalDB.MustBegin()
+	if _, err := tx.Exec("DELETE FROM cookies WHERE session=$1 AND environment=$2",
+		session, environment); err != nil {
+		log.Errorf("%+v\n", err)
+		tx.Rollback()
+		return ErrorUnknown
+	}
+	for _, cookie := range cookies {
+		cookie.Session, cookie.Environment = session, environment
+		if _, err := tx.NamedExec(
+			`INSERT INTO cookies
+			(session, environment, name, value, domain, path, host_only, secure, http_only, expires)
+			VALUES (:session, :environment, :name, :value, :domain, :path, :host_only, :secure,
+			:http_only, :expires)`,
+			&cookie); err != nil {
+			log.Errorf("%+v\n", err)
+			tx.Rollback()
+			return ErrorUnknown
+		}
+	}
+	return tx.Commit()
+}
+
+// DeleteSession deletes the cookies of a session
+func DeleteSession(session, environment string) error {
+	_, err := globalDB.Exec("DELETE FROM cookies WHERE session=$1 AND environment=$2",
+		session, environment)
+	if err != nil {
+		log.Errorf("%+v\n", err)
+		return ErrorUnknown
+	}
+	return nil
+}
+
+func GetAllCookies() []Cookie {
+	cookies := []Cookie{}
+	if err := globalDB.Select(&cookies,
+		"SELECT * FROM cookies ORDER BY session, environment, domain, path, name"); err != nil {
+		log.Errorf("%+v\n", err)
+	}
+	return cookies
+}
+func GetCookiesBySession(session, environment string) []Cookie {
+	cookies := []Cookie{}
+	if err := globalDB.Select(&cookies,
+		"SELECT * FROM cookies WHERE session=$1 AND environment=$2 ORDER BY domain, path, name",
+		session, environment); err != nil {
+		log.Errorf("%+v\n", err)
+	}
+	return cookies
+}
+
+func createCookiesTable() {
+	// create cookies table if not exists
+	query := `
+	CREATE TABLE IF NOT EXISTS cookies(
+		session TEXT NOT NULL,
+		environment TEXT NOT NULL,
+		name TEXT NOT NULL,
+		value TEXT NOT NULL,
+		domain TEXT NOT NULL,
+		path TEXT NOT NULL,
+		host_only BOOLEAN NOT NULL DEFAULT 0,
+		secure BOOLEAN NOT NULL DEFAULT 0,
+		http_only BOOLEAN NOT NULL DEFAULT 0,
+		expires INT NOT NULL DEFAULT 0,
+		PRIMARY KEY(session, environment, domain, path, name),
+		FOREIGN KEY(environment) REFERENCES environments(name) ON DELETE CASCADE
+	);
+	`
+
+	_, err := globalDB.Exec(query)
+	if err != nil {
+		panic(err)
+	}
+}
@@ -5,7 +5,11 @@ import (
 )
 
 type Environment struct {
-	Name string
+	Name        string
+	Parent      string
+	Client      string // JSON encoded client profile
+	Certificate string // JSON encoded client certificate
+	Auth        string // JSON encoded auth
 }
 
 func (e *Environment) Save() error {
@@ -37,7 +41,10 @@ func StoreEnvironments(envs []Environment) error {
 
 	for _, env := range envs {
 		if _, err := tx.NamedExec(
-			"INSERT OR REPLACE INTO environments (name) VALUES (:name)",
+			`INSERT INTO environments (name, parent, client, certificate, auth)
+			VALUES (:name, :parent, :client, :certificate, :auth)
+			ON CONFLICT(name) DO UPDATE SET parent=excluded.parent, client=excluded.client,
+			certificate=excluded.certificate, auth=excluded.auth`,
 			&env); err != nil {
 
 			if sqliteErr, ok := err.(sqlite3.Error); ok {
@@ -68,14 +75,15 @@ func GetEnvironmentByName(name string) (Environment, error) {
 	return environment, nil
 }
 
-func init() {
-	if globalDB == nil {
-		initDB()
-	}
+func createEnvironmentsTable() {
 	// create environments table if not exists
 	query := `
 	CREATE TABLE IF NOT EXISTS environments(
-		name TEXT NOT NULL PRIMARY KEY
+		name TEXT NOT NULL PRIMARY KEY,
+		parent TEXT NOT NULL DEFAULT '',
+		client TEXT NOT NULL DEFAULT '',
+		certificate TEXT NOT NULL DEFAULT '',
+		auth TEXT NOT NULL DEFAULT ''
 	);
 	`
 
@@ -83,6 +91,10 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
+	addColumn("environments", "parent", "TEXT NOT NULL DEFAULT ''")
+	addColumn("environments", "client", "TEXT NOT NULL DEFAULT ''")
+	addColumn("environments", "certificate", "TEXT NOT NULL DEFAULT ''")
+	addColumn("environments", "auth", "TEXT NOT NULL DEFAULT ''")
 
 	globalDB.Exec(`INSERT INTO environments (name) VALUES ('global')`)
 }
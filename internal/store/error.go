@@ -11,5 +11,9 @@ var (
 	ErrorRequestExists       = errors.New("request already exists")
 	ErrorVariableNotFound    = errors.New("variable not found")
 	ErrorVariableExists      = errors.New("variable already exists")
+	ErrorSuiteNotFound       = errors.New("suite not found")
+	ErrorSuiteExists         = errors.New("suite already exists")
+	ErrorResponseNotFound    = errors.New("response not found")
+	ErrorOpenDatabase        = errors.New("could not open database")
 	ErrorUnknown             = errors.New("an unknown exception has occurred")
 )
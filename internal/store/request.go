@@ -11,6 +11,9 @@ type Request struct {
 	Environment string
 	Body        []byte
 	Headers     string // newline separated values
+	Assertions  string // newline separated values
+	Client      string // JSON encoded client profile
+	Auth        string // JSON encoded auth
 }
 
 func (r *Request) Save() error {
@@ -34,8 +37,8 @@ func StoreRequests(requests []Request) error {
 	for _, request := range requests {
 		if _, err := tx.NamedExec(
 			`INSERT OR REPLACE INTO requests
-			(name, method, url, environment, body, headers)
-			VALUES (:name, :method, :url, :environment, :body, :headers)`,
+			(name, method, url, environment, body, headers, assertions, client, auth)
+			VALUES (:name, :method, :url, :environment, :body, :headers, :assertions, :client, :auth)`,
 			&request); err != nil {
 
 			if sqliteErr, ok := err.(sqlite3.Error); ok {
@@ -95,10 +98,7 @@ func GetRequestsByEnvironmentAndMethod(environment, method string) []Request {
 	return requests
 }
 
-func init() {
-	if globalDB == nil {
-		initDB()
-	}
+func createRequestsTable() {
 	// create requests table if not exists
 	query := `
 	CREATE TABLE IF NOT EXISTS requests(
@@ -108,6 +108,9 @@ func init() {
 		environment TEXT,
 		body BLOB,
 		headers TEXT,
+		assertions TEXT,
+		client TEXT DEFAULT '',
+		auth TEXT DEFAULT '',
 		FOREIGN KEY(environment) REFERENCES environments(name)
 	);
 	`
@@ -116,4 +119,7 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
+	addColumn("requests", "assertions", "TEXT DEFAULT ''")
+	addColumn("requests", "client", "TEXT DEFAULT ''")
+	addColumn("requests", "auth", "TEXT DEFAULT ''")
 }
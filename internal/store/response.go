@@ -0,0 +1,167 @@
+package store
+
+import (
+	"time"
+)
+
+type Response struct {
+	ID              int64
+	Request         string
+	Environment     string
+	Method          string
+	URL             string
+	RequestHeaders  string `db:"request_headers"` // newline separated values
+	RequestBody     []byte `db:"request_body"`
+	Proto           string
+	Status          string
+	StatusCode      int    `db:"status_code"`
+	ResponseHeaders string `db:"response_headers"` // newline separated values
+	ResponseBody    []byte `db:"response_body"`
+	Elapsed         int64  // nanoseconds
+	Time            time.Time
+	Baseline        bool
+}
+
+func (r *Response) Save() error {
+	_, err := globalDB.NamedExec(
+		`INSERT INTO responses
+		(request, environment, method, url, request_headers, request_body, proto,
+		status, status_code, response_headers, response_body, elapsed, time, baseline)
+		VALUES (:request, :environment, :method, :url, :request_headers, :request_body, :proto,
+		:status, :status_code, :response_headers, :response_body, :elapsed, :time, :baseline)`,
+		r)
+	if err != nil {
+		log.Errorf("%+v\n", err)
+		return ErrorUnknown
+	}
+	return nil
+}
+func (r *Response) Delete() error {
+	_, err := globalDB.Exec("DELETE FROM responses WHERE id=$1", r.ID)
+	if err != nil {
+		log.Errorf("%+v\n", err)
+		return ErrorResponseNotFound
+	}
+	return nil
+}
+
+// MarkBaseline makes the response the baseline of its request and
+// environment, replacing the previous one.
+func (r *Response) MarkBaseline() error {
+	tx, err := globalDB.Begin()
+	if err != nil {
+		log.Errorf("%+v\n", err)
+		return ErrorUnknown
+	}
+	_, err = tx.Exec("UPDATE responses SET baseline = 0 WHERE request=$1 AND environment=$2",
+		r.Request, r.Environment)
+	if err == nil {
+		_, err = tx.Exec("UPDATE responses SET baseline = 1 WHERE id=$1", r.ID)
+	}
+	if err != nil {
+		tx.Rollback()
+		log.Errorf("%+v\n", err)
+		return ErrorUnknown
+	}
+	if err := tx.Commit(); err != nil {
+		log.Errorf("%+v\n", err)
+		return ErrorUnknown
+	}
+	r.Baseline = true
+	return nil
+}
+
+// DeleteResponsesBefore deletes all responses received before t, except
+// baselines
+func DeleteResponsesBefore(t time.Time) error {
+	_, err := globalDB.Exec("DELETE FROM responses WHERE time < $1 AND baseline = 0", t)
+	if err != nil {
+		log.Errorf("%+v\n", err)
+		return ErrorUnknown
+	}
+	return nil
+}
+
+// DeleteResponsesExceptLatest keeps only the latest n responses of each
+// request, and its baselines.
+func DeleteResponsesExceptLatest(n int) error {
+	_, err := globalDB.Exec(`DELETE FROM responses WHERE id IN (
+		SELECT id FROM (
+			SELECT id, ROW_NUMBER() OVER (PARTITION BY request ORDER BY id DESC) AS age
+			FROM responses WHERE baseline = 0
+		) WHERE age > $1
+	)`, n)
+	if err != nil {
+		log.Errorf("%+v\n", err)
+		return ErrorUnknown
+	}
+	return nil
+}
+
+func GetAllResponses() []Response {
+	responses := []Response{}
+	if err := globalDB.Select(&responses, "SELECT * FROM responses ORDER BY id DESC"); err != nil {
+		log.Errorf("%+v\n", err)
+	}
+	return responses
+}
+func GetResponseByID(id int64) (Response, error) {
+	response := Response{}
+	if err := globalDB.Get(&response, "SELECT * FROM responses WHERE id=$1", id); err != nil {
+		log.Errorf("%+v\n", err)
+		return Response{}, ErrorResponseNotFound
+	}
+	return response, nil
+}
+func GetBaselineByRequestAndEnvironment(request, environment string) (Response, error) {
+	response := Response{}
+	if err := globalDB.Get(&response,
+		"SELECT * FROM responses WHERE request=$1 AND environment=$2 AND baseline = 1",
+		request, environment); err != nil {
+		// Not every request has a baseline
+		return Response{}, ErrorResponseNotFound
+	}
+	return response, nil
+}
+func GetResponsesByRequest(request string) []Response {
+	responses := []Response{}
+	if err := globalDB.Select(&responses,
+		"SELECT * FROM responses WHERE request=$1 ORDER BY id DESC", request); err != nil {
+		log.Errorf("%+v\n", err)
+	}
+	return responses
+}
+
+func createResponsesTable() {
+	// create responses table if not exists
+	query := `
+	CREATE TABLE IF NOT EXISTS responses(
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		request TEXT NOT NULL,
+		environment TEXT NOT NULL,
+		method TEXT,
+		url TEXT,
+		request_headers TEXT,
+		request_body BLOB,
+		proto TEXT,
+		status TEXT,
+		status_code INT,
+		response_headers TEXT,
+		response_body BLOB,
+		elapsed INT,
+		time DATETIME,
+		baseline BOOLEAN NOT NULL DEFAULT 0
+	);
+	`
+
+	_, err := globalDB.Exec(query)
+	if err != nil {
+		panic(err)
+	}
+	addColumn("responses", "baseline", "BOOLEAN NOT NULL DEFAULT 0")
+	// Pruning and the history of a request look responses up by request
+	_, err = globalDB.Exec("CREATE INDEX IF NOT EXISTS responses_request_id ON responses(request, id)")
+	if err != nil {
+		panic(err)
+	}
+}
@@ -1,19 +1,41 @@
 package store
 
 import (
+	"strings"
+
 	"github.com/jmoiron/sqlx"
 	_ "github.com/mattn/go-sqlite3"
 )
 
 var globalDB *sqlx.DB
 
-func initDB() {
-	db, err := sqlx.Open("sqlite3", "test.db?_foreign_keys=on")
-	if err != nil {
-		panic(err)
+// Open opens the sqlite database at path, creating it and its tables if they
+// do not exist. It must be called before any other function in this package.
+func Open(path string) error {
+	db, err := sqlx.Open("sqlite3", path+"?_foreign_keys=on")
+	if err == nil {
+		err = db.Ping()
 	}
-	if db == nil {
-		panic("db nil")
+	if err != nil {
+		log.Errorf("%+v\n", err)
+		return ErrorOpenDatabase
 	}
 	globalDB = db
+
+	createEnvironmentsTable()
+	createRequestsTable()
+	createResponsesTable()
+	createSuitesTable()
+	createVariablesTable()
+	createCookiesTable()
+	return nil
+}
+
+// addColumn adds a column to a table created by an older version of poster.
+// It does nothing if the column already exists.
+func addColumn(table, column, definition string) {
+	_, err := globalDB.Exec("ALTER TABLE " + table + " ADD COLUMN " + column + " " + definition)
+	if err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+		panic(err)
+	}
 }
@@ -0,0 +1,94 @@
+package store
+
+import (
+	"github.com/mattn/go-sqlite3"
+)
+
+type Suite struct {
+	Name        string
+	Environment string
+	Requests    string // newline separated values
+}
+
+func (s *Suite) Save() error {
+	return StoreSuites([]Suite{*s})
+}
+func (s *Suite) Delete() error {
+	_, err := globalDB.Exec("DELETE FROM suites WHERE name=$1", s.Name)
+	if err != nil {
+		log.Errorf("%+v\n", err)
+		return ErrorSuiteNotFound
+	}
+	return nil
+}
+
+func StoreSuites(suites []Suite) error {
+	if len(suites) == 0 {
+		return nil
+	}
+	tx := globalDB.MustBegin()
+
+	for _, suite := range suites {
+		if _, err := tx.NamedExec(
+			`INSERT OR REPLACE INTO suites
+			(name, environment, requests)
+			VALUES (:name, :environment, :requests)`,
+			&suite); err != nil {
+
+			if sqliteErr, ok := err.(sqlite3.Error); ok {
+				if sqliteErr.Code == sqlite3.ErrConstraint {
+					if sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey {
+						return ErrorSuiteExists
+					}
+					return ErrorEnvironmentNotFound
+				}
+				return ErrorUnknown
+			}
+			// Should not reach
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func GetAllSuites() []Suite {
+	suites := []Suite{}
+	if err := globalDB.Select(&suites, "SELECT * FROM suites"); err != nil {
+		log.Errorf("%+v\n", err)
+	}
+	return suites
+}
+func GetSuiteByName(name string) (Suite, error) {
+	suite := Suite{}
+	if err := globalDB.Get(&suite, "SELECT * FROM suites WHERE name=$1", name); err != nil {
+		log.Errorf("%+v\n", err)
+		return Suite{}, ErrorSuiteNotFound
+	}
+	return suite, nil
+}
+func GetSuitesByEnvironment(environment string) []Suite {
+	suites := []Suite{}
+	if err := globalDB.Select(&suites,
+		"SELECT * FROM suites WHERE environment=$1", environment); err != nil {
+		log.Errorf("%+v\n", err)
+	}
+	return suites
+}
+
+func createSuitesTable() {
+	// create suites table if not exists
+	query := `
+	CREATE TABLE IF NOT EXISTS suites(
+		name TEXT NOT NULL PRIMARY KEY,
+		environment TEXT,
+		requests TEXT,
+		FOREIGN KEY(environment) REFERENCES environments(name)
+	);
+	`
+
+	_, err := globalDB.Exec(query)
+	if err != nil {
+		panic(err)
+	}
+}
@@ -14,6 +14,7 @@ type Variable struct {
 	Generator   string
 	Timeout     int64
 	Last        time.Time
+	Secret      bool
 }
 
 func (v *Variable) Save() error {
@@ -38,8 +39,8 @@ func StoreVariables(variables []Variable) error {
 	for _, variable := range variables {
 		if _, err := tx.NamedExec(
 			`INSERT OR REPLACE INTO variables
-			(name, value, environment, type, generator, timeout, last) VALUES
-			(:name, :value, :environment, :type, :generator, :timeout, :last)`,
+			(name, value, environment, type, generator, timeout, last, secret) VALUES
+			(:name, :value, :environment, :type, :generator, :timeout, :last, :secret)`,
 			&variable); err != nil {
 
 			if sqliteErr, ok := err.(sqlite3.Error); ok {
@@ -117,10 +118,7 @@ func GetVariablesByNameAndType(name, typ string) []Variable {
 	return variables
 }
 
-func init() {
-	if globalDB == nil {
-		initDB()
-	}
+func createVariablesTable() {
 	// create requests table if not exists
 	query := `
 	CREATE TABLE IF NOT EXISTS variables(
@@ -131,6 +129,7 @@ func init() {
 		generator TEXT,
 		timeout INT,
 		last DATETIME,
+		secret BOOLEAN NOT NULL DEFAULT 0,
 		PRIMARY KEY (name, environment),
 		FOREIGN KEY(environment) REFERENCES environments(name)
 	);
@@ -140,4 +139,5 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
+	addColumn("variables", "secret", "BOOLEAN NOT NULL DEFAULT 0")
 }